@@ -38,6 +38,7 @@
 	"tailscale.com/envknob"
 	"tailscale.com/feature"
 	"tailscale.com/feature/buildfeatures"
+	"tailscale.com/health"
 	"tailscale.com/ipn"
 	"tailscale.com/ipn/ipnstate"
 	"tailscale.com/net/netutil"
@@ -51,6 +52,7 @@
 	"tailscale.com/types/key"
 	"tailscale.com/util/clientmetric"
 	"tailscale.com/util/eventbus"
+	"tailscale.com/version"
 )
 
 // defaultClient is the default Client when using the legacy
@@ -492,6 +494,20 @@ func (lc *Client) TailDaemonLogs(ctx context.Context) (io.Reader, error) {
 	return res.Body, nil
 }
 
+// RecentDaemonLogs returns the Tailscale daemon's logs written after since,
+// as kept in memory when tailscaled runs with TS_LOG_RING_SIZE set, one
+// JSON object per line. A zero since means all of them.
+//
+// API maturity: this method is not considered a stable API and is
+// subject to change between releases.
+func (lc *Client) RecentDaemonLogs(ctx context.Context, since time.Time) ([]byte, error) {
+	path := "/localapi/v0/logs"
+	if !since.IsZero() {
+		path += "?since=" + url.QueryEscape(since.Format(time.RFC3339))
+	}
+	return lc.get200(ctx, path)
+}
+
 // EventBusGraph returns a graph of active publishers and subscribers in the eventbus
 // as a [eventbus.DebugTopics].
 //
@@ -780,6 +796,55 @@ func (lc *Client) StatusWithoutPeers(ctx context.Context) (*ipnstate.Status, err
 	return lc.status(ctx, "?peers=false")
 }
 
+// DaemonVersion returns the short version (as in [version.Short], such as
+// "1.80.2") of the tailscaled that lc talks to.
+func (lc *Client) DaemonVersion(ctx context.Context) (string, error) {
+	st, err := lc.StatusWithoutPeers(ctx)
+	if err != nil {
+		return "", err
+	}
+	return shortVersion(st.Version), nil
+}
+
+// VersionMeta returns the version metadata of the tailscaled that lc talks
+// to, including how it was installed and whether "tailscale update" can
+// update it.
+func (lc *Client) VersionMeta(ctx context.Context) (version.Meta, error) {
+	body, err := lc.get200(ctx, "/localapi/v0/version-meta")
+	if err != nil {
+		return version.Meta{}, err
+	}
+	return decodeJSON[version.Meta](body)
+}
+
+// ClientDaemonMatch reports whether the current binary and the tailscaled
+// that lc talks to are the same version. If not, msg describes the mismatch,
+// suitable for warning users who upgraded the package but didn't restart
+// tailscaled.
+//
+// The versions match only if their short versions ([version.Short] and
+// [Client.DaemonVersion]) are identical, including any -dev suffix; it
+// doesn't matter which one is newer. Unlike the check that calls the
+// handler set with [SetVersionMismatchHandler], differing commits of the
+// same version match.
+func (lc *Client) ClientDaemonMatch(ctx context.Context) (ok bool, msg string, err error) {
+	daemon, err := lc.DaemonVersion(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	if client := version.Short(); client != daemon {
+		return false, fmt.Sprintf("client version %s != tailscaled version %s; restart tailscaled if it was just upgraded", client, daemon), nil
+	}
+	return true, "", nil
+}
+
+// shortVersion returns the short form of the long version string long, as
+// in [ipnstate.Status.Version]: the part before the "-t" commit suffix.
+func shortVersion(long string) string {
+	short, _, _ := strings.Cut(long, "-t")
+	return short
+}
+
 func (lc *Client) status(ctx context.Context, queryString string) (*ipnstate.Status, error) {
 	body, err := lc.get200(ctx, "/localapi/v0/status"+queryString)
 	if err != nil {
@@ -903,6 +968,19 @@ func (lc *Client) CheckIPForwarding(ctx context.Context) error {
 	return nil
 }
 
+// HealthState returns the local Tailscale daemon's current health state:
+// the unhealthy Warnables, keyed by their stable codes.
+//
+// API maturity: this method is not considered a stable API and is
+// subject to change between releases.
+func (lc *Client) HealthState(ctx context.Context) (*health.State, error) {
+	body, err := lc.get200(ctx, "/localapi/v0/health")
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON[*health.State](body)
+}
+
 // CheckUDPGROForwarding asks the local Tailscale daemon whether it looks like
 // the machine is optimally configured to forward UDP packets as a subnet router
 // or exit node.
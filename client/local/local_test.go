@@ -112,6 +112,22 @@ func TestUserDialSelf(t *testing.T) {
 	}
 }
 
+func TestShortVersion(t *testing.T) {
+	tests := []struct {
+		long, want string
+	}{
+		{"1.80.2-t1234abcde-g5678fedcb", "1.80.2"},
+		{"1.81.0-dev20250101-t1234abcde", "1.81.0-dev20250101"},
+		{"1.80.2", "1.80.2"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := shortVersion(tt.long); got != tt.want {
+			t.Errorf("shortVersion(%q) = %q; want %q", tt.long, got, tt.want)
+		}
+	}
+}
+
 func TestDeps(t *testing.T) {
 	deptest.DepChecker{
 		BadDeps: map[string]string{
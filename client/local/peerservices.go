@@ -0,0 +1,52 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !ts_omit_peerservices
+
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/netip"
+	"net/url"
+
+	"tailscale.com/client/tailscale/apitype"
+)
+
+// PeerServices returns the services advertised over the PeerAPI by the
+// peer with Tailscale IP peer or, if peer is the zero value, those
+// registered on this node. Listing a peer's services requires being one of
+// its owner's untagged devices or having been granted
+// [tailcfg.PeerCapabilityPeerServices] to it.
+func (lc *Client) PeerServices(ctx context.Context, peer netip.Addr) ([]apitype.PeerService, error) {
+	path := "/localapi/v0/peer-services"
+	if peer.IsValid() {
+		path += "?peer=" + url.QueryEscape(peer.String())
+	}
+	body, err := lc.get200(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON[[]apitype.PeerService](body)
+}
+
+// AdvertisePeerService registers svc on this node, for its peers to
+// discover over the PeerAPI. It replaces any service with the same name.
+func (lc *Client) AdvertisePeerService(ctx context.Context, svc apitype.PeerService) error {
+	j, err := json.Marshal(svc)
+	if err != nil {
+		return err
+	}
+	_, err = lc.send(ctx, "POST", "/localapi/v0/peer-services", http.StatusOK, bytes.NewReader(j))
+	return err
+}
+
+// RemovePeerService unregisters the service with the given name from this
+// node.
+func (lc *Client) RemovePeerService(ctx context.Context, name string) error {
+	_, err := lc.send(ctx, "DELETE", "/localapi/v0/peer-services?name="+url.QueryEscape(name), http.StatusOK, nil)
+	return err
+}
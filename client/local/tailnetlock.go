@@ -128,6 +128,26 @@ type signRequest struct {
 	return nil
 }
 
+// TailnetLockSubmitSignature submits a node-key signature made elsewhere,
+// such as with a hardware key and [tka.SignNodeKey], to the control plane.
+// The signature must authorize nodeKey under the current key authority.
+func (lc *Client) TailnetLockSubmitSignature(ctx context.Context, nodeKey key.NodePublic, sig tkatype.MarshaledSignature) error {
+	var b bytes.Buffer
+	type submitRequest struct {
+		NodeKey   key.NodePublic
+		Signature tkatype.MarshaledSignature
+	}
+
+	if err := json.NewEncoder(&b).Encode(submitRequest{NodeKey: nodeKey, Signature: sig}); err != nil {
+		return err
+	}
+
+	if _, err := lc.send(ctx, "POST", "/localapi/v0/tka/submit-signature", 200, &b); err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+	return nil
+}
+
 // Deprecated: use [Client.TailnetLockSign] instead.
 func (lc *Client) NetworkLockSign(ctx context.Context, nodeKey key.NodePublic, rotationPublic []byte) error {
 	return lc.TailnetLockSign(ctx, nodeKey, rotationPublic)
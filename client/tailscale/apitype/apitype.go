@@ -104,3 +104,22 @@ type OptionalFeatures struct {
 	// are not guaranteed to be present.)
 	Features map[string]bool
 }
+
+// PeerService is a named service that a node advertises to its peers over
+// the PeerAPI, so that tailnet apps can find each other without hard-coding
+// hostnames and ports.
+type PeerService struct {
+	// Name is the service's name, such as "grafana". It's unique on the
+	// node advertising it.
+	Name string
+
+	// Port is the port the service listens on, at the node's Tailscale IPs.
+	Port uint16
+
+	// Proto is the service's transport protocol, "tcp" or "udp".
+	Proto string
+
+	// Metadata is optional information about the service for the apps
+	// that discover it, such as a URL path or version.
+	Metadata map[string]string `json:",omitempty"`
+}
@@ -22,6 +22,7 @@
 	"golang.org/x/sys/windows"
 	"tailscale.com/util/winutil"
 	"tailscale.com/util/winutil/authenticode"
+	"tailscale.com/version"
 )
 
 const (
@@ -185,7 +186,7 @@ func (up *Updater) updateWindows() error {
 	up.Logf("running tailscale.exe copy for final install...")
 
 	cmd := exec.Command(cmdTailscaleCopy, "update")
-	cmd.Env = append(os.Environ(), winMSIEnv+"="+msiTarget, winVersionEnv+"="+ver)
+	cmd.Env = append(os.Environ(), winMSIEnv+"="+msiTarget, winVersionEnv+"="+ver, version.UpdaterHelperEnv+"=1")
 	cmd.Stdout = up.Stderr
 	cmd.Stderr = up.Stderr
 	cmd.Stdin = os.Stdin
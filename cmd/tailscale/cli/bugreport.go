@@ -11,6 +11,8 @@
 
 	"github.com/peterbourgon/ff/v3/ffcli"
 	"tailscale.com/client/local"
+	"tailscale.com/feature"
+	"tailscale.com/feature/buildfeatures"
 )
 
 var bugReportCmd = &ffcli.Command{
@@ -22,6 +24,9 @@
 		fs := newFlagSet("bugreport")
 		fs.BoolVar(&bugReportArgs.diagnose, "diagnose", false, "run additional in-depth checks")
 		fs.BoolVar(&bugReportArgs.record, "record", false, "if true, pause and then write another bugreport")
+		if buildfeatures.HasBugReportBundle {
+			fs.StringVar(&bugReportArgs.bundle, "bundle", "", "if non-empty, also write a .tar.gz of local diagnostics (status, prefs without keys, DERP map, metrics, goroutines, recent logs) to this file, to attach to a support ticket")
+		}
 		return fs
 	})(),
 }
@@ -29,6 +34,7 @@
 var bugReportArgs struct {
 	diagnose bool
 	record   bool
+	bundle   string
 }
 
 func runBugReport(ctx context.Context, args []string) error {
@@ -51,7 +57,7 @@ func runBugReport(ctx context.Context, args []string) error {
 			return err
 		}
 		outln(logMarker)
-		return nil
+		return maybeWriteBugReportBundle(ctx, logMarker)
 	}
 
 	// Recording; run the request in the background
@@ -79,5 +85,22 @@ type bugReportResp struct {
 
 	outln(res.marker)
 	outln("Please provide both bugreport markers above to the support team or GitHub issue.")
-	return nil
+	return maybeWriteBugReportBundle(ctx, res.marker)
+}
+
+// hookWriteBugReportBundle writes the bugreport --bundle file at path for
+// the bugreport with the given log marker.
+var hookWriteBugReportBundle feature.Hook[func(ctx context.Context, path, marker string) error] // for bugreportbundle
+
+// maybeWriteBugReportBundle writes the --bundle file, if requested, for the
+// bugreport with the given log marker.
+func maybeWriteBugReportBundle(ctx context.Context, marker string) error {
+	if bugReportArgs.bundle == "" {
+		return nil
+	}
+	f, ok := hookWriteBugReportBundle.GetOk()
+	if !ok {
+		return feature.ErrUnavailable
+	}
+	return f(ctx, bugReportArgs.bundle, marker)
 }
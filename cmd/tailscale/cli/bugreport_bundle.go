@@ -0,0 +1,106 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !ts_omit_bugreportbundle
+
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() {
+	hookWriteBugReportBundle.Set(writeBugReportBundle)
+}
+
+// bundlePart is a file in a bugreport --bundle archive, and how to get its
+// contents.
+type bundlePart struct {
+	name string
+	get  func() ([]byte, error)
+}
+
+// writeBugReportBundle writes the bugreport --bundle file at path for the
+// bugreport with the given log marker.
+func writeBugReportBundle(ctx context.Context, path, marker string) error {
+	asJSON := func(v any, err error) ([]byte, error) {
+		if err != nil {
+			return nil, err
+		}
+		return json.MarshalIndent(v, "", "\t")
+	}
+	parts := []bundlePart{
+		{"bugreport.txt", func() ([]byte, error) { return []byte(marker + "\n"), nil }},
+		{"status.json", func() ([]byte, error) { return asJSON(localClient.Status(ctx)) }},
+		{"prefs.json", func() ([]byte, error) { return asJSON(localClient.GetPrefs(ctx)) }},
+		{"derpmap.json", func() ([]byte, error) { return asJSON(localClient.CurrentDERPMap(ctx)) }},
+		{"metrics.txt", func() ([]byte, error) { return localClient.DaemonMetrics(ctx) }},
+		{"goroutines.txt", func() ([]byte, error) { return localClient.Goroutines(ctx) }},
+		{"logs.jsonl", func() ([]byte, error) { return localClient.RecentDaemonLogs(ctx, time.Time{}) }},
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := writeBundle(f, parts, time.Now()); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	outln("Wrote diagnostics bundle to " + path)
+	return nil
+}
+
+// writeBundle writes parts to w as a .tar.gz archive whose files have
+// modification time now.
+//
+// Each part is gathered separately; the ones that fail to be gathered (such
+// as the logs, if tailscaled doesn't keep them in memory) are listed with
+// their errors in errors.txt instead.
+func writeBundle(w io.Writer, parts []bundlePart, now time.Time) error {
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	add := func(name string, b []byte) error {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(b)),
+			ModTime: now,
+		}); err != nil {
+			return err
+		}
+		_, err := tw.Write(b)
+		return err
+	}
+	var errs strings.Builder
+	for _, p := range parts {
+		b, err := p.get()
+		if err != nil {
+			fmt.Fprintf(&errs, "%s: %v\n", p.name, err)
+			continue
+		}
+		if err := add(p.name, b); err != nil {
+			return err
+		}
+	}
+	if errs.Len() > 0 {
+		if err := add("errors.txt", []byte(errs.String())); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,63 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !ts_omit_bugreportbundle
+
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteBundle(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	parts := []bundlePart{
+		{"bugreport.txt", func() ([]byte, error) { return []byte("BUG-1\n"), nil }},
+		{"logs.jsonl", func() ([]byte, error) { return nil, errors.New("no log ring") }},
+		{"status.json", func() ([]byte, error) { return []byte(`{"BackendState":"Running"}`), nil }},
+	}
+	var buf bytes.Buffer
+	if err := writeBundle(&buf, parts, now); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(zr)
+	got := map[string]string{}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !h.ModTime.Equal(now) {
+			t.Errorf("%s: ModTime = %v; want %v", h.Name, h.ModTime, now)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[h.Name] = string(b)
+	}
+	want := map[string]string{
+		"bugreport.txt": "BUG-1\n",
+		"status.json":   `{"BackendState":"Running"}`,
+		"errors.txt":    "logs.jsonl: no log ring\n",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("bundle contents mismatch (-want +got):\n%s", diff)
+	}
+}
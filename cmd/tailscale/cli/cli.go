@@ -240,6 +240,7 @@ type restore struct {
 	fileCmd,
 	sysPolicyCmd,
 	maybeRoutecheckCmd,
+	maybePeerServicesCmd,
 	maybeWebCmd,
 	maybeDriveCmd,
 	maybeTailnetLockCmd,
@@ -283,6 +284,7 @@ func newRootCmd(tb ...testenv.TB) *ffcli.Command {
 			nilOrCall(sysPolicyCmd),
 			netcheckCmd,
 			nilOrCall(maybeRoutecheckCmd),
+			nilOrCall(maybePeerServicesCmd),
 			ipCmd,
 			dnsCmd,
 			statusCmd,
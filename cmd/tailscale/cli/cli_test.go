@@ -861,6 +861,13 @@ func TestPrefsFromUpArgs(t *testing.T) {
 			},
 			wantErr: `invalid value --netfilter-mode="bogus"`,
 		},
+		{
+			name: "error_linux_netfilter_kind",
+			args: upArgsT{
+				netfilterMode: "nftables",
+			},
+			wantErr: `invalid value --netfilter-mode="nftables" (want on, nodivert or off); it sets how much of netfilter tailscaled manages, and the nftables backend is chosen automatically`,
+		},
 		{
 			name: "error_exit_node_ip_is_self_ip",
 			args: upArgsT{
@@ -1862,3 +1869,77 @@ func TestSanitizeWriter(t *testing.T) {
 		t.Errorf("unexpected sanitized content\ngot: %q\nwant: %q", got, want)
 	}
 }
+
+func TestStatusWatcherChange(t *testing.T) {
+	prefsWithExitNode := func(id tailcfg.StableNodeID) *ipn.PrefsView {
+		pv := (&ipn.Prefs{ExitNodeID: id}).View()
+		return &pv
+	}
+	running := ipn.Running
+	exit := tailcfg.StableNodeID("nExit")
+	none := tailcfg.StableNodeID("")
+
+	sw := newStatusWatcher(true)
+	steps := []struct {
+		name   string
+		n      ipn.Notify
+		want   statusChange
+		wantOK bool
+	}{
+		{
+			name: "initial",
+			n:    ipn.Notify{InitialStatus: &ipnstate.Status{}, Prefs: prefsWithExitNode("")},
+		},
+		{
+			name:   "state",
+			n:      ipn.Notify{State: &running},
+			want:   statusChange{BackendState: "Running"},
+			wantOK: true,
+		},
+		{
+			name:   "exit-node-on",
+			n:      ipn.Notify{Prefs: prefsWithExitNode(exit)},
+			want:   statusChange{ExitNodeID: &exit},
+			wantOK: true,
+		},
+		{
+			name: "prefs-unchanged-exit-node",
+			n:    ipn.Notify{Prefs: prefsWithExitNode(exit)},
+		},
+		{
+			name:   "exit-node-off",
+			n:      ipn.Notify{Prefs: prefsWithExitNode("")},
+			want:   statusChange{ExitNodeID: &none},
+			wantOK: true,
+		},
+		{
+			name: "peers",
+			n: ipn.Notify{
+				PeersChanged:     []*tailcfg.Node{{ID: 1}},
+				PeersRemoved:     []tailcfg.NodeID{2},
+				PeerChangedPatch: []*tailcfg.PeerChange{{NodeID: 3}},
+			},
+			want: statusChange{
+				PeersChanged: []*tailcfg.Node{{ID: 1}},
+				PeersRemoved: []tailcfg.NodeID{2},
+				PeerChanges:  []*tailcfg.PeerChange{{NodeID: 3}},
+			},
+			wantOK: true,
+		},
+		{
+			name: "nothing",
+			n:    ipn.Notify{},
+		},
+	}
+	for _, st := range steps {
+		got, ok := sw.change(st.n)
+		if ok != st.wantOK || !reflect.DeepEqual(got, st.want) {
+			t.Errorf("%s: got (%+v, %v); want (%+v, %v)", st.name, got, ok, st.want, st.wantOK)
+		}
+	}
+
+	noPeers := newStatusWatcher(false)
+	if c, ok := noPeers.change(ipn.Notify{PeersRemoved: []tailcfg.NodeID{2}}); ok {
+		t.Errorf("with --peers=false: got change %+v", c)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !ts_omit_ssh
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+	"tailscale.com/sessionrecording"
+)
+
+func init() {
+	debugSSHReplayCmd = mkDebugSSHReplayCmd
+}
+
+func mkDebugSSHReplayCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "ssh-replay",
+		ShortUsage: "tailscale debug ssh-replay [--max-idle=<duration>] <file.cast>",
+		ShortHelp:  "Play back a locally recorded SSH session",
+		LongHelp: strings.TrimSpace(`
+Plays back an SSH session recording made by tailscaled with
+TS_SSH_RECORDING_DIR or TS_DEBUG_LOG_SSH set, with its original timing.
+The files are in asciinema v2 format, so other asciinema tools work too.
+`),
+		Exec: runSSHReplay,
+		FlagSet: (func() *flag.FlagSet {
+			fs := newFlagSet("ssh-replay")
+			fs.DurationVar(&sshReplayArgs.maxIdle, "max-idle", 2*time.Second, "longest pause to keep in playback; 0 keeps all pauses")
+			return fs
+		})(),
+	}
+}
+
+var sshReplayArgs struct {
+	maxIdle time.Duration
+}
+
+func runSSHReplay(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tailscale debug ssh-replay <file.cast>")
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sessionrecording.Replay(ctx, Stdout, f, sshReplayArgs.maxIdle)
+}
@@ -55,6 +55,7 @@
 	debugCaptureCmd          func() *ffcli.Command // or nil
 	debugPortmapCmd          func() *ffcli.Command // or nil
 	debugPeerRelayCmd        func() *ffcli.Command // or nil
+	debugSSHReplayCmd        func() *ffcli.Command // or nil
 	debugClearNetmapCacheCmd func() *ffcli.Command // or nil
 )
 
@@ -404,6 +405,7 @@ func debugCmd() *ffcli.Command {
 				ShortHelp:  "Print the location of the state directory (if any)",
 				Exec:       runPrintStateDir,
 			},
+			ccall(debugSSHReplayCmd),
 			ccall(debugPeerRelayCmd),
 			ccall(debugClearNetmapCacheCmd),
 		}...),
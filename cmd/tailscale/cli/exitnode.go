@@ -11,12 +11,15 @@
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
 	"text/tabwriter"
+	"time"
 
 	"github.com/kballard/go-shellquote"
 	"github.com/peterbourgon/ff/v3/ffcli"
 	"tailscale.com/envknob"
 	"tailscale.com/feature/buildfeatures"
+	"tailscale.com/ipn"
 	"tailscale.com/ipn/ipnstate"
 	"tailscale.com/tailcfg"
 	"tailscale.com/util/slicesx"
@@ -49,6 +52,8 @@ func exitNodeCmd() *ffcli.Command {
 					if buildfeatures.HasRouteCheck {
 						fs.BoolVar(&exitNodeArgs.probe, "force-probe", false, hidden+"perform a routecheck probe before suggesting")
 					}
+					fs.BoolVar(&exitNodeArgs.measure, "measure", false, "ping each online exit node and rank them by measured latency")
+					fs.BoolVar(&exitNodeArgs.auto, "auto", false, "start using the suggested exit node")
 					return fs
 				})(),
 			}},
@@ -75,8 +80,10 @@ func exitNodeCmd() *ffcli.Command {
 }
 
 var exitNodeArgs struct {
-	filter string
-	probe  bool
+	filter  string
+	probe   bool
+	measure bool
+	auto    bool
 }
 
 func exitNodeSetUse(wantOn bool) func(ctx context.Context, args []string) error {
@@ -157,6 +164,9 @@ func runExitNodeList(ctx context.Context, args []string) error {
 // runExitNodeSuggest returns a suggested exit node ID to connect to and shows the chosen exit node tailcfg.StableNodeID.
 // If there are no derp based exit nodes to choose from or there is a failure in finding a suggestion, the command will return an error indicating so.
 func runExitNodeSuggest(ctx context.Context, args []string) error {
+	if exitNodeArgs.measure {
+		return runExitNodeSuggestMeasured(ctx)
+	}
 	suggestExitNode := localClient.SuggestExitNode
 	if exitNodeArgs.probe {
 		suggestExitNode = localClient.SuggestExitNodeWithProbe
@@ -169,10 +179,128 @@ func runExitNodeSuggest(ctx context.Context, args []string) error {
 		fmt.Println("No exit node suggestion is available.")
 		return nil
 	}
+	if exitNodeArgs.auto {
+		return useSuggestedExitNode(ctx, res.ID, res.Name)
+	}
 	fmt.Printf("Suggested exit node: %v\nTo accept this suggestion, use `tailscale set --exit-node=%v`.\n", res.Name, shellquote.Join(res.Name))
 	return nil
 }
 
+// useSuggestedExitNode starts using the exit node id, named name, for
+// "tailscale exit-node suggest --auto".
+func useSuggestedExitNode(ctx context.Context, id tailcfg.StableNodeID, name string) error {
+	_, err := localClient.EditPrefs(ctx, &ipn.MaskedPrefs{
+		Prefs:         ipn.Prefs{ExitNodeID: id},
+		ExitNodeIDSet: true,
+	})
+	if err != nil {
+		return fmt.Errorf("setting exit node: %w", err)
+	}
+	fmt.Printf("Using exit node: %v\n", name)
+	return nil
+}
+
+// exitNodePings is how many disco pings "tailscale exit-node suggest
+// --measure" sends to each exit node.
+const exitNodePings = 3
+
+// exitNodeLatency is the measured latency to an exit node.
+type exitNodeLatency struct {
+	peer    *ipnstate.PeerStatus
+	latency time.Duration // best of the pings; zero if none was answered
+	lost    int           // pings not answered
+}
+
+// runExitNodeSuggestMeasured implements "tailscale exit-node suggest
+// --measure": it pings each online exit node and prints them ranked by
+// latency.
+func runExitNodeSuggestMeasured(ctx context.Context) error {
+	st, err := localClient.Status(ctx)
+	if err != nil {
+		return err
+	}
+	var peers []*ipnstate.PeerStatus
+	for _, ps := range st.Peer {
+		if ps.ExitNodeOption && ps.Online && len(ps.TailscaleIPs) > 0 {
+			peers = append(peers, ps)
+		}
+	}
+	if len(peers) == 0 {
+		fmt.Println("No online exit nodes found.")
+		return nil
+	}
+
+	results := make([]exitNodeLatency, len(peers))
+	var wg sync.WaitGroup
+	for i, ps := range peers {
+		wg.Go(func() {
+			results[i] = measureExitNode(ctx, ps)
+		})
+	}
+	wg.Wait()
+	rankExitNodeLatencies(results)
+
+	w := tabwriter.NewWriter(Stdout, 10, 5, 5, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", "HOSTNAME", "IP", "LATENCY", "LOSS")
+	for _, r := range results {
+		latency := "-"
+		if r.latency > 0 {
+			latency = r.latency.Round(100 * time.Microsecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\n", strings.Trim(r.peer.DNSName, "."), r.peer.TailscaleIPs[0], latency, r.lost, exitNodePings)
+	}
+	w.Flush()
+
+	best := results[0]
+	if best.latency == 0 {
+		fmt.Println("\nNo exit node answered.")
+		return nil
+	}
+	name := strings.Trim(best.peer.DNSName, ".")
+	if exitNodeArgs.auto {
+		return useSuggestedExitNode(ctx, best.peer.ID, name)
+	}
+	fmt.Printf("\nSuggested exit node: %v\nTo accept this suggestion, use `tailscale set --exit-node=%v`.\n", name, shellquote.Join(name))
+	return nil
+}
+
+// measureExitNode sends exitNodePings disco pings to ps.
+func measureExitNode(ctx context.Context, ps *ipnstate.PeerStatus) exitNodeLatency {
+	r := exitNodeLatency{peer: ps}
+	for range exitNodePings {
+		pctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		pr, err := localClient.Ping(pctx, ps.TailscaleIPs[0], tailcfg.PingDisco)
+		cancel()
+		if err != nil || pr.Err != "" {
+			r.lost++
+			continue
+		}
+		d := time.Duration(pr.LatencySeconds * float64(time.Second))
+		if r.latency == 0 || d < r.latency {
+			r.latency = d
+		}
+	}
+	return r
+}
+
+// rankExitNodeLatencies sorts rs from best to worst: by pings lost, then
+// by latency, then by name. Exit nodes that answered no ping sort last.
+func rankExitNodeLatencies(rs []exitNodeLatency) {
+	slices.SortStableFunc(rs, func(a, b exitNodeLatency) int {
+		if (a.latency == 0) != (b.latency == 0) {
+			if a.latency == 0 {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(
+			cmp.Compare(a.lost, b.lost),
+			cmp.Compare(a.latency, b.latency),
+			cmp.Compare(a.peer.DNSName, b.peer.DNSName),
+		)
+	})
+}
+
 func hasAnyExitNodeSuggestions(peers []*ipnstate.PeerStatus) bool {
 	for _, peer := range peers {
 		if peer.HasCap(tailcfg.NodeAttrSuggestExitNode) {
@@ -4,7 +4,10 @@
 package cli
 
 import (
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -318,3 +321,23 @@ func TestSortByCityName(t *testing.T) {
 		t.Errorf("sortByCityName did not order countries by alphabetical order (-want +got):\n%s", diff)
 	}
 }
+
+func TestRankExitNodeLatencies(t *testing.T) {
+	peer := func(name string) *ipnstate.PeerStatus { return &ipnstate.PeerStatus{DNSName: name + "."} }
+	rs := []exitNodeLatency{
+		{peer: peer("dead"), lost: 3},
+		{peer: peer("lossy"), latency: 5 * time.Millisecond, lost: 1},
+		{peer: peer("slow"), latency: 80 * time.Millisecond},
+		{peer: peer("fast-b"), latency: 10 * time.Millisecond},
+		{peer: peer("fast-a"), latency: 10 * time.Millisecond},
+	}
+	rankExitNodeLatencies(rs)
+	var got []string
+	for _, r := range rs {
+		got = append(got, strings.TrimSuffix(r.peer.DNSName, "."))
+	}
+	want := []string{"fast-a", "fast-b", "slow", "lossy", "dead"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
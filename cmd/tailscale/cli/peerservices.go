@@ -0,0 +1,170 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !ts_omit_peerservices
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+	"tailscale.com/client/tailscale/apitype"
+)
+
+func init() {
+	maybePeerServicesCmd = peerServicesCmd
+}
+
+var peerServicesCmd = func() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "services",
+		ShortUsage: "tailscale services <list|advertise|remove> [arguments]",
+		ShortHelp:  "Advertise and discover services on tailnet nodes",
+		LongHelp: hidden + strings.TrimSpace(`
+The 'tailscale services' command advertises named services on this node to
+its peers, and lists the services that a peer advertises, so that apps on the
+tailnet can find each other without hard-coding hostnames and ports.
+
+Services are advertised directly between nodes, and are forgotten when
+tailscaled restarts. For Tailscale Services, see 'tailscale service'.
+`),
+		UsageFunc: usageFuncNoDefaultValues,
+		Exec:      func(context.Context, []string) error { return flag.ErrHelp },
+		Subcommands: []*ffcli.Command{
+			{
+				Name:       "list",
+				ShortUsage: "tailscale services list [peer]",
+				ShortHelp:  "List the services advertised by this node or a peer",
+				Exec:       runPeerServicesList,
+				FlagSet: func() *flag.FlagSet {
+					fs := newFlagSet("list")
+					fs.BoolVar(&peerServicesArgs.json, "json", false, "output in JSON format")
+					return fs
+				}(),
+			},
+			{
+				Name:       "advertise",
+				ShortUsage: "tailscale services advertise [--proto=tcp|udp] [--meta=key=value,...] <name> <port>",
+				ShortHelp:  "Advertise a service on this node to its peers",
+				Exec:       runPeerServicesAdvertise,
+				FlagSet: func() *flag.FlagSet {
+					fs := newFlagSet("advertise")
+					fs.StringVar(&peerServicesArgs.proto, "proto", "tcp", `the service's protocol, "tcp" or "udp"`)
+					fs.StringVar(&peerServicesArgs.meta, "meta", "", "comma-separated key=value metadata for the service")
+					return fs
+				}(),
+			},
+			{
+				Name:       "remove",
+				ShortUsage: "tailscale services remove <name>",
+				ShortHelp:  "Stop advertising a service on this node",
+				Exec:       runPeerServicesRemove,
+			},
+		},
+	}
+}
+
+var peerServicesArgs struct {
+	json  bool
+	proto string
+	meta  string
+}
+
+func runPeerServicesList(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: tailscale services list [peer]")
+	}
+	var peer netip.Addr
+	if len(args) == 1 {
+		ipStr, self, err := tailscaleIPFromArg(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if !self {
+			if peer, err = netip.ParseAddr(ipStr); err != nil {
+				return err
+			}
+		}
+	}
+	svcs, err := localClient.PeerServices(ctx, peer)
+	if err != nil {
+		return err
+	}
+	if peerServicesArgs.json {
+		j, err := json.MarshalIndent(svcs, "", "  ")
+		if err != nil {
+			return err
+		}
+		outln(string(j))
+		return nil
+	}
+	if len(svcs) == 0 {
+		outln("No services advertised.")
+		return nil
+	}
+	w := tabwriter.NewWriter(Stdout, 10, 5, 5, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", "NAME", "PORT", "PROTO", "METADATA")
+	for _, svc := range svcs {
+		var meta []string
+		for _, k := range slices.Sorted(maps.Keys(svc.Metadata)) {
+			meta = append(meta, k+"="+svc.Metadata[k])
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", svc.Name, svc.Port, svc.Proto, strings.Join(meta, ","))
+	}
+	return nil
+}
+
+func runPeerServicesAdvertise(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: tailscale services advertise [flags] <name> <port>")
+	}
+	port, err := strconv.ParseUint(args[1], 10, 16)
+	if err != nil || port == 0 {
+		return fmt.Errorf("invalid port %q", args[1])
+	}
+	svc := apitype.PeerService{
+		Name:  args[0],
+		Port:  uint16(port),
+		Proto: peerServicesArgs.proto,
+	}
+	if svc.Metadata, err = parsePeerServiceMetadata(peerServicesArgs.meta); err != nil {
+		return err
+	}
+	return localClient.AdvertisePeerService(ctx, svc)
+}
+
+func runPeerServicesRemove(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tailscale services remove <name>")
+	}
+	return localClient.RemovePeerService(ctx, args[0])
+}
+
+// parsePeerServiceMetadata parses the --meta flag of "tailscale services
+// advertise", a comma-separated list of key=value pairs.
+func parsePeerServiceMetadata(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	m := make(map[string]string)
+	for kv := range strings.SplitSeq(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid metadata %q; want key=value", kv)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
@@ -12,6 +12,8 @@
 	"net"
 	"net/netip"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
 	"time"
 
@@ -40,6 +42,10 @@
 By default, 'tailscale ping' stops after 10 pings or once a direct
 (non-DERP) path has been established, whichever comes first.
 
+To troubleshoot a flapping connection, use --stats with -c 0 and
+--until-direct=false to ping until interrupted, and then print a
+summary of the latency percentiles, jitter and path changes.
+
 The provided hostname must resolve to or be a Tailscale IP
 (e.g. 100.x.y.z) or a subnet IP advertised by a Tailscale
 relay node.
@@ -56,6 +62,8 @@
 		fs.IntVar(&pingArgs.num, "c", 10, "max number of pings to send. 0 for infinity.")
 		fs.DurationVar(&pingArgs.timeout, "timeout", 5*time.Second, "timeout before giving up on a ping")
 		fs.IntVar(&pingArgs.size, "size", 0, "size of the ping message (disco pings only). 0 for minimum size.")
+		fs.DurationVar(&pingArgs.interval, "interval", time.Second, "time to wait between pings")
+		fs.BoolVar(&pingArgs.stats, "stats", false, "print a summary of latency, jitter and path changes when done or interrupted")
 		return fs
 	})(),
 }
@@ -77,7 +85,9 @@ func init() {
 	tsmp        bool
 	icmp        bool
 	peerAPI     bool
+	stats       bool
 	timeout     time.Duration
+	interval    time.Duration
 }
 
 func pingType() tailcfg.PingType {
@@ -123,14 +133,27 @@ func runPing(ctx context.Context, args []string) error {
 		log.Printf("lookup %q => %q", hostOrIP, ip)
 	}
 
+	var stats pingStats
+	if pingArgs.stats {
+		var cancel context.CancelFunc
+		ctx, cancel = signal.NotifyContext(ctx, os.Interrupt)
+		defer cancel()
+		defer func() { outln(stats.summary(ip)) }()
+	}
+
 	n := 0
 	anyPong := false
 	for {
 		n++
-		ctx, cancel := context.WithTimeout(ctx, pingArgs.timeout)
-		pr, err := localClient.PingWithOpts(ctx, netip.MustParseAddr(ip), pingType(), local.PingOpts{Size: pingArgs.size})
+		stats.sent++
+		pingCtx, cancel := context.WithTimeout(ctx, pingArgs.timeout)
+		pr, err := localClient.PingWithOpts(pingCtx, netip.MustParseAddr(ip), pingType(), local.PingOpts{Size: pingArgs.size})
 		cancel()
 		if err != nil {
+			if pingArgs.stats && ctx.Err() != nil {
+				stats.sent--
+				return nil
+			}
 			if errors.Is(err, context.DeadlineExceeded) {
 				printf("ping %q timed out\n", ip)
 				if n == pingArgs.num {
@@ -167,6 +190,7 @@ func runPing(ctx context.Context, args []string) error {
 			return nil
 		}
 		anyPong = true
+		stats.addPong(latency, via, pr.Endpoint != "")
 		extra := ""
 		if pr.PeerAPIPort != 0 {
 			extra = fmt.Sprintf(", %d", pr.PeerAPIPort)
@@ -178,7 +202,14 @@ func runPing(ctx context.Context, args []string) error {
 		if pr.Endpoint != "" && pingArgs.untilDirect {
 			return nil
 		}
-		time.Sleep(time.Second)
+		select {
+		case <-time.After(pingArgs.interval):
+		case <-ctx.Done():
+			if pingArgs.stats {
+				return nil
+			}
+			return ctx.Err()
+		}
 
 		if n == pingArgs.num {
 			if !anyPong {
@@ -192,6 +223,69 @@ func runPing(ctx context.Context, args []string) error {
 	}
 }
 
+// pingStats is the summary of a 'tailscale ping' run, for --stats.
+type pingStats struct {
+	sent        int
+	latencies   []time.Duration // of each pong, in order
+	lastVia     string          // path of the latest pong
+	pathChanges int             // times the path switched between pongs
+	direct      int             // pongs over a direct path
+}
+
+func (s *pingStats) addPong(latency time.Duration, via string, direct bool) {
+	if s.lastVia != "" && via != s.lastVia {
+		s.pathChanges++
+	}
+	s.lastVia = via
+	s.latencies = append(s.latencies, latency)
+	if direct {
+		s.direct++
+	}
+}
+
+// jitter returns the mean absolute difference between the latencies of
+// consecutive pongs.
+func (s *pingStats) jitter() time.Duration {
+	if len(s.latencies) < 2 {
+		return 0
+	}
+	var sum time.Duration
+	for i := 1; i < len(s.latencies); i++ {
+		d := s.latencies[i] - s.latencies[i-1]
+		if d < 0 {
+			d = -d
+		}
+		sum += d
+	}
+	return sum / time.Duration(len(s.latencies)-1)
+}
+
+// percentile returns the p'th percentile of sorted, by nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p + 99) / 100
+	return sorted[max(i-1, 0)]
+}
+
+func (s *pingStats) summary(ip string) string {
+	var b strings.Builder
+	recv := len(s.latencies)
+	fmt.Fprintf(&b, "--- %s ping statistics ---\n", ip)
+	loss := 0.0
+	if s.sent > 0 {
+		loss = 100 * float64(s.sent-recv) / float64(s.sent)
+	}
+	fmt.Fprintf(&b, "%d pings sent, %d pongs received, %.1f%% loss\n", s.sent, recv, loss)
+	if recv == 0 {
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+	sorted := slices.Sorted(slices.Values(s.latencies))
+	fmt.Fprintf(&b, "latency min/p50/p95/p99/max = %v/%v/%v/%v/%v, jitter %v\n",
+		sorted[0], percentile(sorted, 50), percentile(sorted, 95), percentile(sorted, 99), sorted[len(sorted)-1],
+		s.jitter().Round(time.Microsecond))
+	fmt.Fprintf(&b, "%d of %d pongs direct, %d path changes", s.direct, recv, s.pathChanges)
+	return b.String()
+}
+
 func tailscaleIPFromArg(ctx context.Context, hostOrIP string) (ip string, self bool, err error) {
 	// If the argument is an IP address, use it directly without any resolution.
 	if net.ParseIP(hostOrIP) != nil {
@@ -0,0 +1,44 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPingStats(t *testing.T) {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+	var s pingStats
+	for _, p := range []struct {
+		latency int
+		via     string
+		direct  bool
+	}{
+		{40, "DERP(nyc)", false},
+		{20, "1.2.3.4:41641", true},
+		{10, "1.2.3.4:41641", true},
+		{30, "DERP(nyc)", false},
+	} {
+		s.sent++
+		s.addPong(ms(p.latency), p.via, p.direct)
+	}
+	s.sent++ // a timeout
+
+	want := `--- 100.64.0.1 ping statistics ---
+5 pings sent, 4 pongs received, 20.0% loss
+latency min/p50/p95/p99/max = 10ms/20ms/40ms/40ms/40ms, jitter 16.667ms
+2 of 4 pongs direct, 2 path changes`
+	if got := s.summary("100.64.0.1"); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	var empty pingStats
+	empty.sent = 3
+	want = `--- 100.64.0.1 ping statistics ---
+3 pings sent, 0 pongs received, 100.0% loss`
+	if got := empty.summary("100.64.0.1"); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -23,12 +23,14 @@
 	"tailscale.com/ipn"
 	"tailscale.com/ipn/ipnstate"
 	"tailscale.com/net/netmon"
+	"tailscale.com/tailcfg"
 	"tailscale.com/util/dnsname"
+	"tailscale.com/util/set"
 )
 
 var statusCmd = &ffcli.Command{
 	Name:       "status",
-	ShortUsage: "tailscale status [--active] [--web] [--json]",
+	ShortUsage: "tailscale status [--active] [--web] [--json [--watch]]",
 	ShortHelp:  "Show state of tailscaled and its connections",
 	LongHelp: strings.TrimSpace(`
 
@@ -44,6 +46,14 @@
 (and be sure to select branch/tag that corresponds to the version
  of Tailscale you're running)
 
+With --watch, the status is printed as a single line of JSON, followed by
+a line for each change to it as tailscaled reports it: peers added
+("PeersChanged", also used when a peer changes in a way that needs its
+whole node to describe), removed ("PeersRemoved") or updated
+("PeerChanges", such as for endpoint or online changes), the backend
+state ("BackendState") and the exit node ("ExitNodeID", empty when it's
+turned off). --active only filters the initial status.
+
 `),
 	Exec: runStatus,
 	FlagSet: (func() *flag.FlagSet {
@@ -56,6 +66,7 @@
 		fs.StringVar(&statusArgs.listen, "listen", "127.0.0.1:8384", "listen address for web mode; use port 0 for automatic")
 		fs.BoolVar(&statusArgs.browser, "browser", true, "open a browser in web mode")
 		fs.BoolVar(&statusArgs.header, "header", false, "show column headers in table format")
+		fs.BoolVar(&statusArgs.watch, "watch", false, "with --json, keep running and print changes to the status as they happen, one JSON object per line")
 		return fs
 	})(),
 }
@@ -69,6 +80,7 @@
 	self    bool   // in CLI mode, show status of local machine
 	peers   bool   // in CLI mode, show status of peer machines
 	header  bool   // in CLI mode, show column headers in table format
+	watch   bool   // in JSON mode, stream changes after the initial status
 }
 
 const mullvadTCD = "mullvad.ts.net."
@@ -77,6 +89,12 @@ func runStatus(ctx context.Context, args []string) error {
 	if len(args) > 0 {
 		return errors.New("unexpected non-flag arguments to 'tailscale status'")
 	}
+	if statusArgs.watch {
+		if !statusArgs.json || statusArgs.web {
+			return errors.New("--watch requires --json and can't be used with --web")
+		}
+		return watchStatus(ctx)
+	}
 	getStatus := localClient.Status
 	if !statusArgs.peers {
 		getStatus = localClient.StatusWithoutPeers
@@ -87,11 +105,7 @@ func runStatus(ctx context.Context, args []string) error {
 	}
 	if statusArgs.json {
 		if statusArgs.active {
-			for peer, ps := range st.Peer {
-				if !ps.Active {
-					delete(st.Peer, peer)
-				}
-			}
+			deleteInactivePeers(st)
 		}
 		j, err := json.MarshalIndent(st, "", "  ")
 		if err != nil {
@@ -319,3 +333,97 @@ func firstIPString(v []netip.Addr) string {
 	}
 	return v[0].String()
 }
+
+// deleteInactivePeers removes the peers without active sessions from st.
+func deleteInactivePeers(st *ipnstate.Status) {
+	for peer, ps := range st.Peer {
+		if !ps.Active {
+			delete(st.Peer, peer)
+		}
+	}
+}
+
+// statusChange is a line of "tailscale status --json --watch" output after
+// the first, which is the full status.
+type statusChange struct {
+	BackendState string                `json:",omitempty"`
+	ExitNodeID   *tailcfg.StableNodeID `json:",omitempty"` // pointer so that turning it off shows as ""
+	PeersChanged []*tailcfg.Node       `json:",omitempty"`
+	PeersRemoved []tailcfg.NodeID      `json:",omitempty"`
+	PeerChanges  []*tailcfg.PeerChange `json:",omitempty"`
+}
+
+// watchStatus implements "tailscale status --json --watch", streaming the
+// status and then changes to it from the IPN bus until ctx is done.
+func watchStatus(ctx context.Context) error {
+	mask := ipn.NotifyInitialStatus | ipn.NotifyInitialPrefs | ipn.NotifyPeerPatches | ipn.NotifyNoNetMap
+	w, err := localClient.WatchIPNBus(ctx, mask)
+	if err != nil {
+		return fixTailscaledConnectError(err)
+	}
+	defer w.Close()
+	enc := json.NewEncoder(Stdout)
+	sw := newStatusWatcher(statusArgs.peers)
+	for {
+		n, err := w.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if st := n.InitialStatus; st != nil {
+			if statusArgs.active {
+				deleteInactivePeers(st)
+			}
+			if !statusArgs.peers {
+				st.Peer = nil
+			}
+			if err := enc.Encode(st); err != nil {
+				return err
+			}
+		}
+		if c, ok := sw.change(n); ok {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// statusWatcher turns IPN bus notifications into statusChanges, with the
+// same [ipn.EventFilter] that the LocalAPI events stream uses.
+type statusWatcher struct {
+	filter *ipn.EventFilter
+}
+
+// newStatusWatcher returns a statusWatcher that reports the backend state
+// and exit node and, if peers, changes to peers.
+func newStatusWatcher(peers bool) *statusWatcher {
+	topics := set.Of(ipn.EventTopicState, ipn.EventTopicExitNode)
+	if peers {
+		topics.Add(ipn.EventTopicPeers)
+	}
+	return &statusWatcher{filter: ipn.NewEventFilter(topics)}
+}
+
+// change returns the change to the status that n carries, if any.
+func (sw *statusWatcher) change(n ipn.Notify) (c statusChange, ok bool) {
+	ev, ok := sw.filter.Event(&n)
+	if !ok {
+		return c, false
+	}
+	if ev.State != nil {
+		c.BackendState = ev.State.String()
+	}
+	// The initial status, printed in full, already has the exit node and
+	// peers.
+	if n.InitialStatus == nil {
+		c.ExitNodeID = ev.ExitNodeID
+		c.PeersChanged = ev.PeersChanged
+		c.PeersRemoved = ev.PeersRemoved
+		c.PeerChanges = ev.PeerChangedPatch
+	}
+	ok = c.BackendState != "" || c.ExitNodeID != nil || len(c.PeersChanged) > 0 || len(c.PeersRemoved) > 0 || len(c.PeerChanges) > 0
+	return c, ok
+}
@@ -8,6 +8,7 @@
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/hex"
 	jsonv1 "encoding/json"
@@ -16,6 +17,7 @@
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -480,10 +482,55 @@ func runTailnetLockAdd(ctx context.Context, addArgs []string) error {
     used to bring up nodes under tailnet lock
 
 If any of the key arguments begin with "file:", the key is retrieved from
-the file at the path specified in the argument suffix.`,
+the file at the path specified in the argument suffix.
+
+To sign a node key with a tailnet lock key that's held in a hardware
+security module (such as a PIV smart card or a TPM) rather than by this
+device, pass the key's public half with --signer-key and a program that
+signs with it with --signer-cmd. The program is given the hex-encoded
+signature hash on stdin and must print the hex-encoded ed25519 signature.`,
+	FlagSet: (func() *flag.FlagSet {
+		fs := newFlagSet("lock sign")
+		fs.StringVar(&tlSignArgs.signerKey, "signer-key", "", "tailnet lock key (tlpub:...) to sign with using --signer-cmd, instead of this device's key")
+		fs.StringVar(&tlSignArgs.signerCmd, "signer-cmd", "", "program to sign with the --signer-key key")
+		return fs
+	})(),
 	Exec: runTailnetLockSign,
 }
 
+var tlSignArgs struct {
+	signerKey string
+	signerCmd string
+}
+
+// cmdSigner is a [tka.NodeKeySigner] that signs with key by running cmd,
+// as for "tailscale lock sign --signer-cmd".
+type cmdSigner struct {
+	key key.NLPublic
+	cmd string
+}
+
+func (s cmdSigner) KeyID() tkatype.KeyID { return s.key.KeyID() }
+
+func (s cmdSigner) SignNKS(sigHash tkatype.NKSSigHash) ([]byte, error) {
+	args := strings.Fields(s.cmd)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(hex.EncodeToString(sigHash[:]) + "\n")
+	cmd.Stderr = Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("running %q: %w", s.cmd, err)
+	}
+	sig, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("decoding signature from %q: %w", s.cmd, err)
+	}
+	if !ed25519.Verify(s.key.Verifier(), sigHash[:], sig) {
+		return nil, fmt.Errorf("signature from %q does not verify with %v", s.cmd, s.key.CLIString())
+	}
+	return sig, nil
+}
+
 func runTailnetLockSign(ctx context.Context, args []string) error {
 	// If any of the arguments start with "file:", replace that argument
 	// with the contents of the file. We do this early, before the check
@@ -519,6 +566,22 @@ func runTailnetLockSign(ctx context.Context, args []string) error {
 		}
 	}
 
+	if tlSignArgs.signerKey != "" || tlSignArgs.signerCmd != "" {
+		if tlSignArgs.signerKey == "" || strings.TrimSpace(tlSignArgs.signerCmd) == "" {
+			return errors.New("--signer-key and --signer-cmd must be used together")
+		}
+		var signer cmdSigner
+		if err := signer.key.UnmarshalText([]byte(tlSignArgs.signerKey)); err != nil {
+			return fmt.Errorf("decoding --signer-key: %w", err)
+		}
+		signer.cmd = tlSignArgs.signerCmd
+		sig, err := tka.SignNodeKey(signer, nodeKey, []byte(rotationKey.Verifier()))
+		if err != nil {
+			return err
+		}
+		return localClient.TailnetLockSubmitSignature(ctx, nodeKey, sig.Serialize())
+	}
+
 	err := localClient.TailnetLockSign(ctx, nodeKey, []byte(rotationKey.Verifier()))
 	// Provide a better help message for when someone clicks through the signing flow
 	// on the wrong device.
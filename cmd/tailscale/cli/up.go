@@ -389,6 +389,12 @@ func prefsFromUpArgs(upArgs upArgsT, warnf logger.Logf, st *ipnstate.Status, goo
 func netfilterModeFromFlag(v string) (_ preftype.NetfilterMode, warning string, _ error) {
 	switch v {
 	case "on", "nodivert", "off":
+	case "iptables", "nftables":
+		// A common mix-up: --netfilter-mode is how much of netfilter
+		// tailscaled manages, not which implementation it uses, which is
+		// auto-detected. Both the iptables and native nftables backends
+		// install rules only in their own ts-* chains.
+		return preftype.NetfilterOn, "", fmt.Errorf("invalid value --netfilter-mode=%q (want on, nodivert or off); it sets how much of netfilter tailscaled manages, and the %s backend is chosen automatically", v, v)
 	default:
 		return preftype.NetfilterOn, "", fmt.Errorf("invalid value --netfilter-mode=%q", v)
 	}
@@ -0,0 +1,59 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// crashLogLines is how many of the last lines of output of a crashed
+	// subprocess babysitProc saves, enough for a panic's goroutine dump in
+	// most cases.
+	crashLogLines = 2000
+
+	// maxCrashLogs is how many crash logs are kept in crashLogDir.
+	maxCrashLogs = 10
+)
+
+// crashLogDir returns the directory that babysitProc saves the output of
+// crashed subprocesses to.
+func crashLogDir() string {
+	return filepath.Join(os.Getenv("ProgramData"), "Tailscale", "crashes")
+}
+
+// saveCrashLog writes a crash log to dir for a subprocess that exited at
+// now with exitErr, after printing lines, and removes the oldest crash
+// logs so that only maxCrashLogs remain. It returns the path written.
+func saveCrashLog(dir string, now time.Time, exitErr error, lines []string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "tailscaled subprocess exited at %v: %v\n\n", now.UTC().Format(time.RFC3339), exitErr)
+	for _, l := range lines {
+		sb.WriteString(l)
+	}
+	name := filepath.Join(dir, "crash-"+now.UTC().Format("20060102T150405.000Z")+".txt")
+	if err := os.WriteFile(name, []byte(sb.String()), 0600); err != nil {
+		return "", err
+	}
+
+	// The names sort by time, so delete from the front.
+	old, err := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+	if err != nil {
+		return name, err
+	}
+	slices.Sort(old)
+	for len(old) > maxCrashLogs {
+		os.Remove(old[0])
+		old = old[1:]
+	}
+	return name, nil
+}
@@ -0,0 +1,41 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveCrashLog(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Unix(1700000000, 0)
+	var last string
+	for i := range maxCrashLogs + 3 {
+		name, err := saveCrashLog(dir, start.Add(time.Duration(i)*time.Second), errors.New("exit status 2"), []string{"panic: boom\n", "goroutine 1\n"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = name
+	}
+
+	logs, err := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != maxCrashLogs {
+		t.Errorf("got %d crash logs; want %d", len(logs), maxCrashLogs)
+	}
+	b, err := os.ReadFile(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); !strings.Contains(got, "exit status 2") || !strings.HasSuffix(got, "panic: boom\ngoroutine 1\n") {
+		t.Errorf("crash log contents = %q", got)
+	}
+}
@@ -726,6 +726,7 @@ func getLocalBackend(ctx context.Context, logf logger.Logf, logID logid.PublicID
 	}
 	if root := lb.TailscaleVarRoot(); root != "" {
 		dnsfallback.SetCachePath(filepath.Join(root, "derpmap.cached.json"), logf)
+		dnsfallback.SetHostCachePath(filepath.Join(root, "dns-fallback-hosts.cached.json"), logf)
 	}
 	if f, ok := hookConfigureWebClient.GetOk(); ok {
 		f(lb)
@@ -54,6 +54,7 @@
 	"tailscale.com/types/logid"
 	"tailscale.com/util/backoff"
 	"tailscale.com/util/osdiag"
+	"tailscale.com/util/ringlog"
 	"tailscale.com/util/syspolicy/pkey"
 	"tailscale.com/util/syspolicy/policyclient"
 	"tailscale.com/util/winutil"
@@ -487,13 +488,19 @@ func babysitProc(ctx context.Context, args []string, logf logger.Logf) {
 		if err != nil {
 			log.Printf("os.Pipe 2: %v", err)
 		}
+		// tail is the last of the output, saved by saveCrashLog if the
+		// subproc crashes.
+		tail := ringlog.New[string](crashLogLines)
+		outDone := make(chan struct{})
 		go func(r *os.File) {
+			defer close(outDone)
 			defer r.Close()
 			rb := bufio.NewReader(r)
 			for {
 				s, err := rb.ReadString('\n')
 				if s != "" {
 					logf("%s", s)
+					tail.Add(s)
 				}
 				if err != nil {
 					break
@@ -522,6 +529,23 @@ func babysitProc(ctx context.Context, args []string, logf logger.Logf) {
 
 			err = cmd.Wait()
 			log.Printf("subprocess exited: %v", err)
+			if err != nil {
+				select {
+				case <-done:
+					// Shutting down; not a crash.
+				default:
+					// Let the output goroutine catch the last of it.
+					select {
+					case <-outDone:
+					case <-time.After(time.Second):
+					}
+					if name, err := saveCrashLog(crashLogDir(), time.Now(), err, tail.GetAll()); err != nil {
+						log.Printf("saving crash log: %v", err)
+					} else {
+						log.Printf("saved crash log to %v", name)
+					}
+				}
+			}
 		}
 
 		// If the process finishes, clean up the write side of the
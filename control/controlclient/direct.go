@@ -295,6 +295,13 @@ type DiscoKeyUpdater interface {
 	PatchDiscoKey(key.NodePublic, key.DiscoPublic)
 }
 
+func init() {
+	// Every client can log in interactively at the URL control sends, and
+	// with an auth key.
+	feature.RegisterAuthMethod("sso")
+	feature.RegisterAuthMethod("authkey")
+}
+
 var nextControlClientID atomic.Int64
 
 // NewDirect returns a new Direct client.
@@ -102,6 +102,7 @@ type Client struct {
 	connGen      int // incremented once per new connection; valid values are >0
 	serverPubKey key.NodePublic
 	tlsState     *tls.ConnectionState
+	wsFallback   bool                             // whether to use websockets because the DERP upgrade failed
 	pingOut      map[derp.PingMessage]chan<- bool // chan to send to on pong
 	clock        tstime.Clock
 }
@@ -335,6 +336,22 @@ func useWebsockets() bool {
 	return false
 }
 
+// noteUpgradeFailedLocked notes that the server answered the HTTP upgrade to
+// DERP with something other than 101 Switching Protocols, as happens behind
+// proxies and middleboxes that don't pass the Upgrade header through, so that
+// the next connect falls back to websockets, if compiled in. It stays that way
+// for c until a websocket dial fails. Errors reading the response don't count;
+// they aren't evidence of such a proxy.
+//
+// c.mu must be held.
+func (c *Client) noteUpgradeFailedLocked(caller string) {
+	if !canWebsockets || c.wsFallback {
+		return
+	}
+	c.logf("%s: DERP upgrade failed; falling back to websockets", caller)
+	c.wsFallback = true
+}
+
 func (c *Client) connect(ctx context.Context, caller string) (client *derp.Client, connGen int, err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -396,7 +413,7 @@ func (c *Client) connect(ctx context.Context, caller string) (client *derp.Clien
 	var node *tailcfg.DERPNode // nil when using c.url to dial
 	var idealNodeInRegion bool
 	switch {
-	case canWebsockets && useWebsockets():
+	case canWebsockets && (useWebsockets() || c.wsFallback):
 		var urlStr string
 		if c.url != nil {
 			urlStr = c.url.String()
@@ -407,6 +424,9 @@ func (c *Client) connect(ctx context.Context, caller string) (client *derp.Clien
 		conn, err := dialWebsocketFunc(ctx, urlStr)
 		if err != nil {
 			c.logf("%s: websocket to %v error: %v", caller, urlStr, err)
+			// Go back to trying the regular upgrade next time; whatever
+			// broke it may have been fixed.
+			c.wsFallback = false
 			return nil, 0, err
 		}
 		brw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
@@ -552,6 +572,7 @@ func (c *Client) connect(ctx context.Context, caller string) (client *derp.Clien
 		if resp.StatusCode != http.StatusSwitchingProtocols {
 			b, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
+			c.noteUpgradeFailedLocked(caller)
 			return nil, 0, fmt.Errorf("GET failed: %v: %s", err, b)
 		}
 	}
@@ -269,6 +269,28 @@ func newWatcherClient(t *testing.T, watcherPrivateKey key.NodePrivate, serverToW
 	return
 }
 
+// TestWebsocketFallback tests that a failed DERP upgrade, as seen behind
+// proxies that don't pass it through, makes the client fall back to
+// websockets when they're compiled in.
+func TestWebsocketFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upgrade not allowed", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c, err := derphttp.NewClient(key.NewNode(), srv.URL, t.Logf, netmon.NewStatic())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+	if err := c.Connect(context.Background()); err == nil {
+		t.Fatal("Connect succeeded; want error")
+	}
+	if got := c.WebsocketFallback(); got != derphttp.CanWebsockets {
+		t.Errorf("WebsocketFallback = %v; want %v", got, derphttp.CanWebsockets)
+	}
+}
+
 // Test that a watcher connection successfully reconnects and processes peer
 // updates after a different thread breaks and reconnects the connection, while
 // the watcher is waiting on recv().
@@ -22,3 +22,11 @@ func (c *Client) BreakConnection(brokenClient *Client) {
 }
 
 var RetryInterval = &retryInterval
+
+const CanWebsockets = canWebsockets
+
+func (c *Client) WebsocketFallback() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.wsFallback
+}
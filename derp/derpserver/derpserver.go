@@ -9,6 +9,7 @@
 import (
 	"bufio"
 	"bytes"
+	"cmp"
 	"context"
 	"crypto/ed25519"
 	crand "crypto/rand"
@@ -192,6 +193,7 @@ type Server struct {
 
 	perClientSendQueueDepth int // Sets the client send queue depth for the server.
 	tcpWriteTimeout         time.Duration
+	perClientMetrics        int // max clients in the per-client metrics; 0 for none
 	clock                   tstime.Clock
 
 	mu       syncs.Mutex // guards the following fields
@@ -518,6 +520,16 @@ func (s *Server) SetTCPWriteTimeout(d time.Duration) {
 	s.tcpWriteTimeout = d
 }
 
+// SetPerClientMetrics sets the maximum number of clients, busiest first,
+// whose packet byte counts are published by [Server.ExpVar]. Each client
+// becomes a pair of metric labels, so n bounds the cardinality.
+// Defaults to 0, which publishes no per-client metrics.
+//
+// It must be called before ExpVar.
+func (s *Server) SetPerClientMetrics(n int) {
+	s.perClientMetrics = n
+}
+
 // minRateLimitTokenBucketSize represents the minimum size of a token bucket
 // applied for the purposes of rate limiting a DERP connection per received DERP
 // frame.
@@ -1329,6 +1341,7 @@ func (c *sclient) handleFrameSendPacket(_ derp.FrameType, fl uint32) error {
 	if err != nil {
 		return fmt.Errorf("client %v: recvPacket: %v", c.key, err)
 	}
+	c.bytesRecv.Add(int64(len(contents)))
 
 	dst, fwd, dstLen := c.lookupDest(dstKey)
 
@@ -1878,6 +1891,10 @@ type sclient struct {
 	// TODO: consider porting the required APIs from [xrate.Limiter] to [rate.Limiter],
 	// which is already optimized to use [mono.Time].
 	recvLim atomic.Pointer[xrate.Limiter]
+
+	// bytesRecv and bytesSent count the packet bytes received from and
+	// sent to this client, for the per-client metrics.
+	bytesRecv, bytesSent atomic.Int64
 }
 
 func (c *sclient) presentFlags() derp.PeerPresentFlags {
@@ -2214,6 +2231,7 @@ func (c *sclient) sendPacket(srcKey key.NodePublic, contents []byte) (err error)
 		} else {
 			c.s.packetsSent.Add(1)
 			c.s.bytesSent.Add(int64(len(contents)))
+			c.bytesSent.Add(int64(len(contents)))
 		}
 		c.debugLogf("sendPacket from %s: %v", srcKey.ShortString(), err)
 	}()
@@ -2473,9 +2491,55 @@ func (s *Server) ExpVar(rateLimitEnabled bool) expvar.Var {
 		}))
 		m.Set("rate_limit_per_client_waited", &s.rateLimitPerClientWaited)
 	}
+	if s.perClientMetrics > 0 {
+		m.Set("client_bytes", clientBytesMetric{s})
+	}
 	return m
 }
 
+// clientBytesMetric is the per-client metric of packet bytes received from
+// and sent to the [Server.SetPerClientMetrics] busiest local clients.
+type clientBytesMetric struct {
+	s *Server
+}
+
+func (m clientBytesMetric) String() string {
+	// NOTE: This has to be valid JSON because it's used by expvar.
+	return `"clientBytesMetric"`
+}
+
+// WritePrometheus implements tsweb/varz.PrometheusWriter.
+func (m clientBytesMetric) WritePrometheus(w io.Writer, name string) {
+	type clientBytes struct {
+		key        key.NodePublic
+		recv, sent int64
+	}
+	var all []clientBytes
+	m.s.mu.Lock()
+	for k, cs := range m.s.clients.All() {
+		cb := clientBytes{key: k}
+		cs.ForeachClient(func(c *sclient) {
+			cb.recv += c.bytesRecv.Load()
+			cb.sent += c.bytesSent.Load()
+		})
+		all = append(all, cb)
+	}
+	m.s.mu.Unlock()
+
+	slices.SortFunc(all, func(a, b clientBytes) int {
+		return cmp.Compare(b.recv+b.sent, a.recv+a.sent)
+	})
+	if len(all) > m.s.perClientMetrics {
+		all = all[:m.s.perClientMetrics]
+	}
+	fmt.Fprintf(w, "# TYPE %s counter\n", name)
+	for _, cb := range all {
+		k := cb.key.ShortString()
+		fmt.Fprintf(w, "%s{client=%q,direction=\"recv\"} %d\n", name, k, cb.recv)
+		fmt.Fprintf(w, "%s{client=%q,direction=\"sent\"} %d\n", name, k, cb.sent)
+	}
+}
+
 func (s *Server) ConsistencyCheck() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
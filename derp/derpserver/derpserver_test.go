@@ -1666,3 +1666,33 @@ func BenchmarkSenderCardinalityOverhead(b *testing.B) {
 		}
 	})
 }
+
+func TestClientBytesMetric(t *testing.T) {
+	s := New(key.NewNode(), logger.Discard)
+	defer s.Close()
+	s.SetPerClientMetrics(2)
+
+	var keys []key.NodePublic
+	for i := range 3 {
+		k := key.NewNode().Public()
+		c := &sclient{key: k, logf: logger.Discard}
+		c.bytesRecv.Add(int64(100 * (i + 1)))
+		c.bytesSent.Add(int64(i + 1))
+		cs := &clientSet{}
+		cs.activeClient.Store(c)
+		s.clients.Store(k, cs)
+		keys = append(keys, k)
+	}
+
+	var buf bytes.Buffer
+	clientBytesMetric{s}.WritePrometheus(&buf, "derp_client_bytes")
+	want := fmt.Sprintf(`# TYPE derp_client_bytes counter
+derp_client_bytes{client=%[1]q,direction="recv"} 300
+derp_client_bytes{client=%[1]q,direction="sent"} 3
+derp_client_bytes{client=%[2]q,direction="recv"} 200
+derp_client_bytes{client=%[2]q,direction="sent"} 2
+`, keys[2].ShortString(), keys[1].ShortString())
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
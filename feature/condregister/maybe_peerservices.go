@@ -0,0 +1,8 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !ts_omit_peerservices
+
+package condregister
+
+import _ "tailscale.com/feature/peerservices"
@@ -6,7 +6,9 @@
 
 import (
 	"errors"
+	"maps"
 	"reflect"
+	"slices"
 
 	"tailscale.com/util/testenv"
 )
@@ -37,6 +39,38 @@ func Register(name string) {
 	in[name] = true
 }
 
+var authMethods = map[string]bool{}
+
+// RegisterAuthMethod notes that the named method of logging in to a tailnet,
+// such as "sso" or "authkey", is supported by this binary. It's called from
+// the init functions of the packages implementing them. See
+// [SupportedAuthMethods].
+func RegisterAuthMethod(name string) {
+	if authMethods[name] {
+		panic("duplicate auth method registration for " + name)
+	}
+	authMethods[name] = true
+}
+
+// SupportedAuthMethods returns the sorted names of the methods of logging in
+// to a tailnet that this binary supports, so that login UIs offer only
+// those. They're registered with [RegisterAuthMethod]:
+//
+//   - "sso": interactive login at the URL the control server sends,
+//     through the tailnet's identity provider; from control/controlclient
+//   - "authkey": logging in with a pre-authentication key; from
+//     control/controlclient
+//   - "oauth": logging in with an OAuth client secret, which is exchanged
+//     for an auth key; from the oauthkey feature
+//
+// No client supports "webauthn" itself: security keys and passkeys are used
+// in the browser during SSO login.
+//
+// The returned slice is a copy that the caller may modify.
+func SupportedAuthMethods() []string {
+	return slices.Sorted(maps.Keys(authMethods))
+}
+
 // Hook is a func that can only be set once.
 //
 // It is not safe for concurrent use.
@@ -116,3 +150,35 @@ func (h *Hooks[Func]) Add(f Func) {
 	}
 	*h = append(*h, f)
 }
+
+// HasFunnel reports whether this binary can serve Tailscale Funnel, which
+// publishes a local service to the internet through Tailscale's ingress
+// relays. Funnel is part of serve and so is compiled out with it by the
+// ts_omit_serve build tag; the serve support in ipn/ipnlocal registers the
+// "funnel" feature.
+func HasFunnel() bool { return IsRegistered("funnel") }
+
+// HasLogUpload reports whether this binary can upload its logs to
+// log.tailscale.com, which bug reports and support bundles depend on. The
+// logtail package registers the "logupload" feature; it is compiled out by the
+// ts_omit_logtail build tag.
+//
+// It reports build capability only: uploads may still be turned off at run
+// time, such as by TS_NO_LOGS_NO_SUPPORT.
+func HasLogUpload() bool { return IsRegistered("logupload") }
+
+// HasEmbeddedDERPMap reports whether this binary embeds a fallback DERP map,
+// used to reach DERP servers (and their fallback DNS servers) when the
+// control plane is unreachable. The package embedding it registers the
+// "embeddedderpmap" feature. See [EmbeddedDERPRegions] for what it covers.
+func HasEmbeddedDERPMap() bool { return IsRegistered("embeddedderpmap") }
+
+// EmbeddedDERPRegions returns the sorted region codes (such as "nyc" or
+// "fra") of the fallback DERP map embedded in this binary, or nil if it
+// doesn't embed one. See [HasEmbeddedDERPMap].
+func EmbeddedDERPRegions() []string {
+	if f, ok := HookEmbeddedDERPRegions.GetOk(); ok {
+		return f()
+	}
+	return nil
+}
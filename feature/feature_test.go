@@ -0,0 +1,104 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package feature
+
+import (
+	"slices"
+	"testing"
+)
+
+// setRegisteredForTest replaces the set of registered features with names for
+// the duration of the test.
+func setRegisteredForTest(t *testing.T, names ...string) {
+	old := in
+	t.Cleanup(func() { in = old })
+	in = map[string]bool{}
+	for _, name := range names {
+		Register(name)
+	}
+}
+
+// setAuthMethodsForTest replaces the set of registered auth methods with
+// names for the duration of the test.
+func setAuthMethodsForTest(t *testing.T, names ...string) {
+	old := authMethods
+	t.Cleanup(func() { authMethods = old })
+	authMethods = map[string]bool{}
+	for _, name := range names {
+		RegisterAuthMethod(name)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	setRegisteredForTest(t, "foo")
+	if !IsRegistered("foo") {
+		t.Error("foo not registered")
+	}
+	if IsRegistered("bar") {
+		t.Error("bar unexpectedly registered")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate Register did not panic")
+		}
+	}()
+	Register("foo")
+}
+
+func TestHasFunnel(t *testing.T) {
+	setRegisteredForTest(t)
+	if HasFunnel() {
+		t.Error("HasFunnel = true with nothing registered")
+	}
+	Register("funnel")
+	if !HasFunnel() {
+		t.Error("HasFunnel = false after registering funnel")
+	}
+}
+
+func TestHasLogUpload(t *testing.T) {
+	setRegisteredForTest(t)
+	if HasLogUpload() {
+		t.Error("HasLogUpload = true with nothing registered")
+	}
+	Register("logupload")
+	if !HasLogUpload() {
+		t.Error("HasLogUpload = false after registering logupload")
+	}
+}
+
+func TestEmbeddedDERPMap(t *testing.T) {
+	setRegisteredForTest(t)
+	if HasEmbeddedDERPMap() {
+		t.Error("HasEmbeddedDERPMap = true with nothing registered")
+	}
+	Register("embeddedderpmap")
+	if !HasEmbeddedDERPMap() {
+		t.Error("HasEmbeddedDERPMap = false after registering embeddedderpmap")
+	}
+
+	if got := EmbeddedDERPRegions(); got != nil {
+		t.Errorf("EmbeddedDERPRegions = %q with no hook; want nil", got)
+	}
+	defer HookEmbeddedDERPRegions.SetForTest(func() []string { return []string{"fra", "nyc"} })()
+	if got := EmbeddedDERPRegions(); !slices.Equal(got, []string{"fra", "nyc"}) {
+		t.Errorf("EmbeddedDERPRegions = %q; want [fra nyc]", got)
+	}
+}
+
+func TestSupportedAuthMethods(t *testing.T) {
+	setAuthMethodsForTest(t)
+	if got := SupportedAuthMethods(); len(got) != 0 {
+		t.Errorf("SupportedAuthMethods = %q with nothing registered", got)
+	}
+	setAuthMethodsForTest(t, "sso", "oauth", "authkey")
+	got := SupportedAuthMethods()
+	if want := []string{"authkey", "oauth", "sso"}; !slices.Equal(got, want) {
+		t.Errorf("SupportedAuthMethods = %q; want %q", got, want)
+	}
+	got[0] = "modified"
+	if SupportedAuthMethods()[0] != "authkey" {
+		t.Error("modifying the result changed the registry")
+	}
+}
@@ -118,6 +118,10 @@ type FeatureMeta struct {
 		Desc: "Bird BGP integration",
 		Deps: []FeatureTag{"advertiseroutes"},
 	},
+	"bugreportbundle": {
+		Sym:  "BugReportBundle",
+		Desc: "'tailscale bugreport --bundle' archive of local diagnostics",
+	},
 	"c2n": {
 		Sym:                  "C2N",
 		Desc:                 "Control-to-node (C2N) support",
@@ -186,6 +190,11 @@ type FeatureMeta struct {
 		Desc: "upload logs to log.tailscale.com (debug logs for bug reports and also by network flow logs if enabled)",
 	},
 	"oauthkey": {Sym: "OAuthKey", Desc: "OAuth secret-to-authkey resolution support"},
+	"otlpmetrics": {
+		Sym:  "OTLPMetrics",
+		Desc: "Export client metrics to an OpenTelemetry collector over OTLP",
+		Deps: []FeatureTag{"clientmetrics"},
+	},
 	"outboundproxy": {
 		Sym:  "OutboundProxy",
 		Desc: "Support running an outbound localhost HTTP/SOCK5 proxy support that sends traffic over Tailscale",
@@ -211,6 +220,11 @@ type FeatureMeta struct {
 		Desc:                 "PeerAPI server support",
 		ImplementationDetail: true,
 	},
+	"peerservices": {
+		Sym:  "PeerServices",
+		Desc: "Advertise named services to peers over the PeerAPI, for service discovery",
+		Deps: []FeatureTag{"peerapiclient", "peerapiserver"},
+	},
 	"portlist":   {Sym: "PortList", Desc: "Optionally advertise listening service ports"},
 	"portmapper": {Sym: "PortMapper", Desc: "NAT-PMP/PCP/UPnP port mapping support"},
 	"posture":    {Sym: "Posture", Desc: "Device posture checking support"},
@@ -245,6 +259,11 @@ type FeatureMeta struct {
 		Sym:  "RouteCheck",
 		Desc: "Support checking the reachability of overlapping routers, for choosing between multiple network paths to the same IP address",
 	},
+	"routehealth": {
+		Sym:  "RouteHealth",
+		Desc: "Withdraw advertised subnet routes while upstream health checks fail, for subnet router failover",
+		Deps: []FeatureTag{"advertiseroutes"},
+	},
 	"runtimemetrics": {
 		Sym:  "RuntimeMetrics",
 		Desc: "Support emission of runtime/metrics as clientmetrics",
@@ -4,8 +4,10 @@
 package feature
 
 import (
+	"context"
 	"io"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
 	"sync"
@@ -29,6 +31,21 @@
 // the same place.
 var HookLogSink Hook[func() io.Writer]
 
+// HookEmbeddedDERPRegions is a hook for the net/dnsfallback package to
+// report the region codes of its embedded DERP map. See
+// [EmbeddedDERPRegions].
+var HookEmbeddedDERPRegions Hook[func() []string]
+
+// HookProbePortMapping is a hook for the portmapper feature to probe the
+// default gateway for the port mapping protocols it supports. It's used by
+// [tailscale.com/net/preflight.PortMappingAvailable].
+var HookProbePortMapping Hook[func(context.Context) (upnp, pmp, pcp bool, err error)]
+
+// HookGatewayExternalIP is a hook for the portmapper feature to ask the
+// default gateway for its external (WAN) address with NAT-PMP or UPnP. It's
+// used by [tailscale.com/net/preflight.DoubleNAT].
+var HookGatewayExternalIP Hook[func(context.Context) (netip.Addr, error)]
+
 // HookCanAutoUpdate is a hook for the clientupdate package
 // to conditionally initialize.
 var HookCanAutoUpdate Hook[func() bool]
@@ -20,6 +20,7 @@
 
 func init() {
 	feature.Register("oauthkey")
+	feature.RegisterAuthMethod("oauth")
 	tailscale.HookResolveAuthKey.Set(resolveAuthKey)
 }
 
@@ -0,0 +1,309 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package otlpmetrics registers the OTLP metrics exporter feature, which
+// periodically pushes the client metrics (see [clientmetric]) to an
+// OpenTelemetry collector using OTLP over HTTP with JSON encoding, so that
+// fleets can see per-node metrics in their existing observability stack.
+//
+// The exporter is off unless TS_OTLP_METRICS_ENDPOINT is set to the full
+// URL of the collector's metrics endpoint, such as
+// "http://collector:4318/v1/metrics".
+package otlpmetrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tailscale.com/envknob"
+	"tailscale.com/feature"
+	"tailscale.com/ipn/ipnext"
+	"tailscale.com/tailcfg"
+	"tailscale.com/tstime"
+	"tailscale.com/types/logger"
+	"tailscale.com/util/clientmetric"
+	"tailscale.com/version"
+)
+
+func init() {
+	feature.Register("otlpmetrics")
+	ipnext.RegisterExtension("otlpmetrics", newExtension)
+}
+
+var (
+	// endpoint is the URL to POST metrics to.
+	endpoint = envknob.RegisterString("TS_OTLP_METRICS_ENDPOINT")
+	// headers are extra HTTP headers to send, as comma-separated
+	// key=value pairs, such as for an API key.
+	headers = envknob.RegisterString("TS_OTLP_METRICS_HEADERS")
+	// interval is how often to push metrics. It defaults to a minute.
+	interval = envknob.RegisterDuration("TS_OTLP_METRICS_INTERVAL")
+)
+
+const defaultInterval = time.Minute
+
+func newExtension(logf logger.Logf, sb ipnext.SafeBackend) (ipnext.Extension, error) {
+	url := endpoint()
+	if url == "" {
+		return nil, ipnext.SkipExtension
+	}
+	hdrs, err := parseHeaders(headers())
+	if err != nil {
+		return nil, fmt.Errorf("TS_OTLP_METRICS_HEADERS: %w", err)
+	}
+	e := &Extension{
+		logf:    logger.WithPrefix(logf, "otlpmetrics: "),
+		clock:   sb.Clock(),
+		url:     url,
+		headers: hdrs,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		done:    make(chan struct{}),
+		start:   sb.Clock().Now(),
+	}
+	e.ctx, e.ctxCancel = context.WithCancel(context.Background())
+	return e, nil
+}
+
+// Extension implements the OTLP metrics exporter.
+type Extension struct {
+	logf      logger.Logf
+	clock     tstime.Clock
+	url       string
+	headers   map[string]string
+	client    *http.Client
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	done      chan struct{} // closed when the push loop exits
+	start     time.Time     // start time of the cumulative counters
+
+	mu   sync.Mutex
+	self tailcfg.NodeView // or invalid before the first netmap
+}
+
+func (e *Extension) Name() string { return "otlpmetrics" }
+
+func (e *Extension) Init(h ipnext.Host) error {
+	h.Hooks().OnSelfChange.Add(e.onSelfChange)
+	go e.pushLoop()
+	return nil
+}
+
+func (e *Extension) Shutdown() error {
+	e.ctxCancel()
+	<-e.done
+	return nil
+}
+
+func (e *Extension) onSelfChange(self tailcfg.NodeView) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.self = self
+}
+
+func (e *Extension) pushLoop() {
+	defer close(e.done)
+	d := interval()
+	if d <= 0 {
+		d = defaultInterval
+	}
+	ticker, tickc := e.clock.NewTicker(d)
+	defer ticker.Stop()
+	var failing bool
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-tickc:
+		}
+		err := e.push(e.ctx)
+		switch {
+		case err != nil && !failing:
+			e.logf("pushing metrics: %v", err)
+		case err == nil && failing:
+			e.logf("pushing metrics: recovered")
+		}
+		failing = err != nil
+	}
+}
+
+// push sends the current metric values to the collector.
+func (e *Extension) push(ctx context.Context) error {
+	e.mu.Lock()
+	self := e.self
+	e.mu.Unlock()
+
+	return e.pushRequest(ctx, exportRequest(resourceAttributes(self), e.start, e.clock.Now(), clientmetric.Metrics()))
+}
+
+// pushRequest sends r to the collector.
+func (e *Extension) pushRequest(ctx context.Context, r *otlpRequest) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1<<10))
+		return fmt.Errorf("%v: %s", res.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// parseHeaders parses s, a comma-separated list of key=value pairs, as in
+// the OTEL_EXPORTER_OTLP_HEADERS environment variable of OpenTelemetry
+// SDKs.
+func parseHeaders(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	m := make(map[string]string)
+	for kv := range strings.SplitSeq(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid header %q; want key=value", kv)
+		}
+		m[k] = strings.TrimSpace(v)
+	}
+	return m, nil
+}
+
+// The types below are the subset of the OTLP metrics data model, in its
+// protobuf JSON encoding, that the exporter uses. See
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+//
+// As in the protobuf JSON mapping, 64-bit integers are encoded as strings.
+
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpMetric struct {
+	Name  string     `json:"name"`
+	Sum   *otlpSum   `json:"sum,omitempty"`
+	Gauge *otlpGauge `json:"gauge,omitempty"`
+}
+
+type otlpSum struct {
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+	AggregationTemporality int             `json:"aggregationTemporality"`
+	IsMonotonic            bool            `json:"isMonotonic"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpDataPoint struct {
+	StartTimeUnixNano string `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string `json:"timeUnixNano"`
+	AsInt             string `json:"asInt"`
+}
+
+// aggregationTemporalityCumulative is the AggregationTemporality of sums
+// that are totals since some start time, as client metric counters are.
+const aggregationTemporalityCumulative = 2
+
+// resourceAttributes returns the OTLP resource attributes that identify
+// this node, from self if valid.
+func resourceAttributes(self tailcfg.NodeView) []otlpKeyValue {
+	attrs := []otlpKeyValue{
+		{"service.name", otlpAnyValue{"tailscaled"}},
+		{"service.version", otlpAnyValue{version.Long()}},
+		{"os.type", otlpAnyValue{runtime.GOOS}},
+	}
+	if !self.Valid() {
+		return attrs
+	}
+	host, tailnet, _ := strings.Cut(strings.TrimSuffix(self.Name(), "."), ".")
+	attrs = append(attrs,
+		otlpKeyValue{"host.name", otlpAnyValue{host}},
+		otlpKeyValue{"tailscale.node_id", otlpAnyValue{string(self.StableID())}},
+	)
+	if tailnet != "" {
+		attrs = append(attrs, otlpKeyValue{"tailscale.tailnet", otlpAnyValue{tailnet}})
+	}
+	return attrs
+}
+
+// exportRequest returns the OTLP export request for metrics at now, with
+// counters counted since start.
+func exportRequest(attrs []otlpKeyValue, start, now time.Time, metrics []*clientmetric.Metric) *otlpRequest {
+	startNano := strconv.FormatInt(start.UnixNano(), 10)
+	nowNano := strconv.FormatInt(now.UnixNano(), 10)
+	var ms []otlpMetric
+	for _, m := range metrics {
+		om := otlpMetric{Name: "tailscale." + m.Name()}
+		dp := otlpDataPoint{TimeUnixNano: nowNano, AsInt: strconv.FormatInt(m.Value(), 10)}
+		switch m.Type() {
+		case clientmetric.TypeCounter:
+			dp.StartTimeUnixNano = startNano
+			om.Sum = &otlpSum{
+				DataPoints:             []otlpDataPoint{dp},
+				AggregationTemporality: aggregationTemporalityCumulative,
+				IsMonotonic:            true,
+			}
+		default:
+			om.Gauge = &otlpGauge{DataPoints: []otlpDataPoint{dp}}
+		}
+		ms = append(ms, om)
+	}
+	return &otlpRequest{
+		ResourceMetrics: []otlpResourceMetrics{{
+			Resource: otlpResource{Attributes: attrs},
+			ScopeMetrics: []otlpScopeMetrics{{
+				Scope:   otlpScope{Name: "tailscale.com/util/clientmetric", Version: version.Short()},
+				Metrics: ms,
+			}},
+		}},
+	}
+}
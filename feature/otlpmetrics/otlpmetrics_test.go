@@ -0,0 +1,100 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package otlpmetrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tailscale.com/tailcfg"
+	"tailscale.com/tstest"
+	"tailscale.com/types/logger"
+	"tailscale.com/util/clientmetric"
+)
+
+func TestParseHeaders(t *testing.T) {
+	got, err := parseHeaders("api-key=secret, x-tenant = acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["api-key"] != "secret" || got["x-tenant"] != "acme" {
+		t.Errorf("got %v", got)
+	}
+	if _, err := parseHeaders("novalue"); err == nil {
+		t.Error("parseHeaders(novalue) succeeded; want error")
+	}
+}
+
+func TestPush(t *testing.T) {
+	counter := clientmetric.NewCounter("test_otlp_counter")
+	counter.Add(3)
+	gauge := clientmetric.NewGauge("test_otlp_gauge")
+	gauge.Set(-5)
+
+	var got otlpRequest
+	var gotHeader string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("Api-Key")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	clock := tstest.NewClock(tstest.ClockOpts{Start: time.Unix(100, 0)})
+	e := &Extension{
+		logf:    logger.Discard,
+		clock:   clock,
+		url:     ts.URL,
+		headers: map[string]string{"Api-Key": "secret"},
+		client:  ts.Client(),
+		start:   time.Unix(50, 0),
+		self: (&tailcfg.Node{
+			StableID: "nStable",
+			Name:     "host.example.ts.net.",
+		}).View(),
+	}
+	// Push just our test metrics, rather than all those registered.
+	req := exportRequest(resourceAttributes(e.self), e.start, clock.Now(), []*clientmetric.Metric{counter, gauge})
+	if err := e.pushRequest(t.Context(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotHeader != "secret" {
+		t.Errorf("Api-Key header = %q; want secret", gotHeader)
+	}
+	if len(got.ResourceMetrics) != 1 {
+		t.Fatalf("got %d resource metrics; want 1", len(got.ResourceMetrics))
+	}
+	rm := got.ResourceMetrics[0]
+	attrs := map[string]string{}
+	for _, kv := range rm.Resource.Attributes {
+		attrs[kv.Key] = kv.Value.StringValue
+	}
+	for k, want := range map[string]string{
+		"service.name":      "tailscaled",
+		"host.name":         "host",
+		"tailscale.tailnet": "example.ts.net",
+		"tailscale.node_id": "nStable",
+	} {
+		if attrs[k] != want {
+			t.Errorf("attribute %q = %q; want %q", k, attrs[k], want)
+		}
+	}
+	ms := rm.ScopeMetrics[0].Metrics
+	if len(ms) != 2 {
+		t.Fatalf("got %d metrics; want 2", len(ms))
+	}
+	if m := ms[0]; m.Name != "tailscale.test_otlp_counter" || m.Sum == nil || !m.Sum.IsMonotonic ||
+		m.Sum.DataPoints[0].AsInt != "3" || m.Sum.DataPoints[0].StartTimeUnixNano != "50000000000" ||
+		m.Sum.DataPoints[0].TimeUnixNano != "100000000000" {
+		t.Errorf("counter = %+v", m)
+	}
+	if m := ms[1]; m.Name != "tailscale.test_otlp_gauge" || m.Gauge == nil || m.Gauge.DataPoints[0].AsInt != "-5" {
+		t.Errorf("gauge = %+v", m)
+	}
+}
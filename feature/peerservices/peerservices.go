@@ -0,0 +1,294 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package peerservices registers the peer services feature, which lets
+// apps on a node advertise named services to the node's peers over the
+// PeerAPI, and query the services that peers advertise, so that tailnet
+// apps can discover each other without hard-coding hostnames and ports.
+//
+// Services are registered with tailscaled through the LocalAPI and are not
+// persisted: apps are expected to register their services each time they
+// (or tailscaled) start.
+//
+// A node's services can be listed by its own user's untagged devices and by
+// peers granted the [tailcfg.PeerCapabilityPeerServices] capability.
+package peerservices
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"tailscale.com/client/tailscale/apitype"
+	"tailscale.com/feature"
+	"tailscale.com/ipn"
+	"tailscale.com/ipn/ipnext"
+	"tailscale.com/ipn/ipnlocal"
+	"tailscale.com/ipn/localapi"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/logger"
+	"tailscale.com/util/clientmetric"
+	"tailscale.com/util/dnsname"
+	"tailscale.com/util/httpm"
+)
+
+func init() {
+	feature.Register("peerservices")
+	ipnext.RegisterExtension("peerservices", newExtension)
+	ipnlocal.RegisterPeerAPIHandler("/v0/services", handlePeerAPIServices)
+	localapi.Register("peer-services", servePeerServices)
+}
+
+// maxServices is the most services that can be registered at once.
+const maxServices = 100
+
+// maxPeerServicesResponseBytes is the most that's read of a peer's
+// response listing its services, comfortably more than maxServices need.
+const maxPeerServicesResponseBytes = 1 << 20
+
+func newExtension(logf logger.Logf, _ ipnext.SafeBackend) (ipnext.Extension, error) {
+	return &Extension{logf: logger.WithPrefix(logf, "peerservices: ")}, nil
+}
+
+// Extension implements the peer services extension. It holds the services
+// registered on this node.
+type Extension struct {
+	logf logger.Logf
+
+	mu       sync.Mutex
+	services map[string]apitype.PeerService // by name
+}
+
+func (e *Extension) Name() string    { return "peerservices" }
+func (e *Extension) Shutdown() error { return nil }
+
+func (e *Extension) Init(h ipnext.Host) error {
+	h.Hooks().ProfileStateChange.Add(e.onChangeProfile)
+	return nil
+}
+
+// onChangeProfile forgets the registered services when switching to
+// another node, so they aren't advertised to the peers of another tailnet.
+func (e *Extension) onChangeProfile(_ ipn.LoginProfileView, _ ipn.PrefsView, sameNode bool) {
+	if sameNode {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.services) > 0 {
+		e.logf("forgetting %d services on profile change", len(e.services))
+	}
+	e.services = nil
+}
+
+// Services returns the registered services, sorted by name.
+func (e *Extension) Services() []apitype.PeerService {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ret := make([]apitype.PeerService, 0, len(e.services))
+	for _, svc := range e.services {
+		ret = append(ret, svc)
+	}
+	slices.SortFunc(ret, func(a, b apitype.PeerService) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return ret
+}
+
+// Register registers svc, replacing any service registered with the same
+// name.
+func (e *Extension) Register(svc apitype.PeerService) error {
+	if svc.Proto == "" {
+		svc.Proto = "tcp"
+	}
+	if err := checkService(svc); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.services[svc.Name]; !ok && len(e.services) >= maxServices {
+		return fmt.Errorf("too many services registered; max %d", maxServices)
+	}
+	if e.services == nil {
+		e.services = make(map[string]apitype.PeerService)
+	}
+	e.services[svc.Name] = svc
+	return nil
+}
+
+// Unregister removes the service with the given name, reporting whether
+// there was one.
+func (e *Extension) Unregister(name string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.services[name]
+	delete(e.services, name)
+	return ok
+}
+
+// checkService reports whether svc is valid to register.
+func checkService(svc apitype.PeerService) error {
+	if err := dnsname.ValidLabel(svc.Name); err != nil {
+		return fmt.Errorf("invalid service name %q: %w", svc.Name, err)
+	}
+	if svc.Port == 0 {
+		return errors.New("service port must be set")
+	}
+	if svc.Proto != "tcp" && svc.Proto != "udp" {
+		return fmt.Errorf("invalid service protocol %q; want tcp or udp", svc.Proto)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+var metricPeerAPIServicesCalls = clientmetric.NewCounter("peerapi_services")
+
+// canListServices reports whether the peer of h may list the services
+// registered on this node: the node's own user's untagged devices can, and
+// other peers need to be granted [tailcfg.PeerCapabilityPeerServices].
+func canListServices(h ipnlocal.PeerAPIHandler) bool {
+	if h.Peer().UnsignedPeerAPIOnly() {
+		return false
+	}
+	return h.IsSelfUntagged() || h.PeerCaps().HasCapability(tailcfg.PeerCapabilityPeerServices)
+}
+
+// handlePeerAPIServices returns the services registered on this node to
+// a peer allowed to list them; see canListServices.
+func handlePeerAPIServices(h ipnlocal.PeerAPIHandler, w http.ResponseWriter, r *http.Request) {
+	metricPeerAPIServicesCalls.Add(1)
+	if !canListServices(h) {
+		http.Error(w, "services access denied", http.StatusForbidden)
+		return
+	}
+	if r.Method != httpm.GET {
+		http.Error(w, "want GET", http.StatusMethodNotAllowed)
+		return
+	}
+	e, ok := ipnlocal.GetExt[*Extension](h.LocalBackend())
+	if !ok {
+		http.Error(w, "services not available", http.StatusNotImplemented)
+		return
+	}
+	writeJSON(w, e.Services())
+}
+
+// servePeerServices handles the LocalAPI peer-services endpoint.
+//
+//   - GET lists the services registered on this node or, with a "peer"
+//     parameter of a peer's Tailscale IP, those advertised by that peer.
+//   - POST registers the JSON-encoded [apitype.PeerService] in the body.
+//   - DELETE unregisters the service named by the "name" parameter.
+func servePeerServices(h *localapi.Handler, w http.ResponseWriter, r *http.Request) {
+	e, ok := ipnlocal.GetExt[*Extension](h.LocalBackend())
+	if !ok {
+		http.Error(w, "services not available", http.StatusNotImplemented)
+		return
+	}
+	switch r.Method {
+	case httpm.GET:
+		if !h.PermitRead {
+			http.Error(w, "services access denied", http.StatusForbidden)
+			return
+		}
+		peer := r.FormValue("peer")
+		if peer == "" {
+			writeJSON(w, e.Services())
+			return
+		}
+		ip, err := netip.ParseAddr(peer)
+		if err != nil {
+			http.Error(w, "invalid 'peer' parameter", http.StatusBadRequest)
+			return
+		}
+		svcs, err := fetchPeerServices(h, r, ip)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeJSON(w, svcs)
+	case httpm.POST:
+		if !h.PermitWrite {
+			http.Error(w, "services access denied", http.StatusForbidden)
+			return
+		}
+		var svc apitype.PeerService
+		if err := json.NewDecoder(r.Body).Decode(&svc); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if err := e.Register(svc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case httpm.DELETE:
+		if !h.PermitWrite {
+			http.Error(w, "services access denied", http.StatusForbidden)
+			return
+		}
+		if !e.Unregister(r.FormValue("name")) {
+			http.Error(w, "no such service", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "want GET, POST or DELETE", http.StatusMethodNotAllowed)
+	}
+}
+
+// fetchPeerServices returns the services advertised by the peer with
+// Tailscale IP ip, as returned by its PeerAPI.
+func fetchPeerServices(h *localapi.Handler, r *http.Request, ip netip.Addr) ([]apitype.PeerService, error) {
+	nb := h.LocalBackend().NodeBackend()
+	peers := nb.AppendMatchingPeers(nil, func(p tailcfg.NodeView) bool {
+		return slices.ContainsFunc(p.Addresses().AsSlice(), func(pfx netip.Prefix) bool {
+			return pfx.IsSingleIP() && pfx.Addr() == ip
+		})
+	})
+	if len(peers) == 0 {
+		return nil, fmt.Errorf("no peer found with Tailscale IP %v", ip)
+	}
+	base := nb.PeerAPIBase(peers[0])
+	if base == "" {
+		return nil, fmt.Errorf("peer %v does not support the PeerAPI", ip)
+	}
+	req, err := http.NewRequestWithContext(r.Context(), httpm.GET, base+"/v0/services", nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Transport: h.LocalBackend().Dialer().PeerAPITransport(),
+		Timeout:   10 * time.Second,
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("peer %v does not advertise services", ip)
+	case http.StatusForbidden:
+		return nil, fmt.Errorf("peer %v does not allow listing its services", ip)
+	default:
+		return nil, fmt.Errorf("peer %v: %v", ip, res.Status)
+	}
+	var svcs []apitype.PeerService
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxPeerServicesResponseBytes)).Decode(&svcs); err != nil {
+		return nil, fmt.Errorf("peer %v: %w", ip, err)
+	}
+	return svcs, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package peerservices
+
+import (
+	"testing"
+
+	"tailscale.com/client/tailscale/apitype"
+	"tailscale.com/ipn"
+	"tailscale.com/ipn/ipnlocal"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/logger"
+)
+
+func TestRegister(t *testing.T) {
+	e := &Extension{logf: logger.Discard}
+
+	for _, svc := range []apitype.PeerService{
+		{Name: "web", Port: 80},
+		{Name: "dns", Port: 53, Proto: "udp"},
+		{Name: "web", Port: 8080, Metadata: map[string]string{"path": "/app"}},
+	} {
+		if err := e.Register(svc); err != nil {
+			t.Fatalf("Register(%+v): %v", svc, err)
+		}
+	}
+	got := e.Services()
+	if len(got) != 2 {
+		t.Fatalf("got %d services; want 2", len(got))
+	}
+	if got[0].Name != "dns" || got[0].Proto != "udp" {
+		t.Errorf("got[0] = %+v; want dns/udp", got[0])
+	}
+	if got[1].Name != "web" || got[1].Port != 8080 || got[1].Proto != "tcp" || got[1].Metadata["path"] != "/app" {
+		t.Errorf("got[1] = %+v; want web on 8080/tcp, replaced", got[1])
+	}
+
+	for _, bad := range []apitype.PeerService{
+		{Name: "", Port: 80},
+		{Name: "no spaces", Port: 80},
+		{Name: "noport"},
+		{Name: "sctp", Port: 80, Proto: "sctp"},
+	} {
+		if err := e.Register(bad); err == nil {
+			t.Errorf("Register(%+v) succeeded; want error", bad)
+		}
+	}
+
+	if !e.Unregister("web") {
+		t.Error("Unregister(web) = false; want true")
+	}
+	if e.Unregister("web") {
+		t.Error("second Unregister(web) = true; want false")
+	}
+	if got := e.Services(); len(got) != 1 {
+		t.Errorf("got %d services after Unregister; want 1", len(got))
+	}
+
+	e.onChangeProfile(ipn.LoginProfileView{}, ipn.PrefsView{}, true)
+	if got := e.Services(); len(got) != 1 {
+		t.Errorf("got %d services after same-node profile change; want 1", len(got))
+	}
+	e.onChangeProfile(ipn.LoginProfileView{}, ipn.PrefsView{}, false)
+	if got := e.Services(); len(got) != 0 {
+		t.Errorf("got %d services after profile change; want 0", len(got))
+	}
+}
+
+type fakePeerAPIHandler struct {
+	ipnlocal.PeerAPIHandler // nil; only the methods below are used
+
+	peer           tailcfg.NodeView
+	caps           tailcfg.PeerCapMap
+	isSelfUntagged bool
+}
+
+func (h fakePeerAPIHandler) Peer() tailcfg.NodeView       { return h.peer }
+func (h fakePeerAPIHandler) PeerCaps() tailcfg.PeerCapMap { return h.caps }
+func (h fakePeerAPIHandler) IsSelfUntagged() bool         { return h.isSelfUntagged }
+
+func TestCanListServices(t *testing.T) {
+	peer := (&tailcfg.Node{}).View()
+	unsignedPeer := (&tailcfg.Node{UnsignedPeerAPIOnly: true}).View()
+	granted := tailcfg.PeerCapMap{tailcfg.PeerCapabilityPeerServices: nil}
+	tests := []struct {
+		name string
+		h    fakePeerAPIHandler
+		want bool
+	}{
+		{"other-peer", fakePeerAPIHandler{peer: peer}, false},
+		{"self-untagged", fakePeerAPIHandler{peer: peer, isSelfUntagged: true}, true},
+		{"granted", fakePeerAPIHandler{peer: peer, caps: granted}, true},
+		{"unsigned-granted", fakePeerAPIHandler{peer: unsignedPeer, caps: granted}, false},
+	}
+	for _, tt := range tests {
+		if got := canListServices(tt.h); got != tt.want {
+			t.Errorf("%s: canListServices = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
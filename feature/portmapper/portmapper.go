@@ -6,6 +6,9 @@
 package portmapper
 
 import (
+	"context"
+	"net/netip"
+
 	"tailscale.com/feature"
 	"tailscale.com/net/netmon"
 	"tailscale.com/net/portmapper"
@@ -17,6 +20,8 @@
 func init() {
 	feature.Register("portmapper")
 	portmappertype.HookNewPortMapper.Set(newPortMapper)
+	feature.HookProbePortMapping.Set(probePortMapping)
+	feature.HookGatewayExternalIP.Set(gatewayExternalIP)
 }
 
 func newPortMapper(
@@ -38,3 +43,35 @@ func newPortMapper(
 	pm.SetGatewayLookupFunc(netMon.GatewayAndSelfIP)
 	return pm
 }
+
+// newProbeClient returns a throwaway portmapper client for probing the
+// default gateway, and a func to close it.
+func newProbeClient() (pm *portmapper.Client, close func()) {
+	bus := eventbus.New()
+	pm = portmapper.NewClient(portmapper.Config{
+		EventBus: bus,
+		NetMon:   netmon.NewStatic(),
+	})
+	return pm, func() {
+		pm.Close()
+		bus.Close()
+	}
+}
+
+// probePortMapping probes the default gateway for UPnP, NAT-PMP and PCP.
+func probePortMapping(ctx context.Context) (upnp, pmp, pcp bool, err error) {
+	pm, close := newProbeClient()
+	defer close()
+	res, err := pm.Probe(ctx)
+	if err != nil {
+		return false, false, false, err
+	}
+	return res.UPnP, res.PMP, res.PCP, nil
+}
+
+// gatewayExternalIP asks the default gateway for its external address.
+func gatewayExternalIP(ctx context.Context) (netip.Addr, error) {
+	pm, close := newProbeClient()
+	defer close()
+	return pm.GatewayExternalIP(ctx)
+}
@@ -0,0 +1,205 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package routehealth registers the route health feature, which makes a
+// subnet router stop advertising its subnet routes while it can't reach
+// its upstream network, so that another subnet router advertising the
+// same routes takes over as primary within seconds, rather than after
+// clients notice the dead path.
+//
+// It's off unless TS_ROUTE_HEALTHCHECK is set to a comma-separated list of
+// "host:port" TCP targets on the routed networks. The upstream network is
+// considered down when none of the targets accept a connection for
+// several checks in a row, and up again after several healthy checks.
+package routehealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"tailscale.com/envknob"
+	"tailscale.com/feature"
+	"tailscale.com/health"
+	"tailscale.com/ipn/ipnext"
+	"tailscale.com/ipn/ipnlocal"
+	"tailscale.com/tstime"
+	"tailscale.com/types/logger"
+	"tailscale.com/util/eventbus"
+)
+
+func init() {
+	feature.Register("routehealth")
+	ipnext.RegisterExtension("routehealth", newExtension)
+}
+
+var (
+	targetsKnob  = envknob.RegisterString("TS_ROUTE_HEALTHCHECK")
+	intervalKnob = envknob.RegisterDuration("TS_ROUTE_HEALTHCHECK_INTERVAL")
+)
+
+const (
+	defaultInterval = 5 * time.Second
+	dialTimeout     = 2 * time.Second
+
+	// threshold is how many checks in a row must fail to withdraw the
+	// routes, or succeed to advertise them again.
+	threshold = 3
+)
+
+var routesWithdrawnWarnable = health.Register(&health.Warnable{
+	Code:     "subnet-routes-withdrawn",
+	Title:    "Subnet routes withdrawn",
+	Severity: health.SeverityMedium,
+	Text: func(args health.Args) string {
+		return fmt.Sprintf("This node stopped advertising its subnet routes because its upstream health checks are failing (%v). Another subnet router advertising the same routes can take over.", args[health.ArgError])
+	},
+})
+
+func newExtension(logf logger.Logf, sb ipnext.SafeBackend) (ipnext.Extension, error) {
+	targets, invalid := parseTargets(targetsKnob())
+	for _, t := range invalid {
+		logf("routehealth: ignoring invalid TS_ROUTE_HEALTHCHECK target %q; want host:port", t)
+	}
+	if len(targets) == 0 {
+		return nil, ipnext.SkipExtension
+	}
+	return &Extension{
+		logf:    logger.WithPrefix(logf, "routehealth: "),
+		sb:      sb,
+		clock:   sb.Clock(),
+		health:  sb.Sys().HealthTracker.Get(),
+		targets: targets,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Extension implements the route health extension.
+type Extension struct {
+	logf    logger.Logf
+	sb      ipnext.SafeBackend
+	clock   tstime.Clock
+	health  *health.Tracker
+	targets []string // host:port
+
+	ec        *eventbus.Client
+	pub       *eventbus.Publisher[ipnlocal.SubnetRouteHealth]
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	done      chan struct{} // closed when the check loop exits
+
+	state checkState // owned by checkLoop
+}
+
+func (e *Extension) Name() string { return "routehealth" }
+
+func (e *Extension) Init(h ipnext.Host) error {
+	e.ec = e.sb.Sys().Bus.Get().Client("routehealth")
+	e.pub = eventbus.Publish[ipnlocal.SubnetRouteHealth](e.ec)
+	e.ctx, e.ctxCancel = context.WithCancel(context.Background())
+	go e.checkLoop()
+	return nil
+}
+
+func (e *Extension) Shutdown() error {
+	if e.ctxCancel == nil {
+		return nil
+	}
+	e.ctxCancel()
+	<-e.done
+	e.ec.Close()
+	return nil
+}
+
+func (e *Extension) checkLoop() {
+	defer close(e.done)
+	d := intervalKnob()
+	if d <= 0 {
+		d = defaultInterval
+	}
+	ticker, tickc := e.clock.NewTicker(d)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-tickc:
+		}
+		err := e.check(e.ctx)
+		if e.ctx.Err() != nil {
+			return
+		}
+		if !e.state.observe(err == nil) {
+			continue
+		}
+		withdrawn := e.state.withdrawn
+		if withdrawn {
+			e.logf("upstream checks failing (%v); withdrawing subnet routes", err)
+			e.health.SetUnhealthy(routesWithdrawnWarnable, health.Args{health.ArgError: err.Error()})
+		} else {
+			e.logf("upstream checks healthy again; advertising subnet routes")
+			e.health.SetHealthy(routesWithdrawnWarnable)
+		}
+		e.pub.Publish(ipnlocal.SubnetRouteHealth{Withdraw: withdrawn})
+	}
+}
+
+// check reports whether any of the targets accepts a TCP connection,
+// returning an error describing the failures if none does.
+func (e *Extension) check(ctx context.Context) error {
+	dialer := e.sb.Sys().Dialer.Get()
+	var errs []error
+	for _, t := range e.targets {
+		dctx, cancel := context.WithTimeout(ctx, dialTimeout)
+		c, err := dialer.SystemDial(dctx, "tcp", t)
+		cancel()
+		if err == nil {
+			c.Close()
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// checkState tracks the results of successive checks.
+type checkState struct {
+	withdrawn bool
+	streak    int // checks in a row whose result disagrees with withdrawn
+}
+
+// observe records the result of a check, reporting whether withdrawn
+// changed as a result.
+func (s *checkState) observe(healthy bool) (changed bool) {
+	if healthy != s.withdrawn {
+		s.streak = 0
+		return false
+	}
+	s.streak++
+	if s.streak < threshold {
+		return false
+	}
+	s.withdrawn = !s.withdrawn
+	s.streak = 0
+	return true
+}
+
+// parseTargets parses the comma-separated host:port targets in s,
+// returning those that aren't host:port separately.
+func parseTargets(s string) (targets, invalid []string) {
+	for t := range strings.SplitSeq(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(t); err != nil {
+			invalid = append(invalid, t)
+			continue
+		}
+		targets = append(targets, t)
+	}
+	return targets, invalid
+}
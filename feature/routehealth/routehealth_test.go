@@ -0,0 +1,46 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routehealth
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCheckState(t *testing.T) {
+	var s checkState
+	steps := []struct {
+		healthy       bool
+		wantChanged   bool
+		wantWithdrawn bool
+	}{
+		{true, false, false},
+		{false, false, false},
+		{false, false, false},
+		{true, false, false}, // resets the streak
+		{false, false, false},
+		{false, false, false},
+		{false, true, true}, // third failure in a row
+		{false, false, true},
+		{true, false, true},
+		{true, false, true},
+		{true, true, false}, // third success in a row
+	}
+	for i, st := range steps {
+		changed := s.observe(st.healthy)
+		if changed != st.wantChanged || s.withdrawn != st.wantWithdrawn {
+			t.Fatalf("step %d: observe(%v) = %v, withdrawn %v; want %v, %v", i, st.healthy, changed, s.withdrawn, st.wantChanged, st.wantWithdrawn)
+		}
+	}
+}
+
+func TestParseTargets(t *testing.T) {
+	targets, invalid := parseTargets("10.0.0.1:443, gateway.lan:80,,bogus")
+	if want := []string{"10.0.0.1:443", "gateway.lan:80"}; !slices.Equal(targets, want) {
+		t.Errorf("targets = %q; want %q", targets, want)
+	}
+	if want := []string{"bogus"}; !slices.Equal(invalid, want) {
+		t.Errorf("invalid = %q; want %q", invalid, want)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// dnsSearchDomains, if non-nil, implements DNSSearchDomains.
+var dnsSearchDomains func() []string
+
+// DNSSearchDomains returns the host's configured DNS search domains, in
+// order, or nil if there are none. A misconfigured search domain can make
+// short names resolve to the wrong host instead of through MagicDNS.
+//
+// It uses:
+//
+//   - on Linux, the search (or domain) line of /etc/resolv.conf, which
+//     NetworkManager, systemd-resolved and resolvconf all write when they
+//     manage the file
+//   - on macOS, the search domains of the default resolver ("scutil
+//     --dns")
+//   - on Windows, the SearchList registry value of the Tcpip service, or
+//     else the primary DNS suffix
+//
+// Other platforms report nil.
+func DNSSearchDomains() []string {
+	if dnsSearchDomains == nil {
+		return nil
+	}
+	return dnsSearchDomains()
+}
+
+// parseResolvConfSearch returns the search domains of the resolv.conf
+// contents. As with the C library resolver, the last search or domain line
+// wins.
+func parseResolvConfSearch(contents []byte) []string {
+	var domains []string
+	sc := bufio.NewScanner(bytes.NewReader(contents))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) < 2 {
+			continue
+		}
+		switch f[0] {
+		case "search":
+			domains = f[1:]
+		case "domain":
+			domains = f[1:2]
+		}
+	}
+	return domains
+}
+
+// parseScutilDNSSearch returns the search domains of the default resolver in
+// the output of macOS's "scutil --dns", which begins like:
+//
+//	DNS configuration
+//
+//	resolver #1
+//	  search domain[0] : corp.example.com
+//	  search domain[1] : example.com
+//	  nameserver[0] : 192.168.1.1
+//
+//	resolver #2
+//	  ...
+func parseScutilDNSSearch(out []byte) []string {
+	return scutilDefaultResolverValues(out, "search domain[")
+}
+
+// scutilDefaultResolverValues returns the values of the default resolver's
+// settings whose names start with prefix, in the output of "scutil --dns".
+// See parseScutilDNSSearch.
+func scutilDefaultResolverValues(out []byte, prefix string) []string {
+	var values []string
+	inFirst := false
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "resolver #") {
+			if inFirst {
+				break
+			}
+			inFirst = line == "resolver #1"
+			continue
+		}
+		if !inFirst || !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		if _, v, ok := strings.Cut(line, ":"); ok {
+			if v := strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,61 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseResolvConfSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"none", "nameserver 127.0.0.53\n", nil},
+		{"search", "# Generated by NetworkManager\nsearch corp.example.com example.com\nnameserver 192.168.1.1\n", []string{"corp.example.com", "example.com"}},
+		{"domain", "domain example.com\nnameserver 192.168.1.1\n", []string{"example.com"}},
+		{"last_wins", "search a.example.com\ndomain b.example.com\n", []string{"b.example.com"}},
+		{"resolved_stub", "nameserver 127.0.0.53\noptions edns0 trust-ad\nsearch tail1234.ts.net lan\n", []string{"tail1234.ts.net", "lan"}},
+		{"empty_search", "search\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseResolvConfSearch([]byte(tt.contents)); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScutilDNSSearch(t *testing.T) {
+	const out = `DNS configuration
+
+resolver #1
+  search domain[0] : corp.example.com
+  search domain[1] : example.com
+  nameserver[0] : 192.168.1.1
+  if_index : 15 (en0)
+  flags    : Request A records
+  reach    : 0x00020002 (Reachable,Directly Reachable Address)
+
+resolver #2
+  domain   : local
+  search domain[0] : other.example
+  options  : mdns
+
+DNS configuration (for scoped queries)
+
+resolver #1
+  search domain[0] : scoped.example
+`
+	want := []string{"corp.example.com", "example.com"}
+	if got := parseScutilDNSSearch([]byte(out)); !slices.Equal(got, want) {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if got := parseScutilDNSSearch([]byte("DNS configuration\n\nresolver #1\n  nameserver[0] : 192.168.1.1\n")); got != nil {
+		t.Errorf("got %q; want nil", got)
+	}
+}
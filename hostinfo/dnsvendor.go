@@ -0,0 +1,72 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bufio"
+	"bytes"
+	"net/netip"
+	"strings"
+)
+
+// dnsResolverAddr, if non-nil, returns the address of the host's primary
+// configured DNS resolver, or the zero value if there's none.
+var dnsResolverAddr func() netip.Addr
+
+// DNSResolverAddr returns the address of the host's primary configured DNS
+// resolver, or the zero value if there's none or it can't be determined.
+//
+// The primary resolver is the first nameserver in /etc/resolv.conf on
+// Linux, the first of the default resolver on macOS ("scutil --dns"), and
+// the first DNS server of the primary network adapter on Windows. Other
+// platforms report the zero value.
+func DNSResolverAddr() netip.Addr {
+	if dnsResolverAddr == nil {
+		return netip.Addr{}
+	}
+	return dnsResolverAddr()
+}
+
+// DNSResolverVendor returns the name of the software of the host's primary
+// configured DNS resolver (see [DNSResolverAddr]) if it can be told from
+// the resolver's address alone: "systemd-resolved" for its stub resolver,
+// 127.0.0.53, and "tailscale" for Tailscale's own, 100.100.100.100 or
+// fd7a:115c:a1e0::53. Otherwise it returns the empty string.
+//
+// It sends no traffic. [tailscale.com/net/preflight.DNSResolverVendor]
+// also asks the resolver itself, identifying more kinds of resolver.
+func DNSResolverVendor() string {
+	switch addr := DNSResolverAddr(); addr {
+	case netip.AddrFrom4([4]byte{127, 0, 0, 53}):
+		return "systemd-resolved"
+	case tailscaleServiceIP, tailscaleServiceIPv6:
+		return "tailscale"
+	}
+	return ""
+}
+
+// tailscaleServiceIP and tailscaleServiceIPv6 are Tailscale's MagicDNS
+// resolver addresses, as returned by tsaddr.TailscaleServiceIP and
+// tsaddr.TailscaleServiceIPv6, which hostinfo doesn't import to keep its
+// dependencies small.
+var (
+	tailscaleServiceIP   = netip.MustParseAddr("100.100.100.100")
+	tailscaleServiceIPv6 = netip.MustParseAddr("fd7a:115c:a1e0::53")
+)
+
+// parseResolvConfNameserver returns the first nameserver of the resolv.conf
+// contents, or the zero value if there's none.
+func parseResolvConfNameserver(contents []byte) netip.Addr {
+	sc := bufio.NewScanner(bytes.NewReader(contents))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) < 2 || f[0] != "nameserver" {
+			continue
+		}
+		if ip, err := netip.ParseAddr(f[1]); err == nil {
+			return ip.WithZone("")
+		}
+	}
+	return netip.Addr{}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestParseResolvConfNameserver(t *testing.T) {
+	const conf = `# Generated by NetworkManager
+search example.com
+nameserver 192.168.1.1
+nameserver 8.8.8.8
+`
+	if got, want := parseResolvConfNameserver([]byte(conf)), netip.MustParseAddr("192.168.1.1"); got != want {
+		t.Errorf("parseResolvConfNameserver = %v; want %v", got, want)
+	}
+	if got := parseResolvConfNameserver([]byte("search example.com\n")); got.IsValid() {
+		t.Errorf("parseResolvConfNameserver without nameservers = %v; want zero", got)
+	}
+}
+
+func TestDNSResolverVendor(t *testing.T) {
+	old := dnsResolverAddr
+	t.Cleanup(func() { dnsResolverAddr = old })
+
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"127.0.0.53", "systemd-resolved"},
+		{"100.100.100.100", "tailscale"},
+		{"fd7a:115c:a1e0::53", "tailscale"},
+		{"192.168.1.1", ""},
+	}
+	for _, tt := range tests {
+		dnsResolverAddr = func() netip.Addr { return netip.MustParseAddr(tt.addr) }
+		if got := DNSResolverVendor(); got != tt.want {
+			t.Errorf("DNSResolverVendor with resolver %s = %q; want %q", tt.addr, got, tt.want)
+		}
+	}
+	dnsResolverAddr = func() netip.Addr { return netip.Addr{} }
+	if got := DNSResolverVendor(); got != "" {
+		t.Errorf("DNSResolverVendor without a resolver = %q; want empty", got)
+	}
+}
@@ -0,0 +1,1480 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"tailscale.com/types/lazy"
+	"tailscale.com/types/opt"
+	"tailscale.com/util/cloudenv"
+	"tailscale.com/version/distro"
+)
+
+// EnvironmentFacts is a snapshot of properties of the host environment that
+// are useful when diagnosing Tailscale problems, such as in bug reports.
+//
+// Unlike [tailcfg.Hostinfo], it's not sent to the control plane. Fields are
+// best effort; the zero value of a field generally means unknown or not
+// applicable on this platform.
+type EnvironmentFacts struct {
+	// HasIOUring is whether the kernel supports io_uring and this process
+	// is permitted to use it. Finding out takes a system call that hostinfo
+	// doesn't make, so GetEnvironmentFacts leaves it false and
+	// [tailscale.com/util/osdiag.EnvironmentFacts] fills it in. See
+	// [tailscale.com/util/osdiag.HasIOUring].
+	HasIOUring bool `json:",omitempty"`
+
+	// DefaultInterfaceMTU is the MTU of the interface carrying the default
+	// route, or zero if unknown. hostinfo can't find the default route on
+	// every platform, so GetEnvironmentFacts leaves it zero and
+	// [tailscale.com/util/osdiag.EnvironmentFacts] fills it in. See
+	// [tailscale.com/util/osdiag.DefaultInterfaceMTU].
+	DefaultInterfaceMTU int `json:",omitempty"`
+
+	// CodeSignatureValid is whether the running executable carries a valid
+	// code signature, if that could be checked. Checking needs packages
+	// hostinfo doesn't otherwise depend on, so GetEnvironmentFacts leaves it
+	// unset and [tailscale.com/util/osdiag.EnvironmentFacts] fills it in.
+	// See [tailscale.com/util/osdiag.CodeSignatureValid].
+	CodeSignatureValid opt.Bool `json:",omitempty"`
+
+	// Antivirus are the names of the enabled antivirus products on the host.
+	// Finding them takes Windows Security Center calls that hostinfo doesn't
+	// make, so GetEnvironmentFacts leaves it empty and
+	// [tailscale.com/util/osdiag.EnvironmentFacts] fills it in. See
+	// [tailscale.com/util/osdiag.AntivirusDetected].
+	Antivirus []string `json:",omitempty"`
+
+	// GOMAXPROCS is the current value of GOMAXPROCS.
+	GOMAXPROCS int
+
+	// RecommendedGOMAXPROCS is the GOMAXPROCS value that matches the
+	// process's cgroup CPU quota, or zero if there's no quota. See
+	// [RecommendedGOMAXPROCS].
+	RecommendedGOMAXPROCS int `json:",omitempty"`
+
+	// GOMAXPROCSExceedsQuota is whether GOMAXPROCS is larger than
+	// RecommendedGOMAXPROCS, which causes CPU throttling.
+	GOMAXPROCSExceedsQuota bool `json:",omitempty"`
+
+	// HostFirewallBlocking is whether the host firewall likely blocks
+	// inbound Tailscale traffic, and HostFirewallReason describes why or
+	// why it couldn't be determined. See [HostFirewallLikelyBlocking].
+	HostFirewallBlocking bool   `json:",omitempty"`
+	HostFirewallReason   string `json:",omitempty"`
+
+	// MultiWAN is whether the host has default routes via multiple
+	// gateways on different interfaces. See [MultiWAN].
+	MultiWAN bool `json:",omitempty"`
+
+	// StableHostKeyAvailable is whether the host has a stable hardware or
+	// OS identifier to derive stable state from. See
+	// [StableHostKeyAvailable].
+	StableHostKeyAvailable bool `json:",omitempty"`
+
+	// MaxSocketReadBuffer and MaxSocketWriteBuffer are the largest socket
+	// receive and send buffer sizes, in bytes, that the kernel allows an
+	// unprivileged process to request, or zero if unknown. See
+	// [MaxSocketBufferBytes].
+	MaxSocketReadBuffer  int `json:",omitempty"`
+	MaxSocketWriteBuffer int `json:",omitempty"`
+
+	// RestrictedEnvironment is whether the host appears to be a locked-down
+	// appliance or restricted shell. See [IsRestrictedEnvironment].
+	RestrictedEnvironment bool `json:",omitempty"`
+
+	// IPv6PrivacyExtensions is whether IPv6 temporary addresses (RFC 8981)
+	// are enabled, if known. See [IPv6PrivacyExtensions].
+	IPv6PrivacyExtensions opt.Bool `json:",omitempty"`
+
+	// IPv4Forwarding and IPv6Forwarding are whether the kernel forwards
+	// IPv4 and IPv6 packets, as subnet routers and exit nodes require.
+	// See [IPForwardingEnabled].
+	IPv4Forwarding bool `json:",omitempty"`
+	IPv6Forwarding bool `json:",omitempty"`
+
+	// StrictRPFilterInterface is the name of an interface with strict
+	// reverse path filtering, if any. See [ReversePathFilterStrict].
+	StrictRPFilterInterface string `json:",omitempty"`
+
+	// NetworkManagerDNS is whether NetworkManager manages the system's
+	// DNS configuration. See [NetworkManagerActive].
+	NetworkManagerDNS bool `json:",omitempty"`
+
+	// SystemdResolved is whether systemd-resolved is running. See
+	// [SystemdResolvedActive].
+	SystemdResolved bool `json:",omitempty"`
+
+	// SplitDNS is whether the OS's DNS configuration backend can route
+	// queries per domain, and SplitDNSReason explains why not when it
+	// can't. See [SplitDNSFeasible].
+	SplitDNS       bool   `json:",omitempty"`
+	SplitDNSReason string `json:",omitempty"`
+
+	// DesktopSandbox is the desktop application sandbox the process runs
+	// in ("snap" or "flatpak"), if any. See [DesktopSandbox].
+	DesktopSandbox string `json:",omitempty"`
+
+	// OutboundProxy is the HTTP proxy used for outbound connections, if
+	// any, with any password redacted. See [OutboundProxy].
+	OutboundProxy string `json:",omitempty"`
+
+	// PACURL is the URL of the proxy auto-config file the host is
+	// configured with, if any. See [PACProxyConfigured].
+	PACURL string `json:",omitempty"`
+
+	// CrashDumpDir is where the OS would store a crash dump of this
+	// process, if known. See [CrashDumpDir].
+	CrashDumpDir string `json:",omitempty"`
+
+	// CoreDumpsEnabled is whether a crash of this process would produce
+	// a core dump, if known. See [CoreDumpsEnabled].
+	CoreDumpsEnabled opt.Bool `json:",omitempty"`
+
+	// NTPSynced is whether the clock is synchronized by a network time
+	// service, if known. See [NTPSynced].
+	NTPSynced opt.Bool `json:",omitempty"`
+
+	// ThermalThrottled is whether the CPU is currently throttled for
+	// thermal or power reasons, if known. See [ThermalThrottled].
+	ThermalThrottled opt.Bool `json:",omitempty"`
+
+	// CPUAffinity is the set of CPUs the process may run on, in the Linux
+	// cpuset list format (such as "0-3,6"), if known. See [CPUAffinity].
+	CPUAffinity string `json:",omitempty"`
+
+	// MinimalBootMode is whether the OS was booted into a recovery,
+	// single-user or safe mode. See [MinimalBootMode].
+	MinimalBootMode bool `json:",omitempty"`
+
+	// CryptoOffloadNIC is whether the default route's network interface
+	// has hardware crypto offload enabled. See [HasCryptoOffloadNIC].
+	CryptoOffloadNIC bool `json:",omitempty"`
+
+	// SwapEnabled is whether swap space is configured, if known, and
+	// SwapBytes is its total size. See [SwapInfo].
+	SwapEnabled opt.Bool `json:",omitempty"`
+	SwapBytes   uint64   `json:",omitempty"`
+
+	// Umask is the process's file mode creation mask, in octal, if
+	// known. See [CurrentUmask].
+	Umask string `json:",omitempty"`
+
+	// ConflictingVPNs are the other VPN products found on the host. See
+	// [ConflictingVPNs].
+	ConflictingVPNs []string `json:",omitempty"`
+
+	// MaxRoutes is the kernel's limit on the number of routes, or zero if
+	// unknown. See [MaxRoutes].
+	MaxRoutes int `json:",omitempty"`
+
+	// SharedHosting is whether the node appears to run on multi-tenant
+	// hosting. See [IsSharedHosting].
+	SharedHosting bool `json:",omitempty"`
+
+	// EntropyAvailable is the kernel's estimate of available entropy in
+	// bits, or zero if unknown. See [EntropyAvailable].
+	EntropyAvailable int `json:",omitempty"`
+
+	// ThreadLimit is the maximum number of threads the process can have,
+	// or zero if unknown or unlimited. See [ThreadLimit].
+	ThreadLimit uint64 `json:",omitempty"`
+
+	// LoopbackBroken is whether the loopback interface doesn't work. See
+	// [LoopbackHealthy].
+	LoopbackBroken bool `json:",omitempty"`
+
+	// NoTZData is whether the time zone database is missing. See
+	// [HasTZData].
+	NoTZData bool `json:",omitempty"`
+
+	// NoUrandom is whether /dev/urandom is missing or unreadable. See
+	// [HasUrandom].
+	NoUrandom bool `json:",omitempty"`
+
+	// HostnameAddr is the address the host's own hostname resolves to, or
+	// empty if it doesn't resolve. See [HostnameResolvesLocally].
+	HostnameAddr string `json:",omitempty"`
+
+	// LocalAPIUnreachable is why the LocalAPI can't be connected to, or
+	// empty if it can. Connecting can take seconds, so GetEnvironmentFacts
+	// leaves it empty and the bugreport handler fills it in. See
+	// [tailscale.com/net/preflight.LocalAPIReachable].
+	LocalAPIUnreachable string `json:",omitempty"`
+
+	// IPv6Disabled is whether IPv6 is administratively disabled, and
+	// IPv6DisabledReason how. See [IPv6Disabled].
+	IPv6Disabled       bool   `json:",omitempty"`
+	IPv6DisabledReason string `json:",omitempty"`
+
+	// AddressingMode is how the primary interface is addressed, unless
+	// "unknown". See [AddressingMode].
+	AddressingMode string `json:",omitempty"`
+
+	// RawSocketSupport is whether the process can open raw AF_PACKET
+	// sockets. See [HasRawSocketSupport].
+	RawSocketSupport bool `json:",omitempty"`
+
+	// SeccompMode is the process's seccomp mode, "disabled", "strict" or
+	// "filter", or empty if unknown. See [SeccompActive].
+	SeccompMode string `json:",omitempty"`
+
+	// EBPFSockops is whether the process can load eBPF socket operations
+	// programs. Finding out takes a system call that hostinfo doesn't make,
+	// so GetEnvironmentFacts leaves it false and
+	// [tailscale.com/util/osdiag.EnvironmentFacts] fills it in. See
+	// [tailscale.com/util/osdiag.HasEBPFSockops].
+	EBPFSockops bool `json:",omitempty"`
+
+	// CGNATConflicts are the host routes that overlap Tailscale's CGNAT
+	// range. See [CGNATRangeConflict].
+	CGNATConflicts []string `json:",omitempty"`
+
+	// HasBrowser is whether a web browser can be launched for interactive
+	// login. See [HasBrowser].
+	HasBrowser bool `json:",omitempty"`
+
+	// HasClipboard is whether text can be copied to the clipboard. See
+	// [HasClipboard].
+	HasClipboard bool `json:",omitempty"`
+
+	// HostsFileConflicts are the hosts file entries that can interfere
+	// with Tailscale. See [HostsFileConflicts].
+	HostsFileConflicts []string `json:",omitempty"`
+
+	// DNSSearchDomains are the host's DNS search domains. See
+	// [DNSSearchDomains].
+	DNSSearchDomains []string `json:",omitempty"`
+
+	// AggressiveNegativeCaching is whether the host's resolver caches
+	// negative answers long enough to delay new names resolving, and
+	// AggressiveNegativeCachingReason which setting does. See
+	// [AggressiveNegativeCaching].
+	AggressiveNegativeCaching       bool   `json:",omitempty"`
+	AggressiveNegativeCachingReason string `json:",omitempty"`
+
+	// DNSResolverVendor is the software of the host's primary DNS
+	// resolver, if it could be identified from its address. See
+	// [DNSResolverVendor].
+	DNSResolverVendor string `json:",omitempty"`
+
+	// AsymmetricRouting describes how the host's routing looks asymmetric,
+	// if it does. See [AsymmetricRouting].
+	AsymmetricRouting string `json:",omitempty"`
+
+	// PolicyRoutingRules are the host's policy routing rules beyond the
+	// defaults and Tailscale's own. See [PolicyRoutingActive].
+	PolicyRoutingRules []string `json:",omitempty"`
+}
+
+// GetEnvironmentFacts returns a snapshot of facts about the host environment.
+func GetEnvironmentFacts() EnvironmentFacts {
+	f := EnvironmentFacts{
+		GOMAXPROCS: EffectiveGOMAXPROCS(),
+		MultiWAN:   MultiWAN(),
+
+		StableHostKeyAvailable: StableHostKeyAvailable(),
+		RestrictedEnvironment:  IsRestrictedEnvironment(),
+		NetworkManagerDNS:      NetworkManagerActive(),
+		SystemdResolved:        SystemdResolvedActive(),
+		DesktopSandbox:         DesktopSandbox(),
+		OutboundProxy:          OutboundProxy(),
+		CrashDumpDir:           CrashDumpDir(),
+		MinimalBootMode:        MinimalBootMode(),
+		CryptoOffloadNIC:       HasCryptoOffloadNIC(),
+		ConflictingVPNs:        ConflictingVPNs(),
+		SharedHosting:          IsSharedHosting(),
+		LoopbackBroken:         !LoopbackHealthy(),
+		NoTZData:               !HasTZData(),
+		NoUrandom:              !HasUrandom(),
+		RawSocketSupport:       HasRawSocketSupport(),
+		HasBrowser:             HasBrowser(),
+		HasClipboard:           HasClipboard(),
+		DNSSearchDomains:       DNSSearchDomains(),
+		DNSResolverVendor:      DNSResolverVendor(),
+		HostsFileConflicts:     HostsFileConflicts(),
+	}
+	_, f.PACURL = PACProxyConfigured()
+	f.HostFirewallBlocking, f.HostFirewallReason = HostFirewallLikelyBlocking()
+	f.MaxSocketReadBuffer, f.MaxSocketWriteBuffer, _ = MaxSocketBufferBytes()
+	if cpus, ok := CPUAffinity(); ok {
+		f.CPUAffinity = formatCPUList(cpus)
+	}
+	f.IPv4Forwarding, f.IPv6Forwarding = IPForwardingEnabled()
+	f.MaxRoutes, _ = MaxRoutes()
+	f.EntropyAvailable, _ = EntropyAvailable()
+	f.ThreadLimit, _ = ThreadLimit()
+	_, f.HostnameAddr = HostnameResolvesLocally()
+	f.IPv6Disabled, f.IPv6DisabledReason = IPv6Disabled()
+	_, f.SeccompMode = SeccompActive()
+	_, f.CGNATConflicts = CGNATRangeConflict()
+	f.AggressiveNegativeCaching, f.AggressiveNegativeCachingReason = AggressiveNegativeCaching()
+	_, f.AsymmetricRouting = AsymmetricRouting()
+	_, f.PolicyRoutingRules = PolicyRoutingActive()
+	if m := AddressingMode(); m != "unknown" {
+		f.AddressingMode = m
+	}
+	if v, ok := CoreDumpsEnabled(); ok {
+		f.CoreDumpsEnabled.Set(v)
+	}
+	if mask, ok := CurrentUmask(); ok {
+		f.Umask = fmt.Sprintf("%04o", mask)
+	}
+	if total, enabled, ok := SwapInfo(); ok {
+		f.SwapEnabled.Set(enabled)
+		f.SwapBytes = total
+	}
+	if v, ok := NTPSynced(); ok {
+		f.NTPSynced.Set(v)
+	}
+	if v, ok := ThermalThrottled(); ok {
+		f.ThermalThrottled.Set(v)
+	}
+	_, f.StrictRPFilterInterface = ReversePathFilterStrict()
+	f.SplitDNS, f.SplitDNSReason = SplitDNSFeasible()
+	if v, ok := IPv6PrivacyExtensions(); ok {
+		f.IPv6PrivacyExtensions.Set(v)
+	}
+	if n, ok := RecommendedGOMAXPROCS(); ok {
+		f.RecommendedGOMAXPROCS = n
+		f.GOMAXPROCSExceedsQuota = f.GOMAXPROCS > n
+	}
+	return f
+}
+
+// non-nil on some platforms
+var (
+	defaultRouteInterface func() string
+	cgroupCPUQuota        func() (cpus float64, ok bool)
+	hostFirewallBlocking  func() (bool, string)
+	multiWAN              func() bool
+	hasStableMachineID    func() bool
+	maxSocketBuffers      func() (rmem, wmem int, ok bool)
+	rootReadOnly          func() bool
+	ipv6TempAddrs         func() (enabled, ok bool)
+	networkSysctls        func() map[string]string
+	ipForwarding          func() (v4, v6 bool)
+	strictRPFilter        func() (iface string)
+	resolvConfOwner       func() string
+	systemdResolved       func() bool
+	desktopSandbox        func() string
+	interfaceType         func(name string) string
+	crashDumpDir          func() string
+	coreDumpsEnabled      func() (enabled, ok bool)
+	thermalThrottled      func() (throttled, ok bool)
+	cpuAffinity           func() ([]int, bool)
+	minimalBootMode       func() bool
+	nicCryptoOffload      func(iface string) bool
+	swapTotal             func() (bytes uint64, ok bool)
+	currentUmask          func() (int, bool)
+	maxRoutes             func() (int, bool)
+	vmVendor              func() (vendor string, isVM bool)
+	entropyAvailable      func() (int, bool)
+	ipv6Disabled          func() (disabled bool, reason string)
+	addressingMode        func() string
+	hasRawSocket          func() bool
+	seccompMode           func() string
+	cgnatRoutes           func() []string
+	hasBrowser            func() bool
+	hasClipboard          func() bool
+	negativeCaching       func() (aggressive bool, reason string)
+	hasUrandom            func() bool
+	threadLimit           func() (uint64, bool)
+	asymmetricRouting     func() (bool, string)
+	policyRoutingRules    func() []string
+)
+
+// EffectiveGOMAXPROCS returns the current GOMAXPROCS setting, which bounds how
+// many CPUs the Go runtime will use at once.
+//
+// Compare against [RecommendedGOMAXPROCS] to find out whether it exceeds the
+// container's CPU quota.
+func EffectiveGOMAXPROCS() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// RecommendedGOMAXPROCS returns the GOMAXPROCS value that matches the CPU
+// quota of the process's cgroup, and whether there is such a quota.
+//
+// The quota is read from cpu.max (cgroup v2) or cpu.cfs_quota_us and
+// cpu.cfs_period_us (cgroup v1), and the recommendation is quota divided by
+// period, rounded up, and capped at the number of CPUs. A GOMAXPROCS larger
+// than that lets the process burn through its quota early in each period and
+// then get throttled, a common cause of latency spikes in Kubernetes pods.
+// Recent Go releases take the quota into account when picking the default,
+// but an explicit $GOMAXPROCS or older toolchain does not.
+//
+// It reports false on platforms other than Linux or when there's no quota.
+func RecommendedGOMAXPROCS() (n int, ok bool) {
+	if cgroupCPUQuota == nil {
+		return 0, false
+	}
+	cpus, ok := cgroupCPUQuota()
+	if !ok {
+		return 0, false
+	}
+	return cpusToGOMAXPROCS(cpus), true
+}
+
+func cpusToGOMAXPROCS(cpus float64) int {
+	n := min(int(math.Ceil(cpus)), runtime.NumCPU())
+	return max(n, 1)
+}
+
+// defaultWireGuardPort is tailscaled's default UDP port, which is what
+// [HostFirewallLikelyBlocking] checks for.
+const defaultWireGuardPort = 41641
+
+// HostFirewallLikelyBlocking reports whether the host's firewall is likely to
+// block inbound Tailscale traffic (UDP port 41641, the default), along with a
+// human-readable reason.
+//
+// It's a heuristic for support triage, not an analysis of the ruleset.
+// Tailscale usually still works through a blocking firewall by relaying or
+// by NAT traversal initiated from this side; inbound blocking mostly costs
+// direct connections. It inspects:
+//
+//   - on Linux, ufw's configuration files and firewalld (via firewall-cmd);
+//     other nftables or iptables rulesets are not inspected
+//   - on Windows, whether Windows Defender Firewall is set to block all
+//     incoming connections, including those of allowed apps
+//   - on macOS, whether the application firewall is set to block all
+//     incoming connections
+//
+// If it can't inspect the firewall, it reports false and a reason beginning
+// with "undetermined".
+func HostFirewallLikelyBlocking() (blocking bool, reason string) {
+	if hostFirewallBlocking == nil {
+		return false, "undetermined: firewall inspection not supported on " + runtime.GOOS
+	}
+	return hostFirewallBlocking()
+}
+
+var multiWANCache lazy.SyncValue[bool]
+
+// MultiWAN reports whether the host has multiple default routes through
+// different gateways on different interfaces, as in multi-WAN or
+// wired-plus-Wi-Fi setups, which can cause asymmetric routing and unstable
+// connections. The result is cached.
+//
+// On Linux, only the main routing table (/proc/net/route) is inspected, so
+// default routes in other policy routing tables aren't counted. It always
+// reports false on other platforms.
+func MultiWAN() bool {
+	if multiWAN == nil {
+		return false
+	}
+	return multiWANCache.Get(multiWAN)
+}
+
+var stableHostKeyCache lazy.SyncValue[bool]
+
+// StableHostKeyAvailable reports whether the host has a stable identifier
+// that survives reinstalling Tailscale, from which stable state could be
+// derived. The sources checked are:
+//
+//   - on Linux, a systemd or D-Bus machine-id
+//   - on macOS, the IOPlatformUUID hardware UUID
+//   - on Windows, the MachineGuid generated at OS installation
+//   - on all platforms, a globally administered (burned-in, rather than
+//     randomized or locally assigned) MAC address on a physical interface
+//
+// Only the availability is reported. The identifiers themselves are
+// sensitive, as they can be used to track the machine across networks and
+// reinstalls, so they are neither returned nor retained. The result is
+// cached.
+func StableHostKeyAvailable() bool {
+	return stableHostKeyCache.Get(func() bool {
+		if hasStableMachineID != nil && hasStableMachineID() {
+			return true
+		}
+		return hasGloballyAdministeredMAC()
+	})
+}
+
+func hasGloballyAdministeredMAC() bool {
+	ifs, err := net.Interfaces()
+	if err != nil {
+		return false
+	}
+	for _, ifc := range ifs {
+		if ifc.Flags&net.FlagLoopback != 0 || len(ifc.HardwareAddr) != 6 {
+			continue
+		}
+		if isGloballyAdministeredMAC(ifc.HardwareAddr) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGloballyAdministeredMAC reports whether mac is a non-zero unicast MAC
+// address with the "locally administered" bit clear, meaning it was assigned
+// by the manufacturer rather than randomized or made up by software.
+func isGloballyAdministeredMAC(mac net.HardwareAddr) bool {
+	if len(mac) == 0 || mac[0]&0x03 != 0 {
+		return false // multicast or locally administered
+	}
+	for _, b := range mac {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxSocketBufferBytes returns the largest socket receive (rmem) and send
+// (wmem) buffer sizes, in bytes, that an unprivileged process can request
+// with SO_RCVBUF and SO_SNDBUF, and whether they could be determined.
+//
+// Small limits are a common cause of poor UDP throughput, as magicsock's
+// requested buffer sizes get silently clamped. On Linux, the values come
+// from the net.core.rmem_max and net.core.wmem_max sysctls (as read from
+// /proc/sys/net/core). Other platforms report ok=false.
+func MaxSocketBufferBytes() (rmem, wmem int, ok bool) {
+	if maxSocketBuffers == nil {
+		return 0, 0, false
+	}
+	return maxSocketBuffers()
+}
+
+var restrictedEnvCache lazy.SyncValue[bool]
+
+// IsRestrictedEnvironment reports whether the process appears to be running
+// in a locked-down appliance or kiosk environment, where the user can't be
+// expected to run arbitrary commands or edit system configuration. Callers
+// such as the CLI can use it to avoid suggesting remedies that aren't
+// possible there.
+//
+// It's a heuristic and defaults to false. It reports true if any of these
+// hold:
+//
+//   - $SHELL is a restricted shell (rbash, rksh, rzsh or lshell)
+//   - the Linux distro is a single-purpose appliance image without a
+//     general-purpose userland (gokrazy, JetKVM)
+//   - on Linux, the process is in a container whose root filesystem is
+//     mounted read-only
+//
+// The result is cached.
+func IsRestrictedEnvironment() bool {
+	return restrictedEnvCache.Get(func() bool {
+		if isRestrictedShell(os.Getenv("SHELL")) {
+			return true
+		}
+		switch distro.Get() {
+		case distro.Gokrazy, distro.JetKVM:
+			return true
+		}
+		return rootReadOnly != nil && inContainer().EqualBool(true) && rootReadOnly()
+	})
+}
+
+// isRestrictedShell reports whether shell, the path to a shell as in $SHELL,
+// is a restricted shell.
+func isRestrictedShell(shell string) bool {
+	switch filepath.Base(shell) {
+	case "rbash", "rksh", "rzsh", "lshell":
+		return true
+	}
+	return false
+}
+
+var ipv6TempAddrsCache lazy.SyncValue[opt.Bool]
+
+// IPv6PrivacyExtensions reports whether the host generates temporary,
+// randomized IPv6 addresses (RFC 8981 privacy extensions), and whether that
+// could be determined. Temporary addresses are rotated periodically, which
+// explains a node's IPv6 endpoints changing over time. It's sourced from:
+//
+//   - on Linux, the net.ipv6.conf.*.use_tempaddr sysctls; enabled if any
+//     non-loopback interface (or the default for new ones) has a positive
+//     value
+//   - on Windows, "netsh interface ipv6 show privacy"
+//   - on macOS, the net.inet6.ip6.use_tempaddr sysctl
+//
+// Other platforms report ok=false. The result is cached.
+func IPv6PrivacyExtensions() (enabled, ok bool) {
+	if ipv6TempAddrs == nil {
+		return false, false
+	}
+	v := ipv6TempAddrsCache.Get(func() opt.Bool {
+		var b opt.Bool
+		if enabled, ok := ipv6TempAddrs(); ok {
+			b.Set(enabled)
+		}
+		return b
+	})
+	return v.Get()
+}
+
+// NetworkSysctls returns a snapshot of the kernel networking settings that
+// most often affect Tailscale, for inclusion in support bundles. It maps
+// sysctl names to their current values.
+//
+// On Linux, it reports whichever of these exist on the running kernel:
+//
+//   - net.ipv4.ip_forward and net.ipv6.conf.all.forwarding, needed by subnet
+//     routers and exit nodes
+//   - net.ipv4.conf.{all,default}.rp_filter and
+//     net.ipv4.conf.all.src_valid_mark, as strict reverse path filtering
+//     drops Tailscale's policy-routed packets
+//   - net.core.{rmem,wmem}_{default,max} and net.core.netdev_max_backlog,
+//     which bound UDP throughput
+//   - net.ipv6.conf.all.disable_ipv6 and
+//     net.ipv6.conf.{all,default}.use_tempaddr
+//
+// It returns an empty map on other platforms.
+func NetworkSysctls() map[string]string {
+	if networkSysctls == nil {
+		return map[string]string{}
+	}
+	return networkSysctls()
+}
+
+// networkSysctlNames are the Linux sysctls reported by [NetworkSysctls].
+var networkSysctlNames = []string{
+	"net.ipv4.ip_forward",
+	"net.ipv6.conf.all.forwarding",
+	"net.ipv4.conf.all.rp_filter",
+	"net.ipv4.conf.default.rp_filter",
+	"net.ipv4.conf.all.src_valid_mark",
+	"net.core.rmem_default",
+	"net.core.rmem_max",
+	"net.core.wmem_default",
+	"net.core.wmem_max",
+	"net.core.netdev_max_backlog",
+	"net.ipv6.conf.all.disable_ipv6",
+	"net.ipv6.conf.all.use_tempaddr",
+	"net.ipv6.conf.default.use_tempaddr",
+}
+
+// IPForwardingEnabled reports whether the kernel is configured to forward
+// IPv4 and IPv6 packets between interfaces, which subnet routers and exit
+// nodes using the kernel's network stack require. It reads:
+//
+//   - on Linux, the net.ipv4.ip_forward and net.ipv6.conf.all.forwarding
+//     sysctls (see [tailscale.com/net/netutil.CheckIPForwarding] for the
+//     per-interface nuances)
+//   - on macOS and FreeBSD, the net.inet.ip.forwarding and
+//     net.inet6.ip6.forwarding sysctls
+//
+// It reports false for a family whose setting can't be read, and always
+// reports false on other platforms.
+func IPForwardingEnabled() (v4, v6 bool) {
+	if ipForwarding == nil {
+		return false, false
+	}
+	return ipForwarding()
+}
+
+// ReversePathFilterStrict reports whether strict reverse path filtering is
+// in effect on any network interface, and if so, the name of one such
+// interface. Strict filtering drops packets arriving on an interface other
+// than the one the reply would be routed out of, which breaks subnet routers
+// and exit nodes, as well as traffic steered by Tailscale's policy routing.
+//
+// On Linux, the effective rp_filter mode of an interface is the larger of
+// net.ipv4.conf.all.rp_filter and net.ipv4.conf.$IFACE.rp_filter, where 1
+// is strict and 2 is loose. Every interface other than loopback is
+// inspected, in name order. If strict filtering comes from the "all"
+// setting, the returned name is "all".
+//
+// It returns (false, "") on other platforms.
+func ReversePathFilterStrict() (strict bool, iface string) {
+	if strictRPFilter == nil {
+		return false, ""
+	}
+	iface = strictRPFilter()
+	return iface != "", iface
+}
+
+var networkManagerCache lazy.SyncValue[bool]
+
+// NetworkManagerActive reports whether NetworkManager manages the host's DNS
+// configuration, which changes how Tailscale has to configure DNS.
+//
+// On Linux, that's the case if /etc/resolv.conf is a symlink into
+// /run/NetworkManager, or if its header comment says it was generated by
+// NetworkManager. NetworkManager running without owning resolv.conf (for
+// instance, when it delegates to systemd-resolved) doesn't count. The
+// result is cached. It always reports false on other platforms.
+func NetworkManagerActive() bool {
+	if resolvConfOwner == nil {
+		return false
+	}
+	return networkManagerCache.Get(func() bool {
+		return resolvConfOwner() == "NetworkManager"
+	})
+}
+
+var systemdResolvedCache lazy.SyncValue[bool]
+
+// SystemdResolvedActive reports whether systemd-resolved is running on the
+// host. Together with [NetworkManagerActive], it determines how Tailscale
+// configures DNS there.
+//
+// On Linux, systemd-resolved is considered active if its stub resolver file,
+// /run/systemd/resolve/stub-resolv.conf, exists and "systemctl is-active
+// systemd-resolved.service" succeeds (or systemctl isn't available to ask).
+// The file lives on a tmpfs and is created by systemd-resolved at startup,
+// so its presence alone can be stale only until the next reboot. Note that
+// systemd-resolved may be running without managing /etc/resolv.conf. The
+// result is cached. It always reports false on other platforms.
+func SystemdResolvedActive() bool {
+	if systemdResolved == nil {
+		return false
+	}
+	return systemdResolvedCache.Get(systemdResolved)
+}
+
+// SplitDNSFeasible reports whether the host's DNS configuration backend can
+// route DNS queries per domain (split DNS), as needed to resolve MagicDNS
+// and split DNS domains through Tailscale while leaving other queries to the
+// existing resolvers. If not, it returns a reason. In that case Tailscale
+// falls back to sending all of the host's DNS queries to its own resolver
+// (100.100.100.100) when it's asked to configure DNS.
+//
+// The backends are:
+//
+//   - Windows: the Name Resolution Policy Table; supported
+//   - macOS and iOS: /etc/resolver and the Network Extension; supported
+//   - Linux with systemd-resolved managing /etc/resolv.conf: supported
+//   - Linux with NetworkManager managing /etc/resolv.conf: not supported,
+//     as NetworkManager only does split DNS when it delegates to dnsmasq or
+//     systemd-resolved (which then owns resolv.conf)
+//   - Linux with resolvconf, or with no manager (Tailscale rewrites
+//     /etc/resolv.conf directly), and the BSDs: not supported
+//
+// On other platforms, it reports false with a reason saying that support is
+// unknown.
+func SplitDNSFeasible() (feasible bool, reason string) {
+	owner := ""
+	if resolvConfOwner != nil {
+		owner = resolvConfOwner()
+	}
+	return splitDNSFeasibility(runtime.GOOS, owner)
+}
+
+// splitDNSFeasibility implements [SplitDNSFeasible] for the given GOOS and
+// resolv.conf owner ("systemd-resolved", "NetworkManager", "resolvconf" or
+// "").
+func splitDNSFeasibility(goos, owner string) (feasible bool, reason string) {
+	switch goos {
+	case "windows", "darwin", "ios":
+		return true, ""
+	case "linux":
+		switch owner {
+		case "systemd-resolved":
+			return true, ""
+		case "NetworkManager":
+			return false, "NetworkManager manages resolv.conf without systemd-resolved or dnsmasq, so it can't do split DNS"
+		case "resolvconf":
+			return false, "resolvconf can't do split DNS"
+		}
+		return false, "direct resolv.conf management can't do split DNS"
+	case "freebsd", "openbsd":
+		return false, "direct resolv.conf management can't do split DNS"
+	}
+	return false, "split DNS support unknown on " + goos
+}
+
+var desktopSandboxCache lazy.SyncValue[string]
+
+// DesktopSandbox returns "snap" or "flatpak" if the process is running
+// inside that Linux desktop application sandbox, or "" otherwise. Sandboxed
+// processes see a restricted and remapped filesystem, which explains many
+// permission and path problems, such as with Taildrop or the tailscaled
+// socket.
+//
+// A snap is detected by the $SNAP and $SNAP_NAME environment variables that
+// snapd sets, and a Flatpak by $FLATPAK_ID or the /.flatpak-info file that
+// Flatpak places at the root of the sandbox. The result is cached. It always
+// returns "" on platforms other than Linux.
+//
+// It complements the package type in [tailcfg.Hostinfo], which only reflects
+// how tailscaled itself was packaged.
+func DesktopSandbox() string {
+	if desktopSandbox == nil {
+		return ""
+	}
+	return desktopSandboxCache.Get(desktopSandbox)
+}
+
+// InterfaceSummary returns the host's non-loopback network interfaces,
+// mapping each name to one of "ethernet", "wifi", "cellular", "virtual"
+// (bridges, veth pairs, and the like), "tun" (layer 3 tunnels, such as
+// Tailscale's own), or "unknown". It's meant to give a quick picture of a
+// host's network topology in support bundles.
+//
+// On Linux, interfaces are first classified from sysfs: the wireless
+// extensions or DEVTYPE in /sys/class/net/$IFACE, its ARP hardware type, and
+// whether it's backed by a device. Other interfaces, and those on other
+// platforms, are classified by conventional name prefixes (such as "wlan",
+// "utun" or "docker"). Names matching no heuristic are "unknown".
+func InterfaceSummary() map[string]string {
+	ifs, err := net.Interfaces()
+	if err != nil {
+		return map[string]string{}
+	}
+	m := make(map[string]string, len(ifs))
+	for _, ifc := range ifs {
+		if ifc.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		typ := ""
+		if interfaceType != nil {
+			typ = interfaceType(ifc.Name)
+		}
+		if typ == "" {
+			typ = interfaceTypeFromName(ifc.Name)
+		}
+		m[ifc.Name] = typ
+	}
+	return m
+}
+
+// interfaceNamePrefixes maps conventional interface name prefixes to the
+// interface type they indicate, most specific first. It covers Linux, BSD,
+// macOS and Windows (friendly) names. Plain "en" is deliberately absent, as
+// macOS uses it for both Ethernet and Wi-Fi.
+var interfaceNamePrefixes = []struct {
+	prefix, typ string
+}{
+	{"tailscale", "tun"},
+	{"utun", "tun"},
+	{"tun", "tun"},
+	{"wg", "tun"},
+	{"wi-fi", "wifi"},
+	{"wlan", "wifi"},
+	{"wl", "wifi"},
+	{"ath", "wifi"},
+	{"rmnet", "cellular"},
+	{"wwan", "cellular"},
+	{"ccmni", "cellular"},
+	{"pdp_ip", "cellular"},
+	{"docker", "virtual"},
+	{"veth", "virtual"},
+	{"virbr", "virtual"},
+	{"vmnet", "virtual"},
+	{"vboxnet", "virtual"},
+	{"br", "virtual"},
+	{"lxc", "virtual"},
+	{"cni", "virtual"},
+	{"flannel", "virtual"},
+	{"cali", "virtual"},
+	{"ifb", "virtual"},
+	{"dummy", "virtual"},
+	{"awdl", "virtual"},
+	{"llw", "virtual"},
+	{"ethernet", "ethernet"},
+	{"eth", "ethernet"},
+	{"enp", "ethernet"},
+	{"ens", "ethernet"},
+	{"eno", "ethernet"},
+	{"enx", "ethernet"},
+	{"igb", "ethernet"},
+	{"em", "ethernet"},
+}
+
+// interfaceTypeFromName classifies an interface by its name, returning
+// "unknown" if no prefix in interfaceNamePrefixes matches.
+func interfaceTypeFromName(name string) string {
+	name = strings.ToLower(name)
+	for _, p := range interfaceNamePrefixes {
+		if strings.HasPrefix(name, p.prefix) {
+			return p.typ
+		}
+	}
+	return "unknown"
+}
+
+// CrashDumpDir returns the directory where the OS would store a crash dump
+// or crash report for the current process, or "" if it can't be determined.
+// Go programs produce such dumps only for crashes in native code or when
+// run with GOTRACEBACK=crash; Go panics are otherwise just logged.
+//
+// The sources are:
+//
+//   - on Linux, the kernel.core_pattern sysctl: systemd-coredump's and
+//     apport's storage directories when core dumps are piped to those, the
+//     pattern's directory if it's an absolute path, or else the working
+//     directory
+//   - on macOS, the DiagnosticReports directory, which is in the user's
+//     ~/Library/Logs for the sandboxed (App Store) variant and other
+//     non-root processes, and in /Library/Logs for root daemons
+//   - on Windows, the Windows Error Reporting LocalDumps DumpFolder for the
+//     executable (or the global one), its %LOCALAPPDATA%\CrashDumps default
+//     if LocalDumps is configured without one, or else the WER report queue
+//     under %ProgramData%
+func CrashDumpDir() string {
+	if crashDumpDir == nil {
+		return ""
+	}
+	return crashDumpDir()
+}
+
+// CoreDumpsEnabled reports whether a crash of the current process would
+// produce a core dump (or, on Windows, a crash dump), and whether that could
+// be determined. See [CrashDumpDir] for where it would go. It checks:
+//
+//   - on Linux, that the RLIMIT_CORE soft limit is non-zero and that
+//     kernel.core_pattern is non-empty
+//   - on macOS, that the RLIMIT_CORE soft limit is non-zero and that the
+//     kern.coredump sysctl is set
+//   - on Windows, that Windows Error Reporting isn't disabled and that its
+//     LocalDumps key, which enables keeping user-mode dumps, exists
+//
+// Other platforms report (false, false).
+func CoreDumpsEnabled() (enabled, ok bool) {
+	if coreDumpsEnabled == nil {
+		return false, false
+	}
+	return coreDumpsEnabled()
+}
+
+// ThermalThrottled reports whether the CPU is currently being slowed down to
+// limit its temperature or power draw, and whether that could be determined.
+// Throttling explains sudden throughput drops on laptops and single-board
+// computers.
+//
+// On Linux, it's sourced from:
+//
+//   - on Raspberry Pis, the firmware's throttling state (as reported by
+//     "vcgencmd get_throttled", read from sysfs when the kernel exposes it):
+//     the device is throttled if it's currently under-voltage, frequency
+//     capped, throttled or at its soft temperature limit
+//   - otherwise, the processor cooling devices in /sys/class/thermal: the
+//     device is throttled if any of them is in a non-zero cooling state
+//
+// Other platforms, and Linux hosts with neither, report ok=false.
+func ThermalThrottled() (throttled, ok bool) {
+	if thermalThrottled == nil {
+		return false, false
+	}
+	return thermalThrottled()
+}
+
+// CPUAffinity returns the CPUs the process is allowed to run on, in
+// increasing order, and whether that could be determined. A list shorter than
+// the machine's CPU count means the process was pinned (by taskset, a
+// cpuset cgroup, or the like), which explains it not using all cores.
+//
+// On Linux, it's the process's affinity mask as returned by
+// sched_getaffinity(2). Other platforms report (nil, false).
+func CPUAffinity() (cpus []int, ok bool) {
+	if cpuAffinity == nil {
+		return nil, false
+	}
+	return cpuAffinity()
+}
+
+// formatCPUList formats sorted CPU numbers like Linux's cpuset lists, with
+// runs of consecutive CPUs as ranges, such as "0-3,6".
+func formatCPUList(cpus []int) string {
+	var sb strings.Builder
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		if j == i {
+			fmt.Fprintf(&sb, "%d", cpus[i])
+		} else {
+			fmt.Fprintf(&sb, "%d-%d", cpus[i], cpus[j])
+		}
+		i = j + 1
+	}
+	return sb.String()
+}
+
+var minimalBootModeCache lazy.SyncValue[bool]
+
+// MinimalBootMode reports whether the OS was booted into a minimal
+// environment, such as a recovery, single-user or safe mode, where networking
+// and other services Tailscale relies on may be missing. It detects:
+//
+//   - on Linux, a kernel command line requesting single-user mode or the
+//     rescue or emergency systemd targets, or either of those targets
+//     being active
+//   - on Windows, Safe Mode (with or without networking), via the SafeBoot
+//     option that's present in the registry only then
+//   - on macOS, Safe Mode, via the kern.safeboot sysctl
+//
+// It reports false by default and on other platforms. The result is cached.
+func MinimalBootMode() bool {
+	if minimalBootMode == nil {
+		return false
+	}
+	return minimalBootModeCache.Get(minimalBootMode)
+}
+
+// HasCryptoOffloadNIC reports whether the network interface carrying the
+// default route has hardware cryptographic offload enabled, for
+// high-throughput deployments.
+//
+// On Linux, it runs "ethtool -k" on the interface (the one with the lowest
+// metric default route in the main routing table, from /proc/net/route) and
+// checks for any of the esp-hw-offload, tls-hw-tx-offload, tls-hw-rx-offload
+// or macsec-hw-offload features being on. Those are the only crypto offloads
+// the kernel exposes; WireGuard can't currently use any of them, so this
+// indicates NIC capability rather than offloaded Tailscale traffic. It
+// reports false if ethtool isn't installed, and always on other platforms.
+func HasCryptoOffloadNIC() bool {
+	if nicCryptoOffload == nil || defaultRouteInterface == nil {
+		return false
+	}
+	iface := defaultRouteInterface()
+	return iface != "" && nicCryptoOffload(iface)
+}
+
+// SwapInfo returns the total size of the host's swap space, whether any is
+// configured, and whether that could be determined. Swapping explains
+// latency spikes under memory pressure, and its absence explains processes
+// getting OOM-killed instead. The sources are:
+//
+//   - on Linux, SwapTotal in /proc/meminfo, which sums the swap devices and
+//     files listed in /proc/swaps
+//   - on Windows, the page file size, computed as the system commit limit
+//     minus physical memory as reported by GlobalMemoryStatusEx
+//   - on macOS, the vm.swapusage sysctl. macOS creates swap files on demand,
+//     so a zero total there just means none are in use yet.
+//
+// Other platforms report ok=false.
+func SwapInfo() (totalBytes uint64, enabled, ok bool) {
+	if swapTotal == nil {
+		return 0, false, false
+	}
+	totalBytes, ok = swapTotal()
+	return totalBytes, totalBytes > 0, ok
+}
+
+// CurrentUmask returns the process's file mode creation mask, which is
+// cleared from the permissions of files tailscaled creates, such as its
+// state file, and whether it could be read. An unusual umask explains state
+// files that are too permissive or that other users can't read.
+//
+// On Linux 4.7 and later, it's read from /proc/self/status. Elsewhere, and on
+// older kernels, the only way to read it is umask(2), which also sets it, so
+// it's briefly set to 077 and then restored. Files created by other
+// goroutines in that window can get the wrong (though never more
+// permissive) mode. It reports ok=false on non-Unix platforms.
+func CurrentUmask() (mask int, ok bool) {
+	if currentUmask == nil {
+		return 0, false
+	}
+	return currentUmask()
+}
+
+// MaxRoutes returns the maximum number of routes the kernel lets Tailscale
+// install, and whether it could be determined. Subnet routes and exit node
+// use can involve many routes, and hitting the limit makes installing more
+// fail.
+//
+// On Linux, it's the smaller of the net.ipv4.route.max_size and
+// net.ipv6.route.max_size sysctls, whichever exist. The IPv4 one is
+// effectively unlimited on kernels since 3.6, which removed the IPv4 route
+// cache, while the IPv6 one was 4096 by default on kernels before 6.3 and
+// is the limit most often hit. Other platforms report ok=false.
+func MaxRoutes() (n int, ok bool) {
+	if maxRoutes == nil {
+		return 0, false
+	}
+	return maxRoutes()
+}
+
+var sharedHostingCache lazy.SyncValue[bool]
+
+// IsSharedHosting reports whether the node appears to run on shared,
+// multi-tenant hosting (a public cloud, VPS or serverless platform) rather
+// than on dedicated or on-premises hardware. Such environments typically put
+// nodes behind provider NATs and firewalls, which affects NAT traversal.
+//
+// It's a heuristic that defaults to false. It reports true if any of these
+// hold:
+//
+//   - a public cloud is detected (see [tailscale.com/util/cloudenv])
+//   - the environment is a serverless or platform-as-a-service one, such as
+//     AWS Lambda, Fargate, Heroku or Fly.io (see [GetEnvType])
+//   - on Linux, the host is a virtual machine (a hypervisor is reported in
+//     /proc/cpuinfo) whose DMI system vendor is a known VPS provider
+//
+// Containers and virtual machines on their own don't count, as they're
+// equally common on laptops and on-premises servers. The result is cached.
+func IsSharedHosting() bool {
+	return sharedHostingCache.Get(func() bool {
+		if cloudenv.Get() != "" {
+			return true
+		}
+		switch GetEnvType() {
+		case KNative, AWSLambda, Heroku, AzureAppService, AWSFargate, FlyDotIo, Replit:
+			return true
+		}
+		if vmVendor == nil {
+			return false
+		}
+		vendor, isVM := vmVendor()
+		return isVM && isVPSVendor(vendor)
+	})
+}
+
+// vpsVendors are substrings of the DMI system vendors of VPS providers,
+// lowercased.
+var vpsVendors = []string{
+	"amazon",
+	"digitalocean",
+	"google",
+	"hetzner",
+	"linode",
+	"akamai",
+	"vultr",
+	"ovh",
+	"scaleway",
+	"contabo",
+	"alibaba",
+	"tencent",
+	"oracle",
+	"upcloud",
+	// OpenStack is used by many smaller providers, but also on-premises,
+	// so it's not included.
+}
+
+// isVPSVendor reports whether vendor, a DMI system vendor, is a known VPS
+// provider.
+func isVPSVendor(vendor string) bool {
+	vendor = strings.ToLower(vendor)
+	for _, v := range vpsVendors {
+		if strings.Contains(vendor, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// EntropyAvailable returns the kernel's estimate of the entropy available to
+// its random number generator, in bits, and whether it could be determined.
+// Before the pool is initialized, reads of random data can block, which shows
+// up as slow startups on freshly booted headless devices like single-board
+// computers that have few entropy sources.
+//
+// On Linux, it's read from /proc/sys/kernel/random/entropy_avail. Since
+// Linux 5.18 the pool is never depleted once initialized and the value is
+// always 256. Other platforms, and kernels that don't expose it, report
+// ok=false.
+func EntropyAvailable() (bits int, ok bool) {
+	if entropyAvailable == nil {
+		return 0, false
+	}
+	return entropyAvailable()
+}
+
+// ThreadLimit returns the maximum number of threads (and processes) the
+// current process can create, if limited. Reaching it makes creating
+// threads fail with EAGAIN, "resource temporarily unavailable", which Go
+// reports as a fatal error, so tailscaled handling many connections may
+// crash with it.
+//
+// On Linux, it's the lower of the RLIMIT_NPROC soft limit (see
+// getrlimit(2)), which limits the threads of all of the user's processes
+// taken together, and the kernel.threads-max sysctl, the system-wide limit.
+// Other platforms report ok=false.
+func ThreadLimit() (n uint64, ok bool) {
+	if threadLimit == nil {
+		return 0, false
+	}
+	return threadLimit()
+}
+
+// hostnameLookupTimeout is how long [HostnameResolvesLocally] waits for the
+// hostname to resolve.
+const hostnameLookupTimeout = 2 * time.Second
+
+// HostnameResolvesLocally reports whether the host's own hostname (from
+// [os.Hostname]) resolves, and if so the first address it resolves to. A
+// hostname that doesn't resolve, or that resolves to an external or stale
+// address, causes subtle problems with software that looks itself up, and
+// can interact badly with MagicDNS.
+//
+// It resolves the name with the system's resolver (on most platforms,
+// /etc/hosts or its equivalent and then DNS), waiting at most two seconds.
+// The result isn't cached, as it can change with the network.
+func HostnameResolvesLocally() (ok bool, addr string) {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return false, ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hostnameLookupTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, name)
+	if err != nil || len(addrs) == 0 {
+		return false, ""
+	}
+	return true, addrs[0].String()
+}
+
+// IPv6Disabled reports whether IPv6 is administratively disabled on the
+// host, and if so a short description of how. This distinguishes "IPv6 is
+// turned off" from "the network has no IPv6 connectivity", which otherwise
+// look the same. It returns (false, "") when IPv6 is enabled or the state is
+// unknown.
+//
+// On Linux, it checks for ipv6.disable=1 on the kernel command line, a
+// kernel without IPv6 support, and the net.ipv6.conf.all.disable_ipv6
+// sysctl. On Windows, it checks the DisabledComponents registry value of the
+// Tcpip6 service, which disables IPv6 on native interfaces if bit 0x10 is
+// set. Other platforms report (false, "").
+func IPv6Disabled() (disabled bool, reason string) {
+	if ipv6Disabled == nil {
+		return false, ""
+	}
+	return ipv6Disabled()
+}
+
+// AddressingMode returns how the IPv4 addresses of the host's primary
+// network interface, the one with the default route, are configured:
+//
+//   - "dhcp", if all were obtained through DHCP
+//   - "static", if all were configured manually
+//   - "mixed", if there are both
+//   - "unknown", if it couldn't be determined
+//
+// Dynamic addresses change when leases expire or the network changes, which
+// explains frequently changing endpoints.
+//
+// On Linux, addresses are classified by the kernel's permanent flag: DHCP
+// clients, including those of NetworkManager, systemd-networkd, dhclient
+// and dhcpcd, add addresses with a lease lifetime, while static
+// configuration adds permanent ones. On Windows, it's the address prefix
+// origin reported by GetAdaptersAddresses. On macOS, an interface that has a
+// DHCP lease (per "ipconfig getpacket") is reported as "dhcp" and one with
+// IPv4 addresses without one as "static". Other platforms report "unknown".
+func AddressingMode() string {
+	if addressingMode == nil {
+		return "unknown"
+	}
+	if m := addressingMode(); m != "" {
+		return m
+	}
+	return "unknown"
+}
+
+// classifyAddressing returns the [AddressingMode] for an interface with the
+// given numbers of dynamically and statically configured addresses.
+func classifyAddressing(dynamic, static int) string {
+	switch {
+	case dynamic > 0 && static > 0:
+		return "mixed"
+	case dynamic > 0:
+		return "dhcp"
+	case static > 0:
+		return "static"
+	}
+	return "unknown"
+}
+
+var hasRawSocketCache lazy.SyncValue[bool]
+
+// HasRawSocketSupport reports whether the process can open raw AF_PACKET
+// sockets, which datapaths that read or write link-layer frames directly
+// need. Without them, such datapaths fall back to regular sockets.
+//
+// On Linux, it checks by creating, and immediately closing, an AF_PACKET
+// socket. That fails without CAP_NET_RAW, as in unprivileged containers, or
+// when blocked by seccomp. The result is cached.
+//
+// It always reports false on other platforms.
+func HasRawSocketSupport() bool {
+	if hasRawSocket == nil {
+		return false
+	}
+	return hasRawSocketCache.Get(hasRawSocket)
+}
+
+// SeccompActive reports whether the process runs under seccomp, and its
+// seccomp mode. seccomp can make syscalls that tailscaled needs fail in
+// ways that are hard to explain, as in hardened container environments. The
+// mode is one of:
+//
+//   - "disabled", with active false, when seccomp isn't in use
+//   - "strict", when only read, write, _exit and sigreturn are allowed
+//   - "filter", when a BPF filter decides which syscalls are allowed
+//
+// On Linux, it's read from the Seccomp field of /proc/self/status. It
+// returns (false, "") on other platforms and on kernels built without
+// seccomp, which don't have the field.
+func SeccompActive() (active bool, mode string) {
+	if seccompMode == nil {
+		return false, ""
+	}
+	mode = seccompMode()
+	return mode == "strict" || mode == "filter", mode
+}
+
+// CGNATRangeConflict reports whether the host routing table has routes that
+// overlap Tailscale's CGNAT range, 100.64.0.0/10, and returns them. Such
+// routes, typically from an ISP using carrier-grade NAT or from another VPN,
+// capture traffic meant for Tailscale peers and are a common cause of broken
+// connectivity. It returns (false, nil) when there are none.
+//
+// On Linux, it inspects the main IPv4 routing table from /proc/net/route,
+// ignoring default routes and routes via Tailscale's own interfaces (those
+// named tailscale*), and formats routes like "ip route" does. Other
+// platforms report (false, nil).
+func CGNATRangeConflict() (conflict bool, routes []string) {
+	if cgnatRoutes == nil {
+		return false, nil
+	}
+	routes = cgnatRoutes()
+	return len(routes) > 0, routes
+}
+
+// HasBrowser reports whether a web browser can likely be launched to show
+// the user a page, such as for interactive login. If not, as on headless
+// servers, callers should print the URL for the user to open elsewhere.
+//
+// On Linux, it requires either the BROWSER environment variable to be set,
+// or a graphical session (DISPLAY or WAYLAND_DISPLAY set) and a launcher
+// (xdg-open, sensible-browser or x-www-browser) in $PATH. On macOS, it's
+// true except in SSH sessions. On Windows, it's true except in session 0,
+// where services run without a desktop. Other platforms report false.
+func HasBrowser() bool {
+	if hasBrowser == nil {
+		return false
+	}
+	return hasBrowser()
+}
+
+// HasClipboard reports whether text can likely be copied to the user's
+// clipboard, such as to offer copying a login URL. It's false on servers
+// and mobile platforms.
+//
+// On Linux, it requires a Wayland session (WAYLAND_DISPLAY set) with
+// wl-copy in $PATH, or an X11 session (DISPLAY set) with xclip or xsel in
+// $PATH. On macOS, it's true except in SSH sessions, where pbcopy would
+// copy to the remote Mac's clipboard rather than the user's. On Windows,
+// it's true except in session 0, where services run without a desktop.
+// Other platforms, including Android and iOS, report false.
+func HasClipboard() bool {
+	if hasClipboard == nil {
+		return false
+	}
+	return hasClipboard()
+}
+
+// AggressiveNegativeCaching reports whether a caching resolver on the host
+// caches negative answers (such as NXDOMAIN) for longer than a minute, and
+// if so which setting causes it. Names that were looked up before they
+// existed, as just before joining a tailnet, then keep failing to resolve
+// until the cached answer expires. It returns (false, "") when that's not
+// an issue.
+//
+// On Linux, it checks the configuration of these caching resolvers, if
+// they're running:
+//
+//   - dnsmasq: neg-ttl, in /etc/dnsmasq.conf, /etc/dnsmasq.d and
+//     NetworkManager's /etc/NetworkManager/dnsmasq.d
+//   - unbound: cache-max-negative-ttl, which defaults to an hour, in
+//     /etc/unbound/unbound.conf and /etc/unbound/unbound.conf.d
+//   - nscd: negative-time-to-live for the hosts cache, in /etc/nscd.conf
+//
+// systemd-resolved isn't checked, as it has no negative caching setting
+// beyond turning it off: it uses the TTLs of the SOA records in negative
+// answers. Other platforms report (false, "").
+func AggressiveNegativeCaching() (aggressive bool, reason string) {
+	if negativeCaching == nil {
+		return false, ""
+	}
+	return negativeCaching()
+}
+
+var hasTZDataCache lazy.SyncValue[bool]
+
+// HasTZData reports whether the time zone database is available, so that
+// time zones other than UTC and the local one can be loaded. Minimal and
+// scratch containers often lack it, and then times get formatted in UTC or
+// fail to parse.
+//
+// It loads the Etc/UTC zone with [time.LoadLocation], which consults $ZONEINFO,
+// the system's database (such as /usr/share/zoneinfo on most Unix systems)
+// and, if it's compiled in with the timetzdata build tag or by importing
+// time/tzdata, Go's embedded copy, in which case HasTZData always reports
+// true. On Windows, Go doesn't use the system's time zone information, so
+// without the embedded copy it's usually false there. The result is cached.
+func HasTZData() bool {
+	return hasTZDataCache.Get(func() bool {
+		_, err := time.LoadLocation("Etc/UTC")
+		return err == nil
+	})
+}
+
+// HasUrandom reports whether /dev/urandom exists and can be read from.
+// Broken or minimal containers sometimes lack it, which breaks programs
+// (such as helpers that tailscaled runs) that take their randomness from
+// it. Go's crypto/rand itself uses the getrandom system call or its
+// equivalent where there is one.
+//
+// On Unix platforms, it opens /dev/urandom and reads a byte from it. The
+// other platforms have other sources of randomness, so it reports true. The
+// result is cached.
+func HasUrandom() bool {
+	if hasUrandom == nil {
+		return true
+	}
+	return hasUrandom()
+}
+
+// AsymmetricRouting reports whether the host's routing looks asymmetric, with
+// replies to some incoming traffic leaving through a different interface
+// than the traffic arrived on, and describes how. Stateful firewalls along
+// either path see only half of each connection and drop it, and strict
+// reverse path filtering (see [ReversePathFilterStrict]) drops packets
+// arriving on the "wrong" interface, which breaks connections to the node.
+//
+// On Linux, it assumes that traffic addressed to the host arrives through
+// the gateways of its IPv4 default routes in the main routing table, and
+// that replies leave through the lowest metric one. It reports asymmetric
+// routing if another default route goes through a different interface, as
+// replies to traffic arriving there would then leave through the primary
+// one. That may be a false positive: policy routing rules that route
+// replies back out the interface they arrived on, as multi-WAN setups
+// should have, aren't considered, nor is whether anything arrives through
+// the secondary gateway at all. Other platforms report (false, "").
+func AsymmetricRouting() (bool, string) {
+	if asymmetricRouting == nil {
+		return false, ""
+	}
+	return asymmetricRouting()
+}
+
+// PolicyRoutingActive reports whether the host uses policy-based routing,
+// with rules choosing among several routing tables, and returns the rules.
+// Such rules can send traffic for Tailscale peers, or Tailscale's own
+// traffic to DERP and control, somewhere other than the main table suggests,
+// which explains many routing surprises on multi-WAN routers and other
+// sophisticated setups. It returns (false, nil) on simple configurations.
+//
+// On Linux, it lists the IPv4 and IPv6 rules with "ip rule" and returns
+// those other than the kernel's defaults (the local, main and default table
+// lookups at preferences 0, 32766 and 32767) and the rules that tailscaled
+// installs itself (those using table 52 or Tailscale's bypass fwmark), as
+// printed, prefixed with "-6" for IPv6 rules. Other platforms, and hosts
+// without the ip command, report (false, nil).
+func PolicyRoutingActive() (bool, []string) {
+	if policyRoutingRules == nil {
+		return false, nil
+	}
+	rules := policyRoutingRules()
+	return len(rules) > 0, rules
+}
@@ -0,0 +1,16 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build darwin || freebsd
+
+package hostinfo
+
+import "golang.org/x/sys/unix"
+
+func init() {
+	ipForwarding = func() (v4, v6 bool) {
+		fwd4, _ := unix.SysctlUint32("net.inet.ip.forwarding")
+		fwd6, _ := unix.SysctlUint32("net.inet6.ip6.forwarding")
+		return fwd4 != 0, fwd6 != 0
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"tailscale.com/version"
+)
+
+func init() {
+	hasStableMachineID = hasPlatformUUIDDarwin
+	coreDumpsEnabled = func() (enabled, ok bool) {
+		allowed, ok := coreRlimitAllowsDumps()
+		if !ok {
+			return false, false
+		}
+		v, err := unix.SysctlUint32("kern.coredump")
+		if err != nil {
+			return false, false
+		}
+		return allowed && v != 0, true
+	}
+	ntpSynced = func() (synced, ok bool) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "/usr/sbin/systemsetup", "-getusingnetworktime").Output()
+		if err != nil {
+			return false, false
+		}
+		return parseSystemsetupNetworkTime(out)
+	}
+	addressingMode = addressingModeDarwin
+	defaultGateway = defaultGatewayDarwin
+	hasBrowser = notInSSHSession
+	dnsSearchDomains = func() []string {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "/usr/sbin/scutil", "--dns").Output()
+		if err != nil {
+			return nil
+		}
+		return parseScutilDNSSearch(out)
+	}
+	dnsResolverAddr = func() netip.Addr {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "/usr/sbin/scutil", "--dns").Output()
+		if err != nil {
+			return netip.Addr{}
+		}
+		for _, v := range scutilDefaultResolverValues(out, "nameserver[") {
+			if ip, err := netip.ParseAddr(v); err == nil {
+				return ip.WithZone("")
+			}
+		}
+		return netip.Addr{}
+	}
+	if runtime.GOOS == "darwin" {
+		hasClipboard = notInSSHSession
+	}
+	minimalBootMode = func() bool {
+		v, err := unix.SysctlUint32("kern.safeboot")
+		return err == nil && v != 0
+	}
+	swapTotal = func() (uint64, bool) {
+		// struct xsw_usage begins with the uint64 xsu_total.
+		b, err := unix.SysctlRaw("vm.swapusage")
+		if err != nil || len(b) < 8 {
+			return 0, false
+		}
+		return binary.NativeEndian.Uint64(b), true
+	}
+	crashDumpDir = func() string {
+		if os.Geteuid() == 0 && !version.IsSandboxedMacOS() {
+			return "/Library/Logs/DiagnosticReports"
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(home, "Library/Logs/DiagnosticReports")
+	}
+	systemProxy = func() string {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "/usr/sbin/scutil", "--proxy").Output()
+		if err != nil {
+			return ""
+		}
+		return parseScutilProxy(out)
+	}
+	systemPAC = func() (bool, string) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "/usr/sbin/scutil", "--proxy").Output()
+		if err != nil {
+			return false, ""
+		}
+		return parseScutilPAC(out)
+	}
+	ipv6TempAddrs = func() (enabled, ok bool) {
+		v, err := unix.SysctlUint32("net.inet6.ip6.use_tempaddr")
+		if err != nil {
+			return false, false
+		}
+		return v != 0, true
+	}
+}
+
+func hasPlatformUUIDDarwin() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "/usr/sbin/ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output()
+	return err == nil && bytes.Contains(out, []byte(`"IOPlatformUUID"`))
+}
+
+func addressingModeDarwin() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "/sbin/route", "-n", "get", "default").Output()
+	if err != nil {
+		return ""
+	}
+	var name string
+	for line := range bytes.Lines(out) {
+		if v, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("interface:")); ok {
+			name = string(bytes.TrimSpace(v))
+		}
+	}
+	ifc, err := net.InterfaceByName(name)
+	if err != nil {
+		return ""
+	}
+	addrs, err := ifc.Addrs()
+	if err != nil {
+		return ""
+	}
+	var n int
+	for _, a := range addrs {
+		if ipn, ok := a.(*net.IPNet); ok && ipn.IP.To4() != nil {
+			n++
+		}
+	}
+	// getpacket fails if the interface has no DHCP lease.
+	if exec.CommandContext(ctx, "/usr/sbin/ipconfig", "getpacket", name).Run() == nil {
+		return classifyAddressing(n, 0)
+	}
+	return classifyAddressing(0, n)
+}
+
+func defaultGatewayDarwin() netip.Addr {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "/sbin/route", "-n", "get", "default").Output()
+	if err != nil {
+		return netip.Addr{}
+	}
+	return parseRouteGetGateway(out)
+}
+
+func notInSSHSession() bool {
+	return os.Getenv("SSH_CONNECTION") == "" && os.Getenv("SSH_TTY") == ""
+}
@@ -0,0 +1,612 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"maps"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"tailscale.com/util/lineiter"
+)
+
+func init() {
+	hasRawSocket = func() bool {
+		// EPERM without CAP_NET_RAW or when blocked by seccomp.
+		fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, 0)
+		if err != nil {
+			return false
+		}
+		unix.Close(fd)
+		return true
+	}
+	defaultRouteInterface = defaultRouteInterfaceLinux
+	defaultGateway = func() netip.Addr { return defaultGatewayOf(readProcNetRoute()) }
+	neighborResolved = func(ip netip.Addr) bool {
+		b, _ := os.ReadFile("/proc/net/arp")
+		return arpResolved(b, ip)
+	}
+	cgroupCPUQuota = cgroupCPUQuotaLinux
+	multiWAN = func() bool { return hasMultipleDefaultGateways(readProcNetRoute()) }
+	cgnatRoutes = func() []string { return cgnatConflicts(readProcNetRoute()) }
+	hasBrowser = func() bool { return canLaunchBrowser(os.Getenv, exec.LookPath) }
+	negativeCaching = aggressiveNegativeCachingLinux
+	dnsSearchDomains = func() []string {
+		b, _ := os.ReadFile(resolvConf)
+		return parseResolvConfSearch(b)
+	}
+	dnsResolverAddr = func() netip.Addr {
+		b, _ := os.ReadFile(resolvConf)
+		return parseResolvConfNameserver(b)
+	}
+	if runtime.GOOS != "android" {
+		hasClipboard = func() bool { return hasClipboardTool(os.Getenv, exec.LookPath) }
+	}
+	hasStableMachineID = hasMachineIDLinux
+	maxSocketBuffers = maxSocketBuffersLinux
+	ipv6TempAddrs = ipv6TempAddrsLinux
+	ipForwarding = func() (v4, v6 bool) {
+		fwd4, _ := readSysctlInt("net.ipv4.ip_forward")
+		fwd6, _ := readSysctlInt("net.ipv6.conf.all.forwarding")
+		return fwd4 != 0, fwd6 != 0
+	}
+	strictRPFilter = strictRPFilterLinux
+	resolvConfOwner = resolvConfOwnerLinux
+	systemdResolved = systemdResolvedLinux
+	desktopSandbox = desktopSandboxLinux
+	interfaceType = interfaceTypeLinux
+	coreDumpsEnabled = func() (enabled, ok bool) {
+		allowed, ok := coreRlimitAllowsDumps()
+		if !ok {
+			return false, false
+		}
+		pattern, err := readSysctl("kernel.core_pattern")
+		if err != nil {
+			return false, false
+		}
+		return allowed && pattern != "", true
+	}
+	ntpSynced = func() (synced, ok bool) {
+		var tx unix.Timex // zero Modes only reads the state
+		if _, err := unix.Adjtimex(&tx); err != nil {
+			return false, false
+		}
+		return tx.Status&unix.STA_UNSYNC == 0, true
+	}
+	thermalThrottled = thermalThrottledLinux
+	minimalBootMode = minimalBootModeLinux
+	ipv6Disabled = ipv6DisabledLinux
+	addressingMode = addressingModeLinux
+	seccompMode = func() string {
+		f, err := os.Open("/proc/self/status")
+		if err != nil {
+			return ""
+		}
+		defer f.Close()
+		return parseProcStatusSeccomp(bufio.NewScanner(f))
+	}
+	vmVendor = func() (vendor string, isVM bool) {
+		b, _ := os.ReadFile("/sys/class/dmi/id/sys_vendor")
+		vendor = strings.TrimSpace(string(b))
+		for lr := range lineiter.File("/proc/cpuinfo") {
+			line, _ := lr.Value()
+			if strings.HasPrefix(string(line), "flags") && slices.Contains(strings.Fields(string(line)), "hypervisor") {
+				return vendor, true
+			}
+		}
+		return vendor, false
+	}
+	entropyAvailable = func() (int, bool) {
+		v, err := readSysctlInt("kernel.random.entropy_avail")
+		return v, err == nil
+	}
+	threadLimit = threadLimitLinux
+	asymmetricRouting = func() (bool, string) { return asymmetricDefaultRoutes(readProcNetRoute()) }
+	policyRoutingRules = policyRoutingRulesLinux
+	maxRoutes = func() (n int, ok bool) {
+		for _, name := range []string{"net.ipv4.route.max_size", "net.ipv6.route.max_size"} {
+			if v, err := readSysctlInt(name); err == nil && v > 0 && (!ok || v < n) {
+				n, ok = v, true
+			}
+		}
+		return n, ok
+	}
+	swapTotal = func() (uint64, bool) {
+		b, err := os.ReadFile("/proc/meminfo")
+		if err != nil {
+			return 0, false
+		}
+		return parseMeminfoSwapTotal(string(b))
+	}
+	nicCryptoOffload = func(iface string) bool {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "ethtool", "-k", iface).Output()
+		return err == nil && hasEthtoolCryptoOffload(string(out))
+	}
+	cpuAffinity = func() ([]int, bool) {
+		var set unix.CPUSet
+		if err := unix.SchedGetaffinity(0, &set); err != nil {
+			return nil, false
+		}
+		n := set.Count()
+		cpus := make([]int, 0, n)
+		for i := 0; len(cpus) < n; i++ {
+			if set.IsSet(i) {
+				cpus = append(cpus, i)
+			}
+		}
+		return cpus, true
+	}
+	crashDumpDir = func() string {
+		pattern, err := readSysctl("kernel.core_pattern")
+		if err != nil {
+			return ""
+		}
+		cwd, _ := os.Getwd()
+		return coreDumpDirFromPattern(pattern, cwd)
+	}
+	networkSysctls = func() map[string]string {
+		m := make(map[string]string, len(networkSysctlNames))
+		for _, name := range networkSysctlNames {
+			if v, err := readSysctl(name); err == nil {
+				m[name] = v
+			}
+		}
+		return m
+	}
+	rootReadOnly = func() bool {
+		var st unix.Statfs_t
+		return unix.Statfs("/", &st) == nil && st.Flags&unix.ST_RDONLY != 0
+	}
+}
+
+// cgroupCPUQuotaLinux returns the CPU quota of the current process's cgroup,
+// in CPUs.
+func cgroupCPUQuotaLinux() (cpus float64, ok bool) {
+	// cgroup v2, where /proc/self/cgroup is a single "0::/path" line. In a
+	// container with its own cgroup namespace, the path is "/".
+	if b, err := os.ReadFile("/proc/self/cgroup"); err == nil {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(string(b)), "0::"); ok {
+			for _, dir := range []string{path.Join("/sys/fs/cgroup", rest), "/sys/fs/cgroup"} {
+				if b, err := os.ReadFile(path.Join(dir, "cpu.max")); err == nil {
+					return parseCgroupV2CPUMax(string(b))
+				}
+			}
+		}
+	}
+	// cgroup v1.
+	quota, err1 := os.ReadFile("/sys/fs/cgroup/cpu/cpu.cfs_quota_us")
+	period, err2 := os.ReadFile("/sys/fs/cgroup/cpu/cpu.cfs_period_us")
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	return cgroupQuotaCPUs(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+// parseCgroupV2CPUMax parses the contents of a cgroup v2 cpu.max file, which
+// is of the form "$MAX $PERIOD", where $MAX is "max" if there's no quota.
+func parseCgroupV2CPUMax(s string) (cpus float64, ok bool) {
+	quota, period, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return 0, false
+	}
+	return cgroupQuotaCPUs(quota, period)
+}
+
+// cgroupQuotaCPUs returns quota/period, interpreting a quota of "max" (v2) or
+// "-1" (v1) as no quota.
+func cgroupQuotaCPUs(quota, period string) (cpus float64, ok bool) {
+	if quota == "max" || quota == "-1" {
+		return 0, false
+	}
+	q, err1 := strconv.ParseFloat(quota, 64)
+	p, err2 := strconv.ParseFloat(period, 64)
+	if err1 != nil || err2 != nil || q <= 0 || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
+
+func minimalBootModeLinux() bool {
+	if b, err := os.ReadFile("/proc/cmdline"); err == nil && isMinimalBootCmdline(string(b)) {
+		return true
+	}
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	// is-active succeeds if any of the given units is active.
+	return exec.CommandContext(ctx, "systemctl", "is-active", "--quiet", "rescue.target", "emergency.target").Run() == nil
+}
+
+// isMinimalBootCmdline reports whether the kernel command line cmdline asks
+// init to boot into single-user, rescue or emergency mode.
+func isMinimalBootCmdline(cmdline string) bool {
+	for _, arg := range strings.Fields(cmdline) {
+		switch arg {
+		case "single", "S", "s", "1", "rescue", "emergency", "-b",
+			"systemd.unit=rescue.target", "systemd.unit=emergency.target":
+			return true
+		}
+	}
+	return false
+}
+
+func ipv6DisabledLinux() (disabled bool, reason string) {
+	if b, err := os.ReadFile("/proc/cmdline"); err == nil && cmdlineDisablesIPv6(string(b)) {
+		return true, "ipv6.disable=1 on kernel command line"
+	}
+	if _, err := os.Stat("/proc/sys/net/ipv6"); os.IsNotExist(err) {
+		// Also the case with ipv6.disable=1, handled above.
+		return true, "kernel built without IPv6"
+	}
+	if v, err := readSysctl("net.ipv6.conf.all.disable_ipv6"); err == nil && v == "1" {
+		return true, "net.ipv6.conf.all.disable_ipv6=1"
+	}
+	return false, ""
+}
+
+// cmdlineDisablesIPv6 reports whether the kernel command line cmdline
+// disables the IPv6 stack.
+func cmdlineDisablesIPv6(cmdline string) bool {
+	return slices.Contains(strings.Fields(cmdline), "ipv6.disable=1")
+}
+
+func addressingModeLinux() string {
+	name := defaultRouteInterfaceLinux()
+	if name == "" {
+		return ""
+	}
+	ifc, err := net.InterfaceByName(name)
+	if err != nil {
+		return ""
+	}
+	rib, err := syscall.NetlinkRIB(unix.RTM_GETADDR, unix.AF_INET)
+	if err != nil {
+		return ""
+	}
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		return ""
+	}
+	var dynamic, static int
+	for _, m := range msgs {
+		if m.Header.Type != unix.RTM_NEWADDR || len(m.Data) < unix.SizeofIfAddrmsg {
+			continue
+		}
+		// m.Data starts with a struct ifaddrmsg: the family, prefix
+		// length, flags and scope bytes, then the interface index.
+		flags, scope := m.Data[2], m.Data[3]
+		index := binary.NativeEndian.Uint32(m.Data[4:8])
+		if int(index) != ifc.Index || scope != unix.RT_SCOPE_UNIVERSE {
+			continue
+		}
+		if flags&unix.IFA_F_PERMANENT != 0 {
+			static++
+		} else {
+			dynamic++
+		}
+	}
+	return classifyAddressing(dynamic, static)
+}
+
+// parseProcStatusSeccomp returns the seccomp mode from the "Seccomp:" line
+// scanned from /proc/self/status, or the empty string if there isn't one.
+func parseProcStatusSeccomp(sc *bufio.Scanner) string {
+	for sc.Scan() {
+		v, found := strings.CutPrefix(sc.Text(), "Seccomp:")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(v) {
+		case "0":
+			return "disabled"
+		case "1":
+			return "strict"
+		case "2":
+			return "filter"
+		}
+		return ""
+	}
+	return ""
+}
+
+// canLaunchBrowser reports whether a browser can be launched, given the
+// environment getenv and a lookPath to find launchers.
+func canLaunchBrowser(getenv func(string) string, lookPath func(string) (string, error)) bool {
+	if getenv("BROWSER") != "" {
+		return true
+	}
+	if getenv("DISPLAY") == "" && getenv("WAYLAND_DISPLAY") == "" {
+		return false
+	}
+	for _, launcher := range []string{"xdg-open", "sensible-browser", "x-www-browser"} {
+		if _, err := lookPath(launcher); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasClipboardTool reports whether there's a tool to copy to the clipboard
+// of the graphical session, given the environment getenv and a lookPath to
+// find tools.
+func hasClipboardTool(getenv func(string) string, lookPath func(string) (string, error)) bool {
+	var tools []string
+	if getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, "wl-copy")
+	}
+	if getenv("DISPLAY") != "" {
+		tools = append(tools, "xclip", "xsel")
+	}
+	for _, tool := range tools {
+		if _, err := lookPath(tool); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func hasMachineIDLinux() bool {
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		b, _ := os.ReadFile(path)
+		// An "uninitialized" machine-id means first boot hasn't completed,
+		// and the ID will be replaced.
+		if id := strings.TrimSpace(string(b)); id != "" && id != "uninitialized" {
+			return true
+		}
+	}
+	return false
+}
+
+// readSysctl returns the value of the named sysctl (such as
+// "net.core.rmem_max") as read from /proc/sys, with surrounding whitespace
+// removed.
+func readSysctl(name string) (string, error) {
+	b, err := os.ReadFile(path.Join("/proc/sys", strings.ReplaceAll(name, ".", "/")))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// readSysctlInt is like readSysctl, but parses the value as an integer.
+func readSysctlInt(name string) (int, error) {
+	v, err := readSysctl(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(v)
+}
+
+// threadLimitLinux returns the lower of the RLIMIT_NPROC soft limit, which
+// on Linux limits the threads of all the user's processes, and the
+// system-wide kernel.threads-max sysctl.
+func threadLimitLinux() (uint64, bool) {
+	var n uint64
+	var ok bool
+	var lim unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NPROC, &lim); err == nil && lim.Cur != unix.RLIM_INFINITY {
+		n, ok = lim.Cur, true
+	}
+	if v, err := readSysctlInt("kernel.threads-max"); err == nil && v > 0 && (!ok || uint64(v) < n) {
+		n, ok = uint64(v), true
+	}
+	return n, ok
+}
+
+func maxSocketBuffersLinux() (rmem, wmem int, ok bool) {
+	rmem, err1 := readSysctlInt("net.core.rmem_max")
+	wmem, err2 := readSysctlInt("net.core.wmem_max")
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return rmem, wmem, true
+}
+
+func ipv6TempAddrsLinux() (enabled, ok bool) {
+	ents, err := os.ReadDir("/proc/sys/net/ipv6/conf")
+	if err != nil {
+		return false, false
+	}
+	for _, de := range ents {
+		if de.Name() == "lo" {
+			continue
+		}
+		// Read the file directly rather than with readSysctl, as
+		// interface names may contain dots.
+		b, err := os.ReadFile(path.Join("/proc/sys/net/ipv6/conf", de.Name(), "use_tempaddr"))
+		if err != nil {
+			continue
+		}
+		ok = true
+		// -1 means not applicable (such as on point-to-point links), 0
+		// disabled, 1 enabled, and 2 enabled and preferred.
+		if v, err := strconv.Atoi(strings.TrimSpace(string(b))); err == nil && v > 0 {
+			return true, true
+		}
+	}
+	return false, ok
+}
+
+func strictRPFilterLinux() (iface string) {
+	const dir = "/proc/sys/net/ipv4/conf"
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	all := -1
+	ifaces := make(map[string]int)
+	for _, de := range ents {
+		b, err := os.ReadFile(path.Join(dir, de.Name(), "rp_filter"))
+		if err != nil {
+			continue
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(string(b)))
+		if err != nil {
+			continue
+		}
+		switch de.Name() {
+		case "all":
+			all = v
+		case "default", "lo":
+		default:
+			ifaces[de.Name()] = v
+		}
+	}
+	return strictRPFilterInterface(all, ifaces)
+}
+
+// strictRPFilterInterface returns the first interface name, in sorted order,
+// whose effective rp_filter mode is strict, given the value of the "all"
+// setting and the per-interface values. It returns "all" if the "all"
+// setting is strict and there are interfaces, or "" if none are strict.
+func strictRPFilterInterface(all int, ifaces map[string]int) string {
+	for _, name := range slices.Sorted(maps.Keys(ifaces)) {
+		// The kernel uses the maximum of the two, so a strict
+		// interface value is overridden by a loose "all".
+		if max(all, ifaces[name]) == 1 {
+			if all == 1 {
+				return "all"
+			}
+			return name
+		}
+	}
+	return ""
+}
+
+func desktopSandboxLinux() string {
+	if os.Getenv("SNAP") != "" && os.Getenv("SNAP_NAME") != "" {
+		return "snap"
+	}
+	if os.Getenv("FLATPAK_ID") != "" {
+		return "flatpak"
+	}
+	if _, err := os.Stat("/.flatpak-info"); err == nil {
+		return "flatpak"
+	}
+	return ""
+}
+
+// ARP hardware types from linux/if_arp.h.
+const (
+	arphrdEther = 1
+	arphrdRawIP = 519 // used by cellular modems
+	arphrdNone  = 0xfffe
+)
+
+// interfaceTypeLinux classifies the named interface from sysfs, returning ""
+// if it can't tell.
+func interfaceTypeLinux(name string) string {
+	dir := path.Join("/sys/class/net", name)
+	uevent, _ := os.ReadFile(path.Join(dir, "uevent"))
+	for line := range strings.Lines(string(uevent)) {
+		switch strings.TrimSpace(line) {
+		case "DEVTYPE=wlan":
+			return "wifi"
+		case "DEVTYPE=wwan":
+			return "cellular"
+		case "DEVTYPE=bridge", "DEVTYPE=vlan", "DEVTYPE=macvlan", "DEVTYPE=bond":
+			return "virtual"
+		case "DEVTYPE=wireguard":
+			return "tun"
+		}
+	}
+	if _, err := os.Stat(path.Join(dir, "wireless")); err == nil {
+		return "wifi"
+	}
+	b, err := os.ReadFile(path.Join(dir, "type"))
+	if err != nil {
+		return ""
+	}
+	switch typ, _ := strconv.Atoi(strings.TrimSpace(string(b))); typ {
+	case arphrdNone:
+		return "tun"
+	case arphrdRawIP:
+		return "cellular"
+	case arphrdEther:
+		// Ethernet-like interfaces without a backing device are
+		// software ones: veth pairs, TAP devices, dummies and so on.
+		if _, err := os.Stat(path.Join(dir, "device")); err != nil {
+			return "virtual"
+		}
+		return "ethernet"
+	}
+	return ""
+}
+
+// coreDumpDirFromPattern returns the directory where core dumps end up, given
+// the kernel.core_pattern sysctl value and the process's working directory.
+func coreDumpDirFromPattern(pattern, cwd string) string {
+	if handler, ok := strings.CutPrefix(pattern, "|"); ok {
+		switch {
+		case strings.Contains(handler, "systemd-coredump"):
+			return "/var/lib/systemd/coredump"
+		case strings.Contains(handler, "apport"):
+			return "/var/crash"
+		}
+		return "" // some other handler; no telling where it writes
+	}
+	if pattern == "" {
+		return ""
+	}
+	if !path.IsAbs(pattern) {
+		return cwd
+	}
+	return path.Dir(pattern)
+}
+
+// ethtoolCryptoOffloadFeatures are the "ethtool -k" features that indicate
+// hardware crypto offload.
+var ethtoolCryptoOffloadFeatures = []string{
+	"esp-hw-offload",
+	"tls-hw-tx-offload",
+	"tls-hw-rx-offload",
+	"macsec-hw-offload",
+}
+
+// hasEthtoolCryptoOffload reports whether the output of "ethtool -k", which
+// has lines like "esp-hw-offload: on [fixed]", has any of
+// ethtoolCryptoOffloadFeatures on.
+func hasEthtoolCryptoOffload(out string) bool {
+	for line := range strings.Lines(out) {
+		name, state, ok := strings.Cut(strings.TrimSpace(line), ": ")
+		if ok && slices.Contains(ethtoolCryptoOffloadFeatures, name) && strings.HasPrefix(state, "on") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMeminfoSwapTotal returns the SwapTotal from the contents of
+// /proc/meminfo, in bytes.
+func parseMeminfoSwapTotal(meminfo string) (bytes uint64, ok bool) {
+	for line := range strings.Lines(meminfo) {
+		v, found := strings.CutPrefix(line, "SwapTotal:")
+		if !found {
+			continue
+		}
+		kb, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(v), " kB"), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return kb * 1024, true
+	}
+	return 0, false
+}
@@ -0,0 +1,248 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bufio"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseCgroupV2CPUMax(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   float64
+		wantOK bool
+	}{
+		{"max 100000\n", 0, false},
+		{"200000 100000\n", 2, true},
+		{"50000 100000", 0.5, true},
+		{"", 0, false},
+		{"garbage", 0, false},
+		{"-5 100000", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseCgroupV2CPUMax(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseCgroupV2CPUMax(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestReadKeyValueFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ufw")
+	const contents = `# /etc/default/ufw
+IPV6=yes
+DEFAULT_INPUT_POLICY="DROP"
+  DEFAULT_OUTPUT_POLICY = 'ACCEPT'
+not a key value line
+`
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got := readKeyValueFile(path)
+	want := map[string]string{
+		"IPV6":                  "yes",
+		"DEFAULT_INPUT_POLICY":  "DROP",
+		"DEFAULT_OUTPUT_POLICY": "ACCEPT",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("readKeyValueFile = %v; want %v", got, want)
+	}
+}
+
+func TestStrictRPFilterInterface(t *testing.T) {
+	tests := []struct {
+		name   string
+		all    int
+		ifaces map[string]int
+		want   string
+	}{
+		{"none", 0, map[string]int{"eth0": 0, "wlan0": 2}, ""},
+		{"iface", 0, map[string]int{"eth0": 0, "wlan0": 1}, "wlan0"},
+		{"sorted", 0, map[string]int{"wlan0": 1, "eth0": 1}, "eth0"},
+		{"all", 1, map[string]int{"eth0": 0}, "all"},
+		{"loose_all_overrides", 2, map[string]int{"eth0": 1}, ""},
+		{"no_ifaces", 1, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strictRPFilterInterface(tt.all, tt.ifaces); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoreDumpDirFromPattern(t *testing.T) {
+	tests := []struct {
+		pattern, want string
+	}{
+		{"|/usr/lib/systemd/systemd-coredump %P %u %g %s %t %c %h", "/var/lib/systemd/coredump"},
+		{"|/usr/share/apport/apport -p%p -s%s -c%c -d%d -P%P -u%u -g%g -- %E", "/var/crash"},
+		{"|/usr/local/bin/handler %p", ""},
+		{"core", "/work"},
+		{"/var/cores/core.%e.%p", "/var/cores"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := coreDumpDirFromPattern(tt.pattern, "/work"); got != tt.want {
+			t.Errorf("coreDumpDirFromPattern(%q) = %q; want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestCanLaunchBrowser(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		path []string
+		want bool
+	}{
+		{"headless", nil, []string{"xdg-open"}, false},
+		{"browser-env", map[string]string{"BROWSER": "w3m"}, nil, true},
+		{"x11", map[string]string{"DISPLAY": ":0"}, []string{"xdg-open"}, true},
+		{"wayland", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, []string{"x-www-browser"}, true},
+		{"display-no-launcher", map[string]string{"DISPLAY": ":0"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(k string) string { return tt.env[k] }
+			lookPath := func(name string) (string, error) {
+				if slices.Contains(tt.path, name) {
+					return "/usr/bin/" + name, nil
+				}
+				return "", os.ErrNotExist
+			}
+			if got := canLaunchBrowser(getenv, lookPath); got != tt.want {
+				t.Errorf("canLaunchBrowser = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasClipboardTool(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		path []string
+		want bool
+	}{
+		{"headless", nil, []string{"xclip", "wl-copy"}, false},
+		{"x11-xclip", map[string]string{"DISPLAY": ":0"}, []string{"xclip"}, true},
+		{"x11-xsel", map[string]string{"DISPLAY": ":0"}, []string{"xsel"}, true},
+		{"x11-only-wl-copy", map[string]string{"DISPLAY": ":0"}, []string{"wl-copy"}, false},
+		{"wayland", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, []string{"wl-copy"}, true},
+		{"no-tools", map[string]string{"DISPLAY": ":0", "WAYLAND_DISPLAY": "wayland-0"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(k string) string { return tt.env[k] }
+			lookPath := func(name string) (string, error) {
+				if slices.Contains(tt.path, name) {
+					return "/usr/bin/" + name, nil
+				}
+				return "", os.ErrNotExist
+			}
+			if got := hasClipboardTool(getenv, lookPath); got != tt.want {
+				t.Errorf("hasClipboardTool = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProcStatusSeccomp(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{"Name:\ttailscaled\nSeccomp:\t0\nSeccomp_filters:\t0\n", "disabled"},
+		{"Name:\ttailscaled\nSeccomp:\t2\nSeccomp_filters:\t1\n", "filter"},
+		{"Seccomp:\t1\n", "strict"},
+		{"Name:\ttailscaled\nUmask:\t0022\n", ""},
+	}
+	for _, tt := range tests {
+		got := parseProcStatusSeccomp(bufio.NewScanner(strings.NewReader(tt.status)))
+		if got != tt.want {
+			t.Errorf("parseProcStatusSeccomp(%q) = %q; want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestCmdlineDisablesIPv6(t *testing.T) {
+	tests := []struct {
+		cmdline string
+		want    bool
+	}{
+		{"BOOT_IMAGE=/vmlinuz-6.8.0 root=UUID=1234 ro quiet splash", false},
+		{"root=/dev/sda1 ro ipv6.disable=1\n", true},
+		{"root=/dev/sda1 ro ipv6.disable=0", false},
+		{"root=/dev/sda1 ro ipv6.disable_ipv6=1", false},
+	}
+	for _, tt := range tests {
+		if got := cmdlineDisablesIPv6(tt.cmdline); got != tt.want {
+			t.Errorf("cmdlineDisablesIPv6(%q) = %v; want %v", tt.cmdline, got, tt.want)
+		}
+	}
+}
+
+func TestIsMinimalBootCmdline(t *testing.T) {
+	tests := []struct {
+		cmdline string
+		want    bool
+	}{
+		{"BOOT_IMAGE=/vmlinuz-6.8.0 root=UUID=1234 ro quiet splash", false},
+		{"BOOT_IMAGE=/vmlinuz-6.8.0 root=UUID=1234 ro single", true},
+		{"root=/dev/sda1 ro systemd.unit=rescue.target", true},
+		{"root=/dev/sda1 ro systemd.unit=emergency.target\n", true},
+		{"root=/dev/sda1 ro 1", true},
+		{"root=/dev/sda1 console=ttyS1", false},
+	}
+	for _, tt := range tests {
+		if got := isMinimalBootCmdline(tt.cmdline); got != tt.want {
+			t.Errorf("isMinimalBootCmdline(%q) = %v; want %v", tt.cmdline, got, tt.want)
+		}
+	}
+}
+
+func TestHasEthtoolCryptoOffload(t *testing.T) {
+	const without = `Features for eth0:
+rx-checksumming: on
+tx-checksumming: on
+esp-hw-offload: off [fixed]
+tls-hw-tx-offload: off [fixed]
+macsec-hw-offload: off [fixed]
+`
+	if hasEthtoolCryptoOffload(without) {
+		t.Error("got true for NIC without offload")
+	}
+	const with = `Features for eth0:
+rx-checksumming: on
+esp-hw-offload: on
+tls-hw-rx-offload: off
+`
+	if !hasEthtoolCryptoOffload(with) {
+		t.Error("got false for NIC with ESP offload")
+	}
+}
+
+func TestParseMeminfoSwapTotal(t *testing.T) {
+	const meminfo = `MemTotal:       16303452 kB
+MemFree:         1234567 kB
+SwapCached:            0 kB
+SwapTotal:       2097148 kB
+SwapFree:        2097148 kB
+`
+	got, ok := parseMeminfoSwapTotal(meminfo)
+	if want := uint64(2097148 * 1024); got != want || !ok {
+		t.Errorf("got (%v, %v); want (%v, true)", got, ok, want)
+	}
+	if _, ok := parseMeminfoSwapTotal("MemTotal: 1 kB\n"); ok {
+		t.Error("got ok for meminfo without SwapTotal")
+	}
+}
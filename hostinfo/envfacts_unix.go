@@ -0,0 +1,18 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build linux || darwin
+
+package hostinfo
+
+import "golang.org/x/sys/unix"
+
+// coreRlimitAllowsDumps reports whether the process's RLIMIT_CORE soft limit
+// is non-zero, and whether it could be read.
+func coreRlimitAllowsDumps() (allowed, ok bool) {
+	var lim unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_CORE, &lim); err != nil {
+		return false, false
+	}
+	return lim.Cur != 0, true
+}
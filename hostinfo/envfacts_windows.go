@@ -0,0 +1,309 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"tailscale.com/util/winutil"
+)
+
+func init() {
+	hasStableMachineID = hasMachineGUIDWindows
+	ipv6TempAddrs = ipv6TempAddrsWindows
+	crashDumpDir = crashDumpDirWindows
+	coreDumpsEnabled = coreDumpsEnabledWindows
+	swapTotal = pageFileTotalWindows
+	minimalBootMode = func() bool {
+		// The SafeBoot\Option key only exists when booted into Safe Mode.
+		k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\SafeBoot\Option`, registry.QUERY_VALUE)
+		if err != nil {
+			return false
+		}
+		defer k.Close()
+		v, _, err := k.GetIntegerValue("OptionValue")
+		return err == nil && v != 0
+	}
+	addressingMode = addressingModeWindows
+	defaultGateway = defaultGatewayWindows
+	hasBrowser = hasDesktopSessionWindows
+	dnsSearchDomains = dnsSearchDomainsWindows
+	dnsResolverAddr = dnsResolverAddrWindows
+	hasClipboard = hasDesktopSessionWindows
+	ipv6Disabled = func() (disabled bool, reason string) {
+		k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\Tcpip6\Parameters`, registry.QUERY_VALUE)
+		if err != nil {
+			return false, ""
+		}
+		defer k.Close()
+		// Bit 0x10 disables IPv6 on all non-tunnel interfaces. See
+		// https://learn.microsoft.com/en-us/troubleshoot/windows-server/networking/configure-ipv6-in-windows
+		v, _, err := k.GetIntegerValue("DisabledComponents")
+		if err != nil || v&0x10 == 0 {
+			return false, ""
+		}
+		return true, fmt.Sprintf("Tcpip6 DisabledComponents=%#x", v)
+	}
+	ntpSynced = func() (synced, ok bool) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "w32tm", "/query", "/status").Output()
+		if err != nil {
+			return false, false
+		}
+		return parseW32tmStatus(out)
+	}
+	systemProxy = func() string {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "netsh", "winhttp", "show", "proxy").Output()
+		if err != nil {
+			return ""
+		}
+		return parseNetshWinHTTPProxy(out)
+	}
+	systemPAC = pacURLWindows
+}
+
+// pacURLWindows returns the AutoConfigURL of the group policy and then the
+// current user's Internet Settings.
+func pacURLWindows() (bool, string) {
+	for _, loc := range []struct {
+		root registry.Key
+		path string
+	}{
+		{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Microsoft\Windows\CurrentVersion\Internet Settings`},
+		{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Internet Settings`},
+	} {
+		k, err := registry.OpenKey(loc.root, loc.path, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		u, _, err := k.GetStringValue("AutoConfigURL")
+		k.Close()
+		if err == nil && u != "" {
+			return true, u
+		}
+	}
+	return false, ""
+}
+
+func hasMachineGUIDWindows() bool {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Cryptography`, registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return false
+	}
+	defer k.Close()
+	guid, _, err := k.GetStringValue("MachineGuid")
+	return err == nil && guid != ""
+}
+
+func ipv6TempAddrsWindows() (enabled, ok bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "netsh", "interface", "ipv6", "show", "privacy").Output()
+	if err != nil {
+		return false, false
+	}
+	// The output contains a line like:
+	//   Use Temporary Addresses             : enabled
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		k, v, found := strings.Cut(sc.Text(), ":")
+		if found && strings.TrimSpace(k) == "Use Temporary Addresses" {
+			switch strings.TrimSpace(v) {
+			case "enabled", "always":
+				return true, true
+			case "disabled":
+				return false, true
+			}
+		}
+	}
+	return false, false
+}
+
+// werLocalDumpsKey is the registry key configuring Windows Error Reporting's
+// user-mode crash dumps. See
+// https://learn.microsoft.com/en-us/windows/win32/wer/collecting-user-mode-dumps
+const werLocalDumpsKey = `SOFTWARE\Microsoft\Windows\Windows Error Reporting\LocalDumps`
+
+func crashDumpDirWindows() string {
+	// Per-application settings take precedence over the global ones.
+	var keys []string
+	if exe, err := os.Executable(); err == nil {
+		keys = append(keys, werLocalDumpsKey+`\`+filepath.Base(exe))
+	}
+	keys = append(keys, werLocalDumpsKey)
+
+	configured := false
+	for _, name := range keys {
+		k, err := registry.OpenKey(registry.LOCAL_MACHINE, name, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		configured = true
+		dir, _, err := k.GetStringValue("DumpFolder")
+		k.Close()
+		if err == nil && dir != "" {
+			if expanded, err := registry.ExpandString(dir); err == nil {
+				dir = expanded
+			}
+			return dir
+		}
+	}
+	if configured {
+		if d := os.Getenv("LOCALAPPDATA"); d != "" {
+			return filepath.Join(d, "CrashDumps")
+		}
+		return ""
+	}
+	if d := os.Getenv("ProgramData"); d != "" {
+		return filepath.Join(d, `Microsoft\Windows\WER`)
+	}
+	return ""
+}
+
+func coreDumpsEnabledWindows() (enabled, ok bool) {
+	if k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\Windows Error Reporting`, registry.QUERY_VALUE); err == nil {
+		disabled, _, err := k.GetIntegerValue("Disabled")
+		k.Close()
+		if err == nil && disabled != 0 {
+			return false, true
+		}
+	}
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, werLocalDumpsKey, registry.QUERY_VALUE)
+	if err != nil {
+		return false, true
+	}
+	k.Close()
+	return true, true
+}
+
+func pageFileTotalWindows() (uint64, bool) {
+	ms, err := winutil.GlobalMemoryStatus()
+	if err != nil {
+		return 0, false
+	}
+	// TotalPageFile is the commit limit: physical memory plus page files.
+	if ms.TotalPageFile < ms.TotalPhys {
+		return 0, true
+	}
+	return ms.TotalPageFile - ms.TotalPhys, true
+}
+
+// primaryAdapterWindows returns the primary network adapter: the lowest
+// metric one that's up and has a default gateway. It returns nil if there's
+// none.
+func primaryAdapterWindows() *windows.IpAdapterAddresses {
+	const flags = windows.GAA_FLAG_INCLUDE_GATEWAYS | windows.GAA_FLAG_SKIP_ANYCAST | windows.GAA_FLAG_SKIP_MULTICAST
+	aa, err := winutil.GetAdaptersAddresses(windows.AF_INET, flags)
+	if err != nil {
+		return nil
+	}
+
+	var primary *windows.IpAdapterAddresses
+	for a := aa; a != nil; a = a.Next {
+		if a.OperStatus != windows.IfOperStatusUp || a.IfType == windows.IF_TYPE_SOFTWARE_LOOPBACK || a.FirstGatewayAddress == nil {
+			continue
+		}
+		if primary == nil || a.Ipv4Metric < primary.Ipv4Metric {
+			primary = a
+		}
+	}
+	return primary
+}
+
+func addressingModeWindows() string {
+	primary := primaryAdapterWindows()
+	if primary == nil {
+		return ""
+	}
+	var dynamic, static int
+	for u := primary.FirstUnicastAddress; u != nil; u = u.Next {
+		switch u.PrefixOrigin {
+		case windows.IpPrefixOriginDhcp:
+			dynamic++
+		case windows.IpPrefixOriginManual:
+			static++
+		}
+	}
+	return classifyAddressing(dynamic, static)
+}
+
+func defaultGatewayWindows() netip.Addr {
+	primary := primaryAdapterWindows()
+	if primary == nil {
+		return netip.Addr{}
+	}
+	sa, err := primary.FirstGatewayAddress.Address.Sockaddr.Sockaddr()
+	if err != nil {
+		return netip.Addr{}
+	}
+	if sa4, ok := sa.(*syscall.SockaddrInet4); ok {
+		return netip.AddrFrom4(sa4.Addr)
+	}
+	return netip.Addr{}
+}
+
+func dnsResolverAddrWindows() netip.Addr {
+	primary := primaryAdapterWindows()
+	if primary == nil || primary.FirstDnsServerAddress == nil {
+		return netip.Addr{}
+	}
+	sa, err := primary.FirstDnsServerAddress.Address.Sockaddr.Sockaddr()
+	if err != nil {
+		return netip.Addr{}
+	}
+	switch sa := sa.(type) {
+	case *syscall.SockaddrInet4:
+		return netip.AddrFrom4(sa.Addr)
+	case *syscall.SockaddrInet6:
+		return netip.AddrFrom16(sa.Addr).Unmap()
+	}
+	return netip.Addr{}
+}
+
+// hasDesktopSessionWindows reports whether the process runs in an
+// interactive session, rather than session 0, where services run.
+func hasDesktopSessionWindows() bool {
+	var session uint32
+	err := windows.ProcessIdToSessionId(windows.GetCurrentProcessId(), &session)
+	return err == nil && session != 0
+}
+
+func dnsSearchDomainsWindows() []string {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\Tcpip\Parameters`, registry.QUERY_VALUE)
+	if err != nil {
+		return nil
+	}
+	defer k.Close()
+	// SearchList is a comma-separated list that, when set, replaces the
+	// primary DNS suffix as the search list.
+	var domains []string
+	if v, _, err := k.GetStringValue("SearchList"); err == nil {
+		for d := range strings.SplitSeq(v, ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				domains = append(domains, d)
+			}
+		}
+	}
+	if len(domains) == 0 {
+		if v, _, err := k.GetStringValue("Domain"); err == nil && v != "" {
+			domains = []string{v}
+		}
+	}
+	return domains
+}
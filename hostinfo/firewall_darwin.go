@@ -0,0 +1,34 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"time"
+)
+
+func init() {
+	hostFirewallBlocking = hostFirewallBlockingDarwin
+}
+
+// hostFirewallBlockingDarwin reports whether the macOS application firewall
+// is set to block all incoming connections. Its per-application mode lets
+// signed apps such as Tailscale accept connections, and pf is not inspected.
+func hostFirewallBlockingDarwin() (bool, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "/usr/libexec/ApplicationFirewall/socketfilterfw", "--getblockall").Output()
+	if err != nil {
+		return false, "undetermined: can't query the application firewall"
+	}
+	// The output is of the form "Firewall is set to block all non-essential
+	// incoming connections" or "Block all INCOMING connections: enabled",
+	// depending on the macOS version, or the same with "disabled"/"off".
+	if bytes.Contains(out, []byte("block all non-essential")) || bytes.Contains(bytes.ToLower(out), []byte("connections: enabled")) {
+		return true, "the application firewall blocks all incoming connections"
+	}
+	return false, "the application firewall allows incoming connections for signed apps"
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"tailscale.com/util/lineiter"
+)
+
+func init() {
+	hostFirewallBlocking = hostFirewallBlockingLinux
+}
+
+func hostFirewallBlockingLinux() (bool, string) {
+	if blocking, reason, ok := ufwBlocking(); ok {
+		return blocking, reason
+	}
+	if blocking, reason, ok := firewalldBlocking(); ok {
+		return blocking, reason
+	}
+	return false, "undetermined: neither ufw nor firewalld is active"
+}
+
+// ufwBlocking inspects ufw's configuration files, which (unlike "ufw status")
+// doesn't require root. The ok result reports whether ufw is enabled.
+func ufwBlocking() (blocking bool, reason string, ok bool) {
+	if readKeyValueFile("/etc/ufw/ufw.conf")["ENABLED"] != "yes" {
+		return false, "", false
+	}
+	policy := readKeyValueFile("/etc/default/ufw")["DEFAULT_INPUT_POLICY"]
+	if policy != "DROP" && policy != "REJECT" {
+		return false, fmt.Sprintf("ufw is enabled with default incoming policy %q", policy), true
+	}
+	rules, _ := os.ReadFile("/etc/ufw/user.rules")
+	if bytes.Contains(rules, []byte(strconv.Itoa(defaultWireGuardPort))) || bytes.Contains(rules, []byte("tailscale0")) {
+		return false, "ufw is enabled with a rule allowing Tailscale", true
+	}
+	return true, fmt.Sprintf("ufw is enabled with default incoming policy %s and no rule for UDP port %d", policy, defaultWireGuardPort), true
+}
+
+// firewalldBlocking asks firewall-cmd about the default zone. The ok result
+// reports whether firewalld is running.
+func firewalldBlocking() (blocking bool, reason string, ok bool) {
+	if _, err := exec.LookPath("firewall-cmd"); err != nil {
+		return false, "", false
+	}
+	if firewallCmd("--state") != "running" {
+		return false, "", false
+	}
+	want := fmt.Sprintf("%d/udp", defaultWireGuardPort)
+	for p := range strings.FieldsSeq(firewallCmd("--list-ports")) {
+		if p == want {
+			return false, "firewalld allows " + want, true
+		}
+	}
+	zone := firewallCmd("--get-default-zone")
+	if zone == "trusted" {
+		return false, "firewalld's default zone is trusted", true
+	}
+	return true, fmt.Sprintf("firewalld is running and its default zone %q doesn't allow %s", zone, want), true
+}
+
+func firewallCmd(arg string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, _ := exec.CommandContext(ctx, "firewall-cmd", arg).Output()
+	return string(bytes.TrimSpace(out))
+}
+
+// readKeyValueFile reads a shell-style file of KEY=VALUE lines, such as
+// /etc/default files, ignoring comments and stripping quotes from values.
+func readKeyValueFile(path string) map[string]string {
+	m := map[string]string{}
+	for lr := range lineiter.File(path) {
+		line, err := lr.Value()
+		if err != nil {
+			break
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		k, v, ok := bytes.Cut(line, []byte{'='})
+		if !ok {
+			continue
+		}
+		m[string(bytes.TrimSpace(k))] = strings.Trim(string(bytes.TrimSpace(v)), `"'`)
+	}
+	return m
+}
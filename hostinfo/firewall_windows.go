@@ -0,0 +1,40 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+func init() {
+	hostFirewallBlocking = hostFirewallBlockingWindows
+}
+
+// hostFirewallBlockingWindows reports whether any Windows Defender Firewall
+// profile is enabled with "Block all incoming connections, including those
+// in the list of allowed apps", which overrides the rules that the Tailscale
+// installer adds.
+func hostFirewallBlockingWindows() (bool, string) {
+	const policyKey = `SYSTEM\CurrentControlSet\Services\SharedAccess\Parameters\FirewallPolicy\`
+	inspected := false
+	for _, profile := range []string{"DomainProfile", "StandardProfile", "PublicProfile"} {
+		k, err := registry.OpenKey(registry.LOCAL_MACHINE, policyKey+profile, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		inspected = true
+		enabled, _, err1 := k.GetIntegerValue("EnableFirewall")
+		blockAll, _, err2 := k.GetIntegerValue("DoNotAllowExceptions")
+		k.Close()
+		if err1 == nil && err2 == nil && enabled == 1 && blockAll == 1 {
+			return true, fmt.Sprintf("Windows Defender Firewall %s blocks all incoming connections, including allowed apps", profile)
+		}
+	}
+	if !inspected {
+		return false, "undetermined: can't read Windows Defender Firewall policy"
+	}
+	return false, "Windows Defender Firewall allows incoming connections for allowed apps"
+}
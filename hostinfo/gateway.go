@@ -0,0 +1,46 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// defaultGateway, if non-nil, returns the gateway of the primary IPv4
+// default route, or the zero value if there's none.
+var defaultGateway func() netip.Addr
+
+// neighborResolved, if non-nil, reports whether the system's ARP table has
+// resolved ip's hardware address.
+var neighborResolved func(ip netip.Addr) bool
+
+// DefaultGateway returns the gateway of the node's primary IPv4 default
+// route, or the zero value if there's none, and whether finding it is
+// supported, which it is on Linux, macOS and Windows.
+func DefaultGateway() (gw netip.Addr, ok bool) {
+	if defaultGateway == nil {
+		return netip.Addr{}, false
+	}
+	return defaultGateway(), true
+}
+
+// NeighborResolved reports whether the system's ARP table has resolved ip's
+// hardware address. It's only known on Linux, where the table is read from
+// /proc/net/arp, and reports false elsewhere.
+func NeighborResolved(ip netip.Addr) bool {
+	return neighborResolved != nil && neighborResolved(ip)
+}
+
+// parseRouteGetGateway returns the gateway in the output of macOS's
+// "route -n get default", or the zero value if there's none.
+func parseRouteGetGateway(out []byte) netip.Addr {
+	for line := range strings.Lines(string(out)) {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "gateway:"); ok {
+			gw, _ := netip.ParseAddr(strings.TrimSpace(v))
+			return gw
+		}
+	}
+	return netip.Addr{}
+}
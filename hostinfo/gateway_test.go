@@ -0,0 +1,25 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestParseRouteGetGateway(t *testing.T) {
+	const out = `   route to: default
+destination: default
+       mask: default
+    gateway: 192.168.1.1
+  interface: en0
+      flags: <UP,GATEWAY,DONE,STATIC,PRCLONING,GLOBAL>
+`
+	if got, want := parseRouteGetGateway([]byte(out)), netip.MustParseAddr("192.168.1.1"); got != want {
+		t.Errorf("parseRouteGetGateway = %v; want %v", got, want)
+	}
+	if got := parseRouteGetGateway([]byte("route: writing to routing socket: not in table\n")); got.IsValid() {
+		t.Errorf("parseRouteGetGateway without a route = %v; want zero", got)
+	}
+}
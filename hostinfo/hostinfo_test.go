@@ -5,7 +5,9 @@
 
 import (
 	"encoding/json"
+	"net"
 	"os"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -22,6 +24,179 @@ func TestNew(t *testing.T) {
 	t.Logf("Got: %s", j)
 }
 
+func TestGetEnvironmentFacts(t *testing.T) {
+	j, err := json.MarshalIndent(GetEnvironmentFacts(), "  ", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("Got: %s", j)
+}
+
+func TestCPUsToGOMAXPROCS(t *testing.T) {
+	ncpu := runtime.NumCPU()
+	tests := []struct {
+		cpus float64
+		want int
+	}{
+		{0.1, 1},
+		{1, 1},
+		{1.5, min(2, ncpu)},
+		{float64(ncpu) + 8, ncpu},
+	}
+	for _, tt := range tests {
+		if got := cpusToGOMAXPROCS(tt.cpus); got != tt.want {
+			t.Errorf("cpusToGOMAXPROCS(%v) = %v; want %v", tt.cpus, got, tt.want)
+		}
+	}
+}
+
+func TestIsGloballyAdministeredMAC(t *testing.T) {
+	tests := []struct {
+		mac  string
+		want bool
+	}{
+		{"00:1a:2b:3c:4d:5e", true},
+		{"00:00:00:00:00:00", false},
+		{"02:42:ac:11:00:02", false}, // locally administered (Docker)
+		{"01:00:5e:00:00:01", false}, // multicast
+	}
+	for _, tt := range tests {
+		mac, err := net.ParseMAC(tt.mac)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := isGloballyAdministeredMAC(mac); got != tt.want {
+			t.Errorf("isGloballyAdministeredMAC(%v) = %v; want %v", tt.mac, got, tt.want)
+		}
+	}
+}
+
+func TestIsRestrictedShell(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  bool
+	}{
+		{"/bin/rbash", true},
+		{"/usr/bin/lshell", true},
+		{"/bin/bash", false},
+		{"/bin/sh", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isRestrictedShell(tt.shell); got != tt.want {
+			t.Errorf("isRestrictedShell(%q) = %v; want %v", tt.shell, got, tt.want)
+		}
+	}
+}
+
+func TestSplitDNSFeasibility(t *testing.T) {
+	tests := []struct {
+		goos, owner string
+		want        bool
+	}{
+		{"windows", "", true},
+		{"darwin", "", true},
+		{"linux", "systemd-resolved", true},
+		{"linux", "NetworkManager", false},
+		{"linux", "resolvconf", false},
+		{"linux", "", false},
+		{"freebsd", "", false},
+		{"plan9", "", false},
+	}
+	for _, tt := range tests {
+		got, reason := splitDNSFeasibility(tt.goos, tt.owner)
+		if got != tt.want {
+			t.Errorf("splitDNSFeasibility(%q, %q) = %v; want %v", tt.goos, tt.owner, got, tt.want)
+		}
+		if got != (reason == "") {
+			t.Errorf("splitDNSFeasibility(%q, %q) reason = %q; want empty only if feasible", tt.goos, tt.owner, reason)
+		}
+	}
+}
+
+func TestInterfaceTypeFromName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"tailscale0", "tun"},
+		{"utun3", "tun"},
+		{"wlp2s0", "wifi"},
+		{"Wi-Fi", "wifi"},
+		{"rmnet_data0", "cellular"},
+		{"pdp_ip0", "cellular"},
+		{"docker0", "virtual"},
+		{"vEthernet (WSL)", "virtual"},
+		{"br-1234abcd", "virtual"},
+		{"eth0", "ethernet"},
+		{"Ethernet 2", "ethernet"},
+		{"enp3s0", "ethernet"},
+		{"en0", "unknown"},
+		{"xyz0", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := interfaceTypeFromName(tt.name); got != tt.want {
+			t.Errorf("interfaceTypeFromName(%q) = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCPUList(t *testing.T) {
+	tests := []struct {
+		cpus []int
+		want string
+	}{
+		{nil, ""},
+		{[]int{0}, "0"},
+		{[]int{0, 1, 2, 3}, "0-3"},
+		{[]int{0, 1, 2, 3, 6}, "0-3,6"},
+		{[]int{1, 3, 5, 6}, "1,3,5-6"},
+	}
+	for _, tt := range tests {
+		if got := formatCPUList(tt.cpus); got != tt.want {
+			t.Errorf("formatCPUList(%v) = %q; want %q", tt.cpus, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyAddressing(t *testing.T) {
+	tests := []struct {
+		dynamic, static int
+		want            string
+	}{
+		{0, 0, "unknown"},
+		{1, 0, "dhcp"},
+		{0, 2, "static"},
+		{1, 1, "mixed"},
+	}
+	for _, tt := range tests {
+		if got := classifyAddressing(tt.dynamic, tt.static); got != tt.want {
+			t.Errorf("classifyAddressing(%d, %d) = %q; want %q", tt.dynamic, tt.static, got, tt.want)
+		}
+	}
+}
+
+func TestIsVPSVendor(t *testing.T) {
+	tests := []struct {
+		vendor string
+		want   bool
+	}{
+		{"DigitalOcean", true},
+		{"Hetzner", true},
+		{"Linode", true},
+		{"Amazon EC2", true},
+		{"QEMU", false},
+		{"VMware, Inc.", false},
+		{"innotek GmbH", false},
+		{"Dell Inc.", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isVPSVendor(tt.vendor); got != tt.want {
+			t.Errorf("isVPSVendor(%q) = %v; want %v", tt.vendor, got, tt.want)
+		}
+	}
+}
+
 func TestOSVersion(t *testing.T) {
 	if osVersion == nil {
 		t.Skip("not available for OS")
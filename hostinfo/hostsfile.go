@@ -0,0 +1,101 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bufio"
+	"bytes"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// HostsFileConflicts returns the entries of the host's hosts file that can
+// interfere with Tailscale: those mapping a name to a Tailscale address (in
+// 100.64.0.0/10 or fd7a:115c:a1e0::/48), which go stale when the node they
+// name changes address or leaves the tailnet, and those for names under
+// MagicDNS's ts.net and beta.tailscale.net domains, which shadow MagicDNS.
+// It returns nil if there are none.
+//
+// The hosts file is /etc/hosts, or %SystemRoot%\System32\drivers\etc\hosts
+// on Windows. Each entry is a line of an IP address followed by one or more
+// names; "#" starts a comment. The section that tailscaled itself maintains
+// in the Windows hosts file for MagicDNS is skipped. Conflicting entries are
+// returned as their address and names separated by single spaces.
+func HostsFileConflicts() []string {
+	b, err := os.ReadFile(hostsFilePath())
+	if err != nil {
+		return nil
+	}
+	return hostsConflicts(b)
+}
+
+// hostsFilePath returns the path of the host's hosts file.
+func hostsFilePath() string {
+	if runtime.GOOS != "windows" {
+		return "/etc/hosts"
+	}
+	root := os.Getenv("SystemRoot")
+	if root == "" {
+		root = `C:\Windows`
+	}
+	return filepath.Join(root, "System32", "drivers", "etc", "hosts")
+}
+
+// hostsConflicts returns the conflicting entries, as HostsFileConflicts
+// documents, of the hosts file contents.
+func hostsConflicts(contents []byte) []string {
+	var conflicts []string
+	inTailscaleSection := false
+	sc := bufio.NewScanner(bytes.NewReader(contents))
+	for sc.Scan() {
+		line, comment, _ := strings.Cut(sc.Text(), "#")
+		switch strings.TrimSpace(comment) {
+		case "TailscaleHostsSectionStart":
+			inTailscaleSection = true
+		case "TailscaleHostsSectionEnd":
+			inTailscaleSection = false
+		}
+		f := strings.Fields(line)
+		if inTailscaleSection || len(f) < 2 {
+			continue
+		}
+		ip, err := netip.ParseAddr(f[0])
+		if err != nil {
+			continue
+		}
+		conflict := isTailscaleIP(ip.Unmap())
+		for _, name := range f[1:] {
+			if shadowsMagicDNS(name) {
+				conflict = true
+			}
+		}
+		if conflict {
+			conflicts = append(conflicts, strings.Join(f, " "))
+		}
+	}
+	return conflicts
+}
+
+// isTailscaleIP reports whether ip is in the ranges Tailscale assigns node
+// addresses from, like tsaddr.IsTailscaleIP, which hostinfo doesn't import
+// to keep its dependencies small: the CGNAT range except ChromeOS's VM
+// range, and Tailscale's IPv6 ULA range.
+func isTailscaleIP(ip netip.Addr) bool {
+	if ip.Is4() {
+		return netip.MustParsePrefix("100.64.0.0/10").Contains(ip) &&
+			!netip.MustParsePrefix("100.115.92.0/23").Contains(ip)
+	}
+	return netip.MustParsePrefix("fd7a:115c:a1e0::/48").Contains(ip)
+}
+
+// shadowsMagicDNS reports whether a hosts file entry for name would take
+// precedence over MagicDNS.
+func shadowsMagicDNS(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	return name == "ts.net" || strings.HasSuffix(name, ".ts.net") ||
+		name == "beta.tailscale.net" || strings.HasSuffix(name, ".beta.tailscale.net")
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHostsConflicts(t *testing.T) {
+	const hosts = `# Static table lookup for hostnames.
+127.0.0.1	localhost
+::1		localhost ip6-localhost
+192.168.1.10	nas nas.lan
+100.101.102.103	laptop	# old tailnet address
+fd7a:115c:a1e0::1234 laptop6
+10.0.0.5	printer.tail1234.ts.net
+10.0.0.6	Router.Beta.Tailscale.Net.
+100.100.100.100
+not-an-ip	foo.ts.net
+
+# TailscaleHostsSectionStart
+# This section contains MagicDNS entries for Tailscale.
+# Do not edit this section manually.
+
+100.64.0.1 peer.tail1234.ts.net
+
+# TailscaleHostsSectionEnd
+100.64.0.2 after-section
+`
+	want := []string{
+		"100.101.102.103 laptop",
+		"fd7a:115c:a1e0::1234 laptop6",
+		"10.0.0.5 printer.tail1234.ts.net",
+		"10.0.0.6 Router.Beta.Tailscale.Net.",
+		"100.64.0.2 after-section",
+	}
+	if got := hostsConflicts([]byte(hosts)); !slices.Equal(got, want) {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if got := hostsConflicts([]byte("127.0.0.1 localhost\n")); got != nil {
+		t.Errorf("clean hosts file: got %q; want nil", got)
+	}
+}
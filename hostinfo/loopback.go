@@ -0,0 +1,62 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"tailscale.com/types/lazy"
+)
+
+var loopbackHealthyCache lazy.SyncValue[bool]
+
+// LoopbackHealthy reports whether the loopback interface works. Without it,
+// the LocalAPI and many other things break in confusing ways; in practice
+// it's only ever missing in misconfigured minimal containers.
+//
+// It probes by listening on a TCP port on 127.0.0.1 and on ::1, connecting to
+// it and accepting the connection. IPv4 loopback must work. IPv6 loopback
+// must work too if ::1 can be listened on at all; a host without IPv6 isn't
+// considered unhealthy. The result is cached.
+func LoopbackHealthy() bool {
+	return loopbackHealthyCache.Get(func() bool {
+		if _, err := probeLoopback("127.0.0.1"); err != nil {
+			return false
+		}
+		listened, err := probeLoopback("::1")
+		return err == nil || !listened
+	})
+}
+
+// probeLoopback listens on a TCP port on ip, connects to it and accepts the
+// connection. It reports whether the listen succeeded, and the first error.
+func probeLoopback(ip string) (listened bool, err error) {
+	ln, err := net.Listen("tcp", net.JoinHostPort(ip, "0"))
+	if err != nil {
+		return false, err
+	}
+	defer ln.Close()
+
+	accepted := make(chan error, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			c.Close()
+		}
+		accepted <- err
+	}()
+	c, err := net.DialTimeout("tcp", ln.Addr().String(), 2*time.Second)
+	if err != nil {
+		return true, err
+	}
+	c.Close()
+	select {
+	case err := <-accepted:
+		return true, err
+	case <-time.After(2 * time.Second):
+		return true, errors.New("timeout accepting loopback connection")
+	}
+}
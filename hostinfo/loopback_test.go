@@ -0,0 +1,16 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import "testing"
+
+func TestProbeLoopback(t *testing.T) {
+	listened, err := probeLoopback("127.0.0.1")
+	if !listened || err != nil {
+		t.Errorf("probeLoopback(127.0.0.1) = (%v, %v); want (true, nil)", listened, err)
+	}
+	if listened, err := probeLoopback("192.0.2.1"); listened || err == nil {
+		t.Errorf("probeLoopback(192.0.2.1) = (%v, %v); want (false, non-nil)", listened, err)
+	}
+}
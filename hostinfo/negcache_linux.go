@@ -0,0 +1,120 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// negativeCacheTTLThreshold is the negative caching TTL, in seconds, above
+// which AggressiveNegativeCaching reports it.
+const negativeCacheTTLThreshold = 60
+
+func aggressiveNegativeCachingLinux() (bool, string) {
+	var running []string
+	if processNames != nil {
+		running = processNames()
+	}
+	if slices.Contains(running, "dnsmasq") {
+		conf := readConfFiles("/etc/dnsmasq.conf", "/etc/dnsmasq.d/*", "/etc/NetworkManager/dnsmasq.d/*")
+		if ttl, ok := parseDnsmasqNegTTL(conf); ok && ttl > negativeCacheTTLThreshold {
+			return true, fmt.Sprintf("dnsmasq neg-ttl=%d", ttl)
+		}
+	}
+	if slices.Contains(running, "unbound") {
+		conf := readConfFiles("/etc/unbound/unbound.conf", "/etc/unbound/unbound.conf.d/*.conf")
+		if ttl := parseUnboundNegTTL(conf); ttl > negativeCacheTTLThreshold {
+			return true, fmt.Sprintf("unbound cache-max-negative-ttl: %d", ttl)
+		}
+	}
+	if slices.Contains(running, "nscd") {
+		conf, _ := os.ReadFile("/etc/nscd.conf")
+		if ttl, ok := parseNscdHostsNegTTL(conf); ok && ttl > negativeCacheTTLThreshold {
+			return true, fmt.Sprintf("nscd negative-time-to-live hosts %d", ttl)
+		}
+	}
+	return false, ""
+}
+
+// readConfFiles returns the concatenated contents of the files matching the
+// glob patterns, in order.
+func readConfFiles(patterns ...string) []byte {
+	var buf bytes.Buffer
+	for _, pat := range patterns {
+		files, _ := filepath.Glob(pat)
+		for _, f := range files {
+			if b, err := os.ReadFile(f); err == nil {
+				buf.Write(b)
+				buf.WriteByte('\n')
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// parseDnsmasqNegTTL returns the neg-ttl option from the dnsmasq
+// configuration conf. dnsmasq only caches negative answers without a SOA
+// record if it's set; with one, it uses the SOA's TTL, which isn't
+// configurable. It reports ok=false if the option isn't set or negative
+// caching is turned off with no-negcache.
+func parseDnsmasqNegTTL(conf []byte) (ttl int, ok bool) {
+	sc := bufio.NewScanner(bytes.NewReader(conf))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "no-negcache" {
+			return 0, false
+		}
+		if v, found := strings.CutPrefix(line, "neg-ttl="); found {
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				ttl, ok = n, true
+			}
+		}
+	}
+	return ttl, ok
+}
+
+// parseUnboundNegTTL returns the cache-max-negative-ttl setting from the
+// unbound configuration conf, which defaults to 3600 seconds.
+func parseUnboundNegTTL(conf []byte) int {
+	ttl := 3600
+	sc := bufio.NewScanner(bytes.NewReader(conf))
+	for sc.Scan() {
+		if v, found := strings.CutPrefix(strings.TrimSpace(sc.Text()), "cache-max-negative-ttl:"); found {
+			if n, err := strconv.Atoi(strings.Trim(strings.TrimSpace(v), `"`)); err == nil {
+				ttl = n
+			}
+		}
+	}
+	return ttl
+}
+
+// parseNscdHostsNegTTL returns the negative-time-to-live setting of the
+// hosts cache from the nscd configuration conf, which defaults to 20
+// seconds. It reports ok=false if the hosts cache is disabled.
+func parseNscdHostsNegTTL(conf []byte) (ttl int, ok bool) {
+	ttl, ok = 20, true
+	sc := bufio.NewScanner(bytes.NewReader(conf))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) != 3 || f[1] != "hosts" {
+			continue
+		}
+		switch f[0] {
+		case "enable-cache":
+			ok = f[2] == "yes"
+		case "negative-time-to-live":
+			if n, err := strconv.Atoi(f[2]); err == nil {
+				ttl = n
+			}
+		}
+	}
+	return ttl, ok
+}
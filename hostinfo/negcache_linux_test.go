@@ -0,0 +1,61 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import "testing"
+
+func TestParseDnsmasqNegTTL(t *testing.T) {
+	tests := []struct {
+		conf   string
+		ttl    int
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"cache-size=1000\nneg-ttl=3600\n", 3600, true},
+		{"neg-ttl=60\nneg-ttl=600\n", 600, true},
+		{"neg-ttl=3600\nno-negcache\n", 0, false},
+		{"# neg-ttl=3600\n", 0, false},
+	}
+	for _, tt := range tests {
+		ttl, ok := parseDnsmasqNegTTL([]byte(tt.conf))
+		if ttl != tt.ttl || ok != tt.wantOK {
+			t.Errorf("parseDnsmasqNegTTL(%q) = (%v, %v); want (%v, %v)", tt.conf, ttl, ok, tt.ttl, tt.wantOK)
+		}
+	}
+}
+
+func TestParseUnboundNegTTL(t *testing.T) {
+	tests := []struct {
+		conf string
+		want int
+	}{
+		{"server:\n  verbosity: 1\n", 3600},
+		{"server:\n  cache-max-negative-ttl: 30\n", 30},
+		{"server:\n\tcache-max-negative-ttl: \"120\"\n", 120},
+	}
+	for _, tt := range tests {
+		if got := parseUnboundNegTTL([]byte(tt.conf)); got != tt.want {
+			t.Errorf("parseUnboundNegTTL(%q) = %v; want %v", tt.conf, got, tt.want)
+		}
+	}
+}
+
+func TestParseNscdHostsNegTTL(t *testing.T) {
+	tests := []struct {
+		conf   string
+		ttl    int
+		wantOK bool
+	}{
+		{"", 20, true},
+		{"\tenable-cache\t\thosts\t\tyes\n\tnegative-time-to-live\thosts\t\t300\n", 300, true},
+		{"\tnegative-time-to-live\tpasswd\t\t300\n", 20, true},
+		{"\tenable-cache\t\thosts\t\tno\n", 20, false},
+	}
+	for _, tt := range tests {
+		ttl, ok := parseNscdHostsNegTTL([]byte(tt.conf))
+		if ttl != tt.ttl || ok != tt.wantOK {
+			t.Errorf("parseNscdHostsNegTTL(%q) = (%v, %v); want (%v, %v)", tt.conf, ttl, ok, tt.ttl, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// ntpSynced, if non-nil, implements NTPSynced.
+var ntpSynced func() (synced, ok bool)
+
+// NTPSynced reports whether the host clock is synchronized by a network time
+// service, and whether that could be determined. An unsynchronized clock
+// can break TLS certificate validation and control plane authentication.
+//
+// It uses:
+//
+//   - on Linux, the kernel's clock status from adjtimex(2), which
+//     systemd-timesyncd, chrony and ntpd all update: the clock is synced
+//     if the STA_UNSYNC flag is clear
+//   - on macOS, whether "Set time and date automatically" is on ("systemsetup
+//     -getusingnetworktime"), as macOS doesn't expose its time daemon's
+//     synchronization state
+//   - on Windows, the Windows Time service's status ("w32tm /query
+//     /status"): the clock is synced if it has a time source other than the
+//     local clock and the leap indicator isn't 3 (unsynchronized)
+//
+// Other platforms, and failures such as the Windows Time service not running,
+// report ok=false.
+func NTPSynced() (synced, ok bool) {
+	if ntpSynced == nil {
+		return false, false
+	}
+	return ntpSynced()
+}
+
+// parseW32tmStatus parses the output of "w32tm /query /status", which
+// contains lines like:
+//
+//	Leap Indicator: 0(no warning)
+//	Source: time.windows.com,0x9
+func parseW32tmStatus(out []byte) (synced, ok bool) {
+	var leap, source string
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		k, v, found := strings.Cut(sc.Text(), ":")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(k) {
+		case "Leap Indicator":
+			leap = strings.TrimSpace(v)
+		case "Source":
+			source = strings.TrimSpace(v)
+		}
+	}
+	if leap == "" || source == "" {
+		return false, false
+	}
+	switch source {
+	case "Local CMOS Clock", "Free-running System Clock":
+		return false, true
+	}
+	return !strings.HasPrefix(leap, "3"), true
+}
+
+// parseSystemsetupNetworkTime parses the output of macOS's "systemsetup
+// -getusingnetworktime", which is "Network Time: On" or "Network Time: Off".
+func parseSystemsetupNetworkTime(out []byte) (on, ok bool) {
+	_, v, found := strings.Cut(string(out), ":")
+	if !found {
+		return false, false
+	}
+	switch strings.TrimSpace(v) {
+	case "On":
+		return true, true
+	case "Off":
+		return false, true
+	}
+	return false, false
+}
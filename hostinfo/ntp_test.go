@@ -0,0 +1,62 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import "testing"
+
+func TestParseW32tmStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		wantSynced bool
+		wantOK     bool
+	}{
+		{
+			name:       "synced",
+			in:         "Leap Indicator: 0(no warning)\r\nStratum: 4 (secondary reference - syncd by (S)NTP)\r\nSource: time.windows.com,0x9\r\n",
+			wantSynced: true,
+			wantOK:     true,
+		},
+		{
+			name:   "local_clock",
+			in:     "Leap Indicator: 0(no warning)\r\nStratum: 1 (primary reference - syncd by radio clock)\r\nSource: Local CMOS Clock\r\n",
+			wantOK: true,
+		},
+		{
+			name:   "unsynchronized",
+			in:     "Leap Indicator: 3(not synchronized)\r\nSource: time.windows.com,0x9\r\n",
+			wantOK: true,
+		},
+		{
+			name: "service_stopped",
+			in:   "The following error occurred: The service has not been started. (0x80070426)\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synced, ok := parseW32tmStatus([]byte(tt.in))
+			if synced != tt.wantSynced || ok != tt.wantOK {
+				t.Errorf("got (%v, %v); want (%v, %v)", synced, ok, tt.wantSynced, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseSystemsetupNetworkTime(t *testing.T) {
+	tests := []struct {
+		in     string
+		wantOn bool
+		wantOK bool
+	}{
+		{"Network Time: On\n", true, true},
+		{"Network Time: Off\n", false, true},
+		{"You need administrator access to run this tool... exiting!\n", false, false},
+	}
+	for _, tt := range tests {
+		on, ok := parseSystemsetupNetworkTime([]byte(tt.in))
+		if on != tt.wantOn || ok != tt.wantOK {
+			t.Errorf("parseSystemsetupNetworkTime(%q) = (%v, %v); want (%v, %v)", tt.in, on, ok, tt.wantOn, tt.wantOK)
+		}
+	}
+}
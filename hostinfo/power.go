@@ -0,0 +1,47 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import "strings"
+
+// onBattery, if non-nil, implements OnBatteryPower.
+var onBattery func() (battery, ok bool)
+
+// OnBatteryPower reports whether the host is running on battery power
+// rather than from an external power source, and whether that could be
+// determined at all. Hosts without a battery are reported as not on
+// battery, where that can be told.
+//
+// It uses:
+//
+//   - on Linux, the power supplies in /sys/class/power_supply: the host is
+//     on battery if one of its batteries is discharging and none of its
+//     other supplies (mains or USB power) is online; batteries of
+//     peripherals, such as wireless mice, are ignored
+//   - on macOS, the power source that "pmset -g batt" reports drawing from
+//   - on Windows, the AC line status from GetSystemPowerStatus
+//
+// Other platforms report (false, false).
+func OnBatteryPower() (battery, ok bool) {
+	if onBattery == nil {
+		return false, false
+	}
+	return onBattery()
+}
+
+// parsePmsetBatt returns whether the output of macOS's "pmset -g batt"
+// says the host is drawing from battery power, which starts like:
+//
+//	Now drawing from 'Battery Power'
+//	 -InternalBattery-0 (id=1234567)	95%; discharging; 5:12 remaining present: true
+func parsePmsetBatt(out []byte) (battery, ok bool) {
+	first, _, _ := strings.Cut(string(out), "\n")
+	switch {
+	case strings.Contains(first, "'Battery Power'"):
+		return true, true
+	case strings.Contains(first, "'AC Power'"), strings.Contains(first, "'UPS Power'"):
+		return false, true
+	}
+	return false, false
+}
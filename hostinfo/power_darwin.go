@@ -0,0 +1,24 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+func init() {
+	onBattery = onBatteryDarwin
+}
+
+func onBatteryDarwin() (battery, ok bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "/usr/bin/pmset", "-g", "batt").Output()
+	if err != nil {
+		return false, false
+	}
+	return parsePmsetBatt(out)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	onBattery = func() (battery, ok bool) { return onBatteryLinux("/sys/class/power_supply") }
+}
+
+// onBatteryLinux implements OnBatteryPower using the power supplies in dir,
+// normally /sys/class/power_supply.
+func onBatteryLinux(dir string) (battery, ok bool) {
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return false, false
+	}
+	read := func(supply, attr string) string {
+		b, _ := os.ReadFile(filepath.Join(dir, supply, attr))
+		return strings.TrimSpace(string(b))
+	}
+	discharging := false
+	for _, e := range ents {
+		name := e.Name()
+		if read(name, "scope") == "Device" {
+			continue // a peripheral's battery
+		}
+		switch read(name, "type") {
+		case "Battery":
+			if read(name, "status") == "Discharging" {
+				discharging = true
+			}
+		case "Mains", "USB", "USB_C", "USB_PD":
+			if read(name, "online") == "1" {
+				return false, true
+			}
+		}
+	}
+	return discharging, true
+}
@@ -0,0 +1,62 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOnBatteryLinux(t *testing.T) {
+	// writeSupplies creates a power_supply directory with supplies, each a
+	// map of attribute files to their contents.
+	writeSupplies := func(t *testing.T, supplies map[string]map[string]string) string {
+		dir := t.TempDir()
+		for name, attrs := range supplies {
+			if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+				t.Fatal(err)
+			}
+			for attr, v := range attrs {
+				if err := os.WriteFile(filepath.Join(dir, name, attr), []byte(v+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		return dir
+	}
+	tests := []struct {
+		name     string
+		supplies map[string]map[string]string
+		battery  bool
+	}{
+		{"desktop", nil, false},
+		{"laptop-on-battery", map[string]map[string]string{
+			"AC":   {"type": "Mains", "online": "0"},
+			"BAT0": {"type": "Battery", "status": "Discharging"},
+		}, true},
+		{"laptop-plugged-in", map[string]map[string]string{
+			"AC":   {"type": "Mains", "online": "1"},
+			"BAT0": {"type": "Battery", "status": "Charging"},
+		}, false},
+		{"usb-c-charger", map[string]map[string]string{
+			"ucsi-source-psy-USBC000:001": {"type": "USB", "online": "1"},
+			"BAT0":                        {"type": "Battery", "status": "Discharging"},
+		}, false},
+		{"wireless-mouse", map[string]map[string]string{
+			"hidpp_battery_0": {"type": "Battery", "scope": "Device", "status": "Discharging"},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			battery, ok := onBatteryLinux(writeSupplies(t, tt.supplies))
+			if battery != tt.battery || !ok {
+				t.Errorf("got (%v, %v); want (%v, true)", battery, ok, tt.battery)
+			}
+		})
+	}
+	if _, ok := onBatteryLinux(filepath.Join(t.TempDir(), "missing")); ok {
+		t.Error("missing directory: got ok")
+	}
+}
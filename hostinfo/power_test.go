@@ -0,0 +1,23 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import "testing"
+
+func TestParsePmsetBatt(t *testing.T) {
+	tests := []struct {
+		out         string
+		battery, ok bool
+	}{
+		{"Now drawing from 'Battery Power'\n -InternalBattery-0 (id=4653155)\t95%; discharging; 5:12 remaining present: true\n", true, true},
+		{"Now drawing from 'AC Power'\n -InternalBattery-0 (id=4653155)\t100%; charged; 0:00 remaining present: true\n", false, true},
+		{"Now drawing from 'AC Power'\n", false, true},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		if battery, ok := parsePmsetBatt([]byte(tt.out)); battery != tt.battery || ok != tt.ok {
+			t.Errorf("parsePmsetBatt(%q) = (%v, %v); want (%v, %v)", tt.out, battery, ok, tt.battery, tt.ok)
+		}
+	}
+}
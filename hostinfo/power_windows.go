@@ -0,0 +1,24 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import "tailscale.com/util/winutil"
+
+func init() {
+	onBattery = onBatteryWindows
+}
+
+func onBatteryWindows() (battery, ok bool) {
+	st, err := winutil.GetSystemPowerStatus()
+	if err != nil {
+		return false, false
+	}
+	switch st.ACLineStatus {
+	case 0: // offline
+		return true, true
+	case 1: // online
+		return false, true
+	}
+	return false, false // 255: unknown
+}
@@ -0,0 +1,202 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"tailscale.com/types/lazy"
+)
+
+// systemProxy, if non-nil, returns the OS-wide HTTP(S) proxy, or "" if none.
+var systemProxy func() string
+
+var outboundProxyCache lazy.SyncValue[string]
+
+// OutboundProxy returns the HTTP proxy the host is configured to use for
+// outbound HTTPS connections, such as tailscaled's connection to the
+// control plane, or "" if there's none. Any password in the proxy URL is
+// redacted.
+//
+// The first non-empty of these environment variables wins: HTTPS_PROXY,
+// https_proxy, HTTP_PROXY, http_proxy, ALL_PROXY, all_proxy. Otherwise the
+// system proxy settings are consulted:
+//
+//   - on Windows, the machine-wide WinHTTP proxy ("netsh winhttp show
+//     proxy"), which is what services use; per-user proxy settings and PAC
+//     files aren't reported
+//   - on macOS, the HTTPS (or else HTTP) proxy of the system configuration
+//     ("scutil --proxy")
+//
+// The result is cached.
+func OutboundProxy() string {
+	return outboundProxyCache.Get(func() string {
+		if p := proxyFromEnv(os.Getenv); p != "" {
+			return redactProxyURL(p)
+		}
+		if systemProxy != nil {
+			return redactProxyURL(systemProxy())
+		}
+		return ""
+	})
+}
+
+// SystemProxy returns the OS-wide HTTP(S) proxy that [OutboundProxy] falls
+// back to, unredacted, or "" if there's none or the OS has no such setting.
+func SystemProxy() string {
+	if systemProxy == nil {
+		return ""
+	}
+	return systemProxy()
+}
+
+// systemPAC, if non-nil, reports whether the OS-wide proxy settings use
+// automatic configuration, and returns the PAC URL, or "" if it's discovered
+// with WPAD.
+var systemPAC func() (bool, string)
+
+// PACProxyConfigured reports whether the host is configured to find its
+// HTTP proxy with a proxy auto-config (PAC) file, as is common on corporate
+// networks, and returns the PAC file's URL with any password redacted. The
+// URL is empty if it's discovered with WPAD (Web Proxy Auto-Discovery)
+// rather than configured. tailscaled doesn't evaluate PAC files, so hosts
+// that require the proxy they name can fail to reach the control plane
+// unless it's also set explicitly (see [OutboundProxy]).
+//
+// The auto_proxy (or AUTO_PROXY) environment variable, which Chromium uses
+// for the PAC URL on Linux and other platforms without system proxy
+// settings, wins if it's set. Otherwise the system settings are consulted:
+//
+//   - on Windows, the AutoConfigURL registry value of the Internet
+//     Settings, first as set by group policy (HKLM) and then for the user
+//     (HKCU), which for the tailscaled service is the LocalSystem account
+//   - on macOS, the ProxyAutoConfigURLString of the system configuration
+//     ("scutil --proxy"), or ProxyAutoDiscoveryEnable for WPAD
+//
+// It returns (false, "") when no PAC file is configured.
+func PACProxyConfigured() (bool, string) {
+	if u := pacFromEnv(os.Getenv); u != "" {
+		return true, redactProxyURL(u)
+	}
+	if systemPAC != nil {
+		ok, u := systemPAC()
+		return ok, redactProxyURL(u)
+	}
+	return false, ""
+}
+
+// pacFromEnv returns the PAC URL set in the environment variables
+// PACProxyConfigured documents, as looked up with getenv.
+func pacFromEnv(getenv func(string) string) string {
+	for _, k := range []string{"auto_proxy", "AUTO_PROXY"} {
+		if v := strings.TrimSpace(getenv(k)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// proxyFromEnv returns the first proxy set in the environment variables
+// OutboundProxy documents, as looked up with getenv.
+func proxyFromEnv(getenv func(string) string) string {
+	for _, k := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "ALL_PROXY", "all_proxy"} {
+		if v := strings.TrimSpace(getenv(k)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// redactProxyURL returns p with the password of any userinfo replaced.
+func redactProxyURL(p string) string {
+	if !strings.Contains(p, "@") {
+		return p
+	}
+	u, err := url.Parse(p)
+	if err != nil || u.User == nil {
+		// Perhaps "user:pass@host:port" without a scheme.
+		if _, host, ok := strings.Cut(p, "@"); ok {
+			return "REDACTED@" + host
+		}
+		return p
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "REDACTED")
+	}
+	return u.String()
+}
+
+// parseScutilProxy returns the HTTPS proxy, or else the HTTP proxy, from the
+// output of macOS's "scutil --proxy", which looks like:
+//
+//	<dictionary> {
+//	  HTTPEnable : 1
+//	  HTTPPort : 3128
+//	  HTTPProxy : proxy.example.com
+//	  ...
+//	}
+func parseScutilProxy(out []byte) string {
+	kv := parseScutilKeys(out)
+	for _, scheme := range []string{"HTTPS", "HTTP"} {
+		if kv[scheme+"Enable"] != "1" || kv[scheme+"Proxy"] == "" {
+			continue
+		}
+		host := kv[scheme+"Proxy"]
+		if port := kv[scheme+"Port"]; port != "" {
+			host = net.JoinHostPort(host, port)
+		}
+		return host
+	}
+	return ""
+}
+
+// parseScutilPAC reports whether the output of macOS's "scutil --proxy"
+// (see parseScutilProxy) enables automatic proxy configuration, and returns
+// the PAC URL, which is empty if it's discovered with WPAD:
+//
+//	ProxyAutoConfigEnable : 1
+//	ProxyAutoConfigURLString : http://wpad.example.com/proxy.pac
+//	ProxyAutoDiscoveryEnable : 0
+func parseScutilPAC(out []byte) (bool, string) {
+	kv := parseScutilKeys(out)
+	if kv["ProxyAutoConfigEnable"] == "1" && kv["ProxyAutoConfigURLString"] != "" {
+		return true, kv["ProxyAutoConfigURLString"]
+	}
+	return kv["ProxyAutoDiscoveryEnable"] == "1", ""
+}
+
+// parseScutilKeys returns the "key : value" pairs of the output of macOS's
+// "scutil --proxy". Nested dictionaries and arrays aren't distinguished.
+func parseScutilKeys(out []byte) map[string]string {
+	kv := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), " : ")
+		if ok {
+			kv[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return kv
+}
+
+// parseNetshWinHTTPProxy returns the proxy server from the output of
+// Windows's "netsh winhttp show proxy", which shows either "Direct access
+// (no proxy server)." or a line like:
+//
+//	Proxy Server(s) :  proxy.example.com:8080
+func parseNetshWinHTTPProxy(out []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), ":")
+		if ok && strings.TrimSpace(k) == "Proxy Server(s)" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
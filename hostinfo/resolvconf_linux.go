@@ -0,0 +1,76 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const resolvConf = "/etc/resolv.conf"
+
+// resolvConfOwnerLinux returns the program that appears to manage
+// /etc/resolv.conf, or "" if unknown.
+func resolvConfOwnerLinux() string {
+	target, _ := os.Readlink(resolvConf)
+	contents, _ := os.ReadFile(resolvConf)
+	return parseResolvConfOwner(target, contents)
+}
+
+// parseResolvConfOwner returns "NetworkManager", "systemd-resolved" or
+// "resolvconf" if the resolv.conf symlink target or the header comments of
+// its contents name that program as the file's owner, or "" otherwise. The
+// symlink target takes precedence.
+//
+// It's similar to the logic in tailscale.com/net/dns, which hostinfo can't
+// depend on.
+func parseResolvConfOwner(target string, contents []byte) string {
+	switch {
+	case strings.HasPrefix(target, "/run/NetworkManager/"), strings.HasPrefix(target, "/var/run/NetworkManager/"):
+		return "NetworkManager"
+	case strings.Contains(target, "/systemd/resolve/"):
+		return "systemd-resolved"
+	case strings.Contains(target, "/resolvconf/"):
+		return "resolvconf"
+	}
+	likely := ""
+	sc := bufio.NewScanner(bytes.NewReader(contents))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if line[0] != '#' {
+			// The owner's header is at the top.
+			break
+		}
+		switch {
+		case strings.Contains(line, "systemd-resolved"):
+			likely = "systemd-resolved"
+		case strings.Contains(line, "NetworkManager"):
+			likely = "NetworkManager"
+		case strings.Contains(line, "resolvconf"):
+			likely = "resolvconf"
+		}
+	}
+	return likely
+}
+
+func systemdResolvedLinux() bool {
+	if _, err := os.Stat("/run/systemd/resolve/stub-resolv.conf"); err != nil {
+		return false
+	}
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	// is-active exits with code 3 if the service is not active.
+	return exec.CommandContext(ctx, "systemctl", "is-active", "--quiet", "systemd-resolved.service").Run() == nil
+}
@@ -0,0 +1,31 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import "testing"
+
+func TestParseResolvConfOwner(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		contents string
+		want     string
+	}{
+		{"nm_symlink", "/run/NetworkManager/resolv.conf", "", "NetworkManager"},
+		{"resolved_symlink", "../run/systemd/resolve/stub-resolv.conf", "", "systemd-resolved"},
+		{"resolvconf_symlink", "/run/resolvconf/resolv.conf", "", "resolvconf"},
+		{"nm_header", "", "# Generated by NetworkManager\nnameserver 192.168.1.1\n", "NetworkManager"},
+		{"resolved_header", "", "# This is /run/systemd/resolve/stub-resolv.conf managed by man:systemd-resolved(8).\n# Do not edit.\nnameserver 127.0.0.53\n", "systemd-resolved"},
+		{"header_after_content", "", "nameserver 8.8.8.8\n# Generated by NetworkManager\n", ""},
+		{"unmanaged", "", "nameserver 8.8.8.8\n", ""},
+		{"symlink_wins", "/run/NetworkManager/resolv.conf", "# systemd-resolved\n", "NetworkManager"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseResolvConfOwner(tt.target, []byte(tt.contents)); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
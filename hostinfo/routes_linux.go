@@ -0,0 +1,288 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/netip"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"go4.org/mem"
+	"golang.org/x/sys/unix"
+	"tailscale.com/util/lineiter"
+)
+
+// procNetRoute is an IPv4 route from the main routing table, as read from
+// /proc/net/route.
+type procNetRoute struct {
+	iface   string
+	dst     netip.Prefix
+	gateway netip.Addr // zero value if the route has no gateway
+	metric  uint64
+}
+
+// isDefault reports whether r is an IPv4 default route.
+func (r procNetRoute) isDefault() bool {
+	return r.dst.Bits() == 0
+}
+
+// maxProcNetRouteLines is the maximum number of lines of /proc/net/route
+// that are read. Big Linux routers can have enormous routing tables, and we
+// don't want to spend much time or memory on them for diagnostics.
+const maxProcNetRouteLines = 1000
+
+// readProcNetRoute returns the routes in /proc/net/route that are up. It
+// returns nil if the file can't be read.
+func readProcNetRoute() []procNetRoute {
+	f, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	return parseProcNetRoute(f)
+}
+
+/*
+parseProcNetRoute parses the up routes out of the format:
+
+	Iface   Destination     Gateway         Flags   RefCnt  Use     Metric  Mask            MTU     Window  IRTT
+	ens18   00000000        0100000A        0003    0       0       0       00000000        0       0       0
+	ens18   0000000A        00000000        0001    0       0       0       0000FFFF        0       0       0
+
+Addresses are printed as hex in host byte order, which is little endian
+on all the platforms we care about.
+*/
+func parseProcNetRoute(r io.Reader) []procNetRoute {
+	var ret []procNetRoute
+	var f []mem.RO
+	lineNum := 0
+	for lr := range lineiter.Reader(r) {
+		line, err := lr.Value()
+		if err != nil {
+			break
+		}
+		lineNum++
+		if lineNum == 1 {
+			continue // header
+		}
+		if lineNum > maxProcNetRouteLines {
+			break
+		}
+		f = mem.AppendFields(f[:0], mem.B(line))
+		if len(f) < 8 {
+			continue
+		}
+		flags, err := mem.ParseUint(f[3], 16, 16)
+		if err != nil || flags&unix.RTF_UP == 0 {
+			continue
+		}
+		dst, ok1 := parseProcNetRouteAddr(f[1])
+		gw, ok2 := parseProcNetRouteAddr(f[2])
+		mask, err1 := mem.ParseUint(f[7], 16, 32)
+		metric, err2 := mem.ParseUint(f[6], 10, 64)
+		if !ok1 || !ok2 || err1 != nil || err2 != nil {
+			continue
+		}
+		route := procNetRoute{
+			iface:  f[0].StringCopy(),
+			dst:    netip.PrefixFrom(dst, bits.OnesCount32(uint32(mask))),
+			metric: metric,
+		}
+		if flags&unix.RTF_GATEWAY != 0 {
+			route.gateway = gw
+		}
+		ret = append(ret, route)
+	}
+	return ret
+}
+
+// defaultRouteInterfaceLinux returns the name of the interface of the lowest
+// metric IPv4 default route in the main routing table, or the empty string
+// if there's none.
+func defaultRouteInterfaceLinux() string {
+	var best procNetRoute
+	found := false
+	for _, r := range readProcNetRoute() {
+		if r.isDefault() && (!found || r.metric < best.metric) {
+			best, found = r, true
+		}
+	}
+	return best.iface
+}
+
+// defaultGatewayOf returns the gateway of the lowest metric IPv4 default
+// route in routes, or the zero value if there's none.
+func defaultGatewayOf(routes []procNetRoute) netip.Addr {
+	var best procNetRoute
+	for _, r := range routes {
+		if r.isDefault() && r.gateway.IsValid() && (!best.gateway.IsValid() || r.metric < best.metric) {
+			best = r
+		}
+	}
+	return best.gateway
+}
+
+/*
+arpResolved reports whether the ARP table in /proc/net/arp has a complete
+entry for ip, in the format:
+
+	IP address       HW type     Flags       HW address            Mask     Device
+	10.0.0.1         0x1         0x2         52:54:00:12:34:56     *        ens18
+*/
+func arpResolved(procNetARP []byte, ip netip.Addr) bool {
+	const atfCom = 0x2 // ATF_COM: the hardware address is known
+	for i, line := range strings.Split(string(procNetARP), "\n") {
+		f := strings.Fields(line)
+		if i == 0 || len(f) < 4 || f[0] != ip.String() {
+			continue
+		}
+		flags, err := strconv.ParseUint(strings.TrimPrefix(f[2], "0x"), 16, 32)
+		if err == nil && flags&atfCom != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMultipleDefaultGateways reports whether routes contains default routes
+// via different gateways on different interfaces.
+func hasMultipleDefaultGateways(routes []procNetRoute) bool {
+	var first procNetRoute
+	found := false
+	for _, r := range routes {
+		if !r.isDefault() || !r.gateway.IsValid() {
+			continue
+		}
+		if !found {
+			first, found = r, true
+			continue
+		}
+		if r.iface != first.iface && r.gateway != first.gateway {
+			return true
+		}
+	}
+	return false
+}
+
+// asymmetricDefaultRoutes reports whether routes contains a default route
+// through a different interface than that of the lowest metric default
+// route, which replies to traffic arriving through it would take, and
+// describes the first such route.
+func asymmetricDefaultRoutes(routes []procNetRoute) (bool, string) {
+	var primary procNetRoute
+	found := false
+	for _, r := range routes {
+		if r.isDefault() && r.gateway.IsValid() && (!found || r.metric < primary.metric) {
+			primary, found = r, true
+		}
+	}
+	for _, r := range routes {
+		if !r.isDefault() || !r.gateway.IsValid() || r.iface == primary.iface {
+			continue
+		}
+		return true, fmt.Sprintf("traffic arriving on %s via %v is answered through %s via %v", r.iface, r.gateway, primary.iface, primary.gateway)
+	}
+	return false, ""
+}
+
+// cgnatConflicts returns the routes in routes, formatted like "ip route"
+// does, that overlap Tailscale's CGNAT range and aren't default routes or
+// Tailscale's own.
+func cgnatConflicts(routes []procNetRoute) []string {
+	// Tailscale's CGNAT range, as returned by tsaddr.CGNATRange, which
+	// hostinfo doesn't import to keep its dependencies small.
+	cgnat := netip.MustParsePrefix("100.64.0.0/10")
+	var ret []string
+	for _, r := range routes {
+		if r.isDefault() || !r.dst.Overlaps(cgnat) || strings.HasPrefix(r.iface, "tailscale") {
+			continue
+		}
+		s := r.dst.String()
+		if r.gateway.IsValid() {
+			s += " via " + r.gateway.String()
+		}
+		ret = append(ret, s+" dev "+r.iface)
+	}
+	return ret
+}
+
+// policyRoutingRulesLinux returns the IPv4 and IPv6 policy routing rules
+// other than the defaults and Tailscale's own, with IPv6 ones prefixed by
+// "-6". See [PolicyRoutingActive].
+func policyRoutingRulesLinux() []string {
+	rules := customIPRules(ipRuleShow("-4"))
+	for _, r := range customIPRules(ipRuleShow("-6")) {
+		rules = append(rules, "-6 "+r)
+	}
+	return rules
+}
+
+// ipRuleShow returns the output of "ip <family> rule show", or the empty
+// string if it fails.
+func ipRuleShow(family string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, _ := exec.CommandContext(ctx, "ip", family, "rule", "show").Output()
+	return string(out)
+}
+
+// defaultIPRules are the rules, as normalized by customIPRules, that the
+// kernel installs in each address family.
+var defaultIPRules = map[string]bool{
+	"0: from all lookup local":       true,
+	"32766: from all lookup main":    true,
+	"32767: from all lookup default": true,
+}
+
+// customIPRules returns the rules in out, the output of "ip rule show", other
+// than the kernel's defaults and those tailscaled installs, with whitespace
+// normalized.
+func customIPRules(out string) []string {
+	var ret []string
+	for line := range strings.Lines(out) {
+		pref, rule, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rule)
+		r := strings.TrimSpace(pref) + ": " + strings.Join(fields, " ")
+		if defaultIPRules[r] || isTailscaleIPRule(fields) {
+			continue
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// isTailscaleIPRule reports whether the rule with the given fields is one
+// that tailscaled installs: those look up its routing table, 52, or match its
+// bypass fwmark.
+//
+// The fwmark is tsconst.LinuxBypassMark/tsconst.LinuxFwmarkMask, spelled out
+// here to keep hostinfo's dependencies small.
+func isTailscaleIPRule(fields []string) bool {
+	for i := 0; i+1 < len(fields); i++ {
+		switch {
+		case fields[i] == "lookup" && fields[i+1] == "52",
+			fields[i] == "fwmark" && fields[i+1] == "0x80000/0xff0000":
+			return true
+		}
+	}
+	return false
+}
+
+func parseProcNetRouteAddr(hex mem.RO) (netip.Addr, bool) {
+	u, err := mem.ParseUint(hex, 16, 32)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return netip.AddrFrom4([4]byte{byte(u), byte(u >> 8), byte(u >> 16), byte(u >> 24)}), true
+}
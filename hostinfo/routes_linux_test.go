@@ -0,0 +1,215 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseProcNetRoute(t *testing.T) {
+	const in = `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+wlan0	00000000	0101A8C0	0003	0	0	600	00000000	0	0	0
+eth0	00000000	0100000A	0003	0	0	100	00000000	0	0	0
+eth0	0000000A	00000000	0001	0	0	100	0000FFFF	0	0	0
+eth1	0000A8C0	00000000	0000	0	0	0	00FFFFFF	0	0	0
+garbage
+`
+	got := parseProcNetRoute(strings.NewReader(in))
+	want := []procNetRoute{
+		{iface: "wlan0", dst: netip.MustParsePrefix("0.0.0.0/0"), gateway: netip.MustParseAddr("192.168.1.1"), metric: 600},
+		{iface: "eth0", dst: netip.MustParsePrefix("0.0.0.0/0"), gateway: netip.MustParseAddr("10.0.0.1"), metric: 100},
+		{iface: "eth0", dst: netip.MustParsePrefix("10.0.0.0/16"), metric: 100},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseProcNetRoute:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestHasMultipleDefaultGateways(t *testing.T) {
+	dflt := netip.MustParsePrefix("0.0.0.0/0")
+	gw1 := netip.MustParseAddr("192.168.1.1")
+	gw2 := netip.MustParseAddr("10.0.0.1")
+	tests := []struct {
+		name   string
+		routes []procNetRoute
+		want   bool
+	}{
+		{"none", nil, false},
+		{"single", []procNetRoute{{iface: "eth0", dst: dflt, gateway: gw1}}, false},
+		{"same-gateway-twice", []procNetRoute{
+			{iface: "eth0", dst: dflt, gateway: gw1},
+			{iface: "eth0", dst: dflt, gateway: gw1, metric: 10},
+		}, false},
+		{"wired-and-wifi", []procNetRoute{
+			{iface: "eth0", dst: dflt, gateway: gw2, metric: 100},
+			{iface: "wlan0", dst: dflt, gateway: gw1, metric: 600},
+		}, true},
+		{"non-default", []procNetRoute{
+			{iface: "eth0", dst: dflt, gateway: gw2},
+			{iface: "wlan0", dst: netip.MustParsePrefix("192.168.1.0/24"), gateway: gw1},
+		}, false},
+	}
+	for _, tt := range tests {
+		if got := hasMultipleDefaultGateways(tt.routes); got != tt.want {
+			t.Errorf("%s: got %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCGNATConflicts(t *testing.T) {
+	routes := []procNetRoute{
+		{iface: "eth0", dst: netip.MustParsePrefix("0.0.0.0/0"), gateway: netip.MustParseAddr("192.168.1.1")},
+		{iface: "eth0", dst: netip.MustParsePrefix("192.168.1.0/24")},
+		{iface: "eth0", dst: netip.MustParsePrefix("100.64.0.0/10"), gateway: netip.MustParseAddr("192.168.1.1")},
+		{iface: "wg0", dst: netip.MustParsePrefix("100.100.0.0/16")},
+		{iface: "tun0", dst: netip.MustParsePrefix("100.0.0.0/8")},
+		{iface: "tailscale0", dst: netip.MustParsePrefix("100.101.102.103/32")},
+		{iface: "eth1", dst: netip.MustParsePrefix("100.128.0.0/16")},
+	}
+	got := cgnatConflicts(routes)
+	want := []string{
+		"100.64.0.0/10 via 192.168.1.1 dev eth0",
+		"100.100.0.0/16 dev wg0",
+		"100.0.0.0/8 dev tun0",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("cgnatConflicts:\n got %q\nwant %q", got, want)
+	}
+	if got := cgnatConflicts(routes[:2]); got != nil {
+		t.Errorf("cgnatConflicts without conflicts = %q; want nil", got)
+	}
+}
+
+func TestDefaultGatewayOf(t *testing.T) {
+	routes := []procNetRoute{
+		{iface: "eth0", dst: netip.MustParsePrefix("10.0.0.0/24")},
+		{iface: "wlan0", dst: netip.MustParsePrefix("0.0.0.0/0"), gateway: netip.MustParseAddr("192.168.1.1"), metric: 600},
+		{iface: "eth0", dst: netip.MustParsePrefix("0.0.0.0/0"), gateway: netip.MustParseAddr("10.0.0.1"), metric: 100},
+		{iface: "wg0", dst: netip.MustParsePrefix("0.0.0.0/0")},
+	}
+	if got, want := defaultGatewayOf(routes), netip.MustParseAddr("10.0.0.1"); got != want {
+		t.Errorf("defaultGatewayOf = %v; want %v", got, want)
+	}
+	if got := defaultGatewayOf(routes[:1]); got.IsValid() {
+		t.Errorf("defaultGatewayOf without default routes = %v; want zero", got)
+	}
+}
+
+func TestARPResolved(t *testing.T) {
+	const arp = `IP address       HW type     Flags       HW address            Mask     Device
+10.0.0.1         0x1         0x2         52:54:00:12:34:56     *        ens18
+10.0.0.7         0x1         0x0         00:00:00:00:00:00     *        ens18
+`
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.0.0.1", true},
+		{"10.0.0.7", false}, // incomplete
+		{"10.0.0.9", false},
+	}
+	for _, tt := range tests {
+		if got := arpResolved([]byte(arp), netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("arpResolved(%s) = %v; want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestAsymmetricDefaultRoutes(t *testing.T) {
+	def := netip.MustParsePrefix("0.0.0.0/0")
+	lan := netip.MustParsePrefix("10.0.0.0/24")
+	tests := []struct {
+		name   string
+		routes []procNetRoute
+		want   string
+	}{
+		{
+			name: "single",
+			routes: []procNetRoute{
+				{iface: "eth0", dst: def, gateway: netip.MustParseAddr("10.0.0.1")},
+				{iface: "eth0", dst: lan},
+			},
+		},
+		{
+			name: "same-interface",
+			routes: []procNetRoute{
+				{iface: "eth0", dst: def, gateway: netip.MustParseAddr("10.0.0.1"), metric: 100},
+				{iface: "eth0", dst: def, gateway: netip.MustParseAddr("10.0.0.2"), metric: 200},
+			},
+		},
+		{
+			name: "gatewayless-vpn",
+			routes: []procNetRoute{
+				{iface: "eth0", dst: def, gateway: netip.MustParseAddr("10.0.0.1")},
+				{iface: "wg0", dst: def},
+			},
+		},
+		{
+			name: "multi-wan",
+			routes: []procNetRoute{
+				{iface: "wlan0", dst: def, gateway: netip.MustParseAddr("192.168.1.1"), metric: 600},
+				{iface: "eth0", dst: def, gateway: netip.MustParseAddr("10.0.0.1"), metric: 100},
+			},
+			want: "traffic arriving on wlan0 via 192.168.1.1 is answered through eth0 via 10.0.0.1",
+		},
+	}
+	for _, tt := range tests {
+		asym, got := asymmetricDefaultRoutes(tt.routes)
+		if asym != (tt.want != "") || got != tt.want {
+			t.Errorf("%s: got (%v, %q); want %q", tt.name, asym, got, tt.want)
+		}
+	}
+}
+
+func TestCustomIPRules(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want []string
+	}{
+		{
+			name: "defaults",
+			out: `0:	from all lookup local
+32766:	from all lookup main
+32767:	from all lookup default
+`,
+		},
+		{
+			name: "tailscale",
+			out: `0:	from all lookup local
+5210:	from all fwmark 0x80000/0xff0000 lookup main
+5230:	from all fwmark 0x80000/0xff0000 lookup default
+5250:	from all fwmark 0x80000/0xff0000 unreachable
+5270:	from all lookup 52
+32766:	from all lookup main
+32767:	from all lookup default
+`,
+		},
+		{
+			name: "multi-wan",
+			out: `0:	from all lookup local
+100:	from 192.168.1.0/24 lookup 100
+101:	from all fwmark 0x1 lookup wan2
+32766:	from all lookup main
+32767:	from all lookup default
+`,
+			want: []string{
+				"100: from 192.168.1.0/24 lookup 100",
+				"101: from all fwmark 0x1 lookup wan2",
+			},
+		},
+		{
+			name: "empty",
+			out:  "",
+		},
+	}
+	for _, tt := range tests {
+		if got := customIPRules(tt.out); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func thermalThrottledLinux() (throttled, ok bool) {
+	if state, ok := raspberryPiThrottleState(); ok {
+		return state&piThrottledNow != 0, true
+	}
+	return coolingDevicesActive()
+}
+
+// piThrottledNow are the bits of the Raspberry Pi firmware's throttling
+// state that indicate current (as opposed to past, since boot) under-voltage,
+// frequency capping, throttling or soft temperature limiting.
+const piThrottledNow = 0xf
+
+// raspberryPiThrottleState returns the Raspberry Pi firmware's throttling
+// state bitmask, and whether this is a Raspberry Pi that reported it.
+func raspberryPiThrottleState() (state uint64, ok bool) {
+	if b, err := os.ReadFile("/sys/devices/platform/soc/soc:firmware/get_throttled"); err == nil {
+		state, err := strconv.ParseUint(strings.TrimSpace(string(b)), 16, 64)
+		return state, err == nil
+	}
+	if _, err := exec.LookPath("vcgencmd"); err != nil {
+		return 0, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "vcgencmd", "get_throttled").Output()
+	if err != nil {
+		return 0, false
+	}
+	return parseVcgencmdThrottled(string(out))
+}
+
+// parseVcgencmdThrottled parses the output of "vcgencmd get_throttled",
+// which looks like "throttled=0x50005".
+func parseVcgencmdThrottled(s string) (state uint64, ok bool) {
+	v, found := strings.CutPrefix(strings.TrimSpace(s), "throttled=0x")
+	if !found {
+		return 0, false
+	}
+	state, err := strconv.ParseUint(v, 16, 64)
+	return state, err == nil
+}
+
+// coolingDevicesActive reports whether any CPU cooling device (such as
+// cpufreq or intel_powerclamp) is throttling the processor, and whether
+// there are any.
+func coolingDevicesActive() (active, ok bool) {
+	devs, _ := filepath.Glob("/sys/class/thermal/cooling_device*")
+	for _, dev := range devs {
+		typ, err := os.ReadFile(filepath.Join(dev, "type"))
+		if err != nil || !isCPUCoolingDevice(strings.TrimSpace(string(typ))) {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dev, "cur_state"))
+		if err != nil {
+			continue
+		}
+		ok = true
+		if n, err := strconv.Atoi(strings.TrimSpace(string(b))); err == nil && n > 0 {
+			return true, true
+		}
+	}
+	return false, ok
+}
+
+// isCPUCoolingDevice reports whether a thermal cooling device type (the
+// contents of its sysfs "type" file) throttles the CPU, as opposed to, say,
+// driving a fan.
+func isCPUCoolingDevice(typ string) bool {
+	return typ == "Processor" ||
+		strings.Contains(typ, "cpufreq") ||
+		strings.Contains(typ, "powerclamp") ||
+		strings.HasPrefix(typ, "cpu")
+}
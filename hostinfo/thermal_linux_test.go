@@ -0,0 +1,29 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import "testing"
+
+func TestParseVcgencmdThrottled(t *testing.T) {
+	tests := []struct {
+		in          string
+		wantState   uint64
+		wantOK      bool
+		wantCurrent bool
+	}{
+		{"throttled=0x0\n", 0, true, false},
+		{"throttled=0x50005\n", 0x50005, true, true},
+		{"throttled=0x50000\n", 0x50000, true, false}, // only in the past
+		{"VCHI initialization failed\n", 0, false, false},
+	}
+	for _, tt := range tests {
+		state, ok := parseVcgencmdThrottled(tt.in)
+		if state != tt.wantState || ok != tt.wantOK {
+			t.Errorf("parseVcgencmdThrottled(%q) = (%#x, %v); want (%#x, %v)", tt.in, state, ok, tt.wantState, tt.wantOK)
+		}
+		if got := state&piThrottledNow != 0; got != tt.wantCurrent {
+			t.Errorf("parseVcgencmdThrottled(%q): currently throttled = %v; want %v", tt.in, got, tt.wantCurrent)
+		}
+	}
+}
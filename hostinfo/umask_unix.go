@@ -0,0 +1,55 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build unix
+
+package hostinfo
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+func init() {
+	currentUmask = currentUmaskUnix
+}
+
+// umaskMu serializes the umask(2) set-and-restore in currentUmaskUnix
+// between concurrent callers. It can't protect other code creating files
+// meanwhile.
+var umaskMu sync.Mutex
+
+func currentUmaskUnix() (int, bool) {
+	// Linux 4.7+ reports the umask in /proc, which reads it without
+	// changing it.
+	if f, err := os.Open("/proc/self/status"); err == nil {
+		defer f.Close()
+		if mask, ok := parseProcStatusUmask(bufio.NewScanner(f)); ok {
+			return mask, true
+		}
+	}
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+	mask := unix.Umask(0o077)
+	unix.Umask(mask)
+	return mask, true
+}
+
+// parseProcStatusUmask returns the value of the "Umask:" line scanned from
+// /proc/self/status, which is in octal.
+func parseProcStatusUmask(sc *bufio.Scanner) (mask int, ok bool) {
+	for sc.Scan() {
+		v, found := strings.CutPrefix(sc.Text(), "Umask:")
+		if !found {
+			continue
+		}
+		m, err := strconv.ParseUint(strings.TrimSpace(v), 8, 32)
+		return int(m), err == nil
+	}
+	return 0, false
+}
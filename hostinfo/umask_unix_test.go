@@ -0,0 +1,23 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build unix
+
+package hostinfo
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestParseProcStatusUmask(t *testing.T) {
+	const status = "Name:\tcat\nUmask:\t0027\nState:\tR (running)\n"
+	mask, ok := parseProcStatusUmask(bufio.NewScanner(strings.NewReader(status)))
+	if mask != 0o027 || !ok {
+		t.Errorf("got (%#o, %v); want (027, true)", mask, ok)
+	}
+	if _, ok := parseProcStatusUmask(bufio.NewScanner(strings.NewReader("Name:\tcat\n"))); ok {
+		t.Error("got ok without Umask line")
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build unix
+
+package hostinfo
+
+import (
+	"io"
+	"os"
+
+	"tailscale.com/types/lazy"
+)
+
+func init() {
+	hasUrandom = hasUrandomUnix
+}
+
+var hasUrandomCache lazy.SyncValue[bool]
+
+func hasUrandomUnix() bool {
+	return hasUrandomCache.Get(func() bool {
+		f, err := os.Open("/dev/urandom")
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+		var b [1]byte
+		_, err = io.ReadFull(f, b[:])
+		return err == nil
+	})
+}
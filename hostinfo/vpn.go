@@ -0,0 +1,142 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"net"
+	"slices"
+	"strings"
+)
+
+// non-nil on some platforms
+var (
+	// processNames returns the executable names of running processes.
+	processNames func() []string
+	// vpnDrivers returns the names of installed VPN drivers, as in
+	// vpnDriverProducts.
+	vpnDrivers func() []string
+)
+
+// ConflictingVPNs returns the names of other VPN products that appear to be
+// installed or running on the host, sorted, or an empty slice if none are
+// found. Other VPNs often install routes or DNS settings, or filter traffic,
+// in ways that conflict with Tailscale's.
+//
+// The heuristics are:
+//
+//   - network interface names characteristic of a product, such as
+//     "cscotun" (Cisco AnyConnect) or "nordlynx" (NordVPN); generic
+//     tun/tap interfaces are reported as OpenVPN and "wg" ones as WireGuard
+//   - running processes with a product's daemon or agent name, such as
+//     "openvpn" or "PanGPS" (GlobalProtect), as listed from /proc on Linux,
+//     the process snapshot on Windows, and ps on macOS
+//   - on Windows, installed drivers and services of well-known VPN
+//     products, such as OpenVPN's tap0901 adapter driver
+//
+// All of these can report false positives: generic tun and tap interfaces
+// are also created by virtual machine managers and other software, and an
+// installed but disconnected VPN client doesn't necessarily interfere. Tailscale's own interfaces and processes are
+// never reported.
+func ConflictingVPNs() []string {
+	found := make(map[string]bool)
+	if ifs, err := net.Interfaces(); err == nil {
+		for _, ifc := range ifs {
+			if p := vpnFromInterfaceName(ifc.Name); p != "" {
+				found[p] = true
+			}
+		}
+	}
+	if processNames != nil {
+		for _, name := range processNames() {
+			if p := vpnFromProcessName(name); p != "" {
+				found[p] = true
+			}
+		}
+	}
+	if vpnDrivers != nil {
+		for _, p := range vpnDrivers() {
+			found[p] = true
+		}
+	}
+	ret := make([]string, 0, len(found))
+	for p := range found {
+		ret = append(ret, p)
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+// vpnInterfacePrefixes maps network interface name prefixes (lowercased) to
+// the VPN product they indicate, most specific first.
+var vpnInterfacePrefixes = []struct {
+	prefix, product string
+}{
+	{"tailscale", ""}, // us
+	{"cscotun", "Cisco AnyConnect"},
+	{"gpd", "GlobalProtect"},
+	{"pangp", "GlobalProtect"},
+	{"nordlynx", "NordVPN"},
+	{"nordtun", "NordVPN"},
+	{"proton", "Proton VPN"},
+	{"wg-mullvad", "Mullvad"},
+	{"mullvad", "Mullvad"},
+	{"fortissl", "FortiClient"},
+	{"zt", "ZeroTier"},
+	{"ham", "Hamachi"},
+	{"nebula", "Nebula"},
+	{"wt", "NetBird"},
+	{"ipsec", "IPsec"},
+	{"openvpn", "OpenVPN"},
+	{"tap", "OpenVPN"},
+	{"tun", "OpenVPN"},
+	{"wg", "WireGuard"},
+	{"wireguard", "WireGuard"},
+}
+
+// vpnFromInterfaceName returns the VPN product indicated by a network
+// interface name, or "" if none.
+func vpnFromInterfaceName(name string) string {
+	name = strings.ToLower(name)
+	for _, p := range vpnInterfacePrefixes {
+		if strings.HasPrefix(name, p.prefix) {
+			return p.product
+		}
+	}
+	return ""
+}
+
+// vpnProcesses maps VPN daemon and agent executable names (lowercased,
+// without any .exe suffix) to their product.
+var vpnProcesses = map[string]string{
+	"openvpn":          "OpenVPN",
+	"openvpnserv":      "OpenVPN",
+	"openconnect":      "OpenConnect",
+	"vpnagentd":        "Cisco AnyConnect",
+	"vpnagent":         "Cisco AnyConnect",
+	"csc_cui":          "Cisco AnyConnect",
+	"pangps":           "GlobalProtect",
+	"pangpa":           "GlobalProtect",
+	"forticlient":      "FortiClient",
+	"fortitray":        "FortiClient",
+	"nordvpnd":         "NordVPN",
+	"nordvpn-service":  "NordVPN",
+	"expressvpnd":      "ExpressVPN",
+	"expressvpn":       "ExpressVPN",
+	"mullvad-daemon":   "Mullvad",
+	"protonvpn":        "Proton VPN",
+	"zerotier-one":     "ZeroTier",
+	"zerotier-one_x64": "ZeroTier",
+	"charon":           "strongSwan",
+	"wireguard":        "WireGuard",
+	"netbird":          "NetBird",
+	"nebula":           "Nebula",
+	"hamachi2-svc":     "Hamachi",
+}
+
+// vpnFromProcessName returns the VPN product indicated by a running
+// process's executable name, or "" if none.
+func vpnFromProcessName(name string) string {
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	return vpnProcesses[name]
+}
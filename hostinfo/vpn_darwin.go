@@ -0,0 +1,32 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"context"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+func init() {
+	processNames = processNamesDarwin
+}
+
+func processNamesDarwin() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "/bin/ps", "-axo", "comm=").Output()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for line := range strings.Lines(string(out)) {
+		if line = strings.TrimSpace(line); line != "" {
+			names = append(names, path.Base(line))
+		}
+	}
+	return names
+}
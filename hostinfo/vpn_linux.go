@@ -0,0 +1,25 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	processNames = processNamesLinux
+}
+
+func processNamesLinux() []string {
+	comms, _ := filepath.Glob("/proc/[0-9]*/comm")
+	names := make([]string, 0, len(comms))
+	for _, p := range comms {
+		if b, err := os.ReadFile(p); err == nil {
+			names = append(names, strings.TrimSpace(string(b)))
+		}
+	}
+	return names
+}
@@ -0,0 +1,43 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import "testing"
+
+func TestVPNFromInterfaceName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"tailscale0", ""},
+		{"cscotun0", "Cisco AnyConnect"},
+		{"nordlynx", "NordVPN"},
+		{"wg-mullvad", "Mullvad"},
+		{"wg0", "WireGuard"},
+		{"tun0", "OpenVPN"},
+		{"eth0", ""},
+		{"utun3", ""}, // macOS uses utun for everything, including us
+	}
+	for _, tt := range tests {
+		if got := vpnFromInterfaceName(tt.name); got != tt.want {
+			t.Errorf("vpnFromInterfaceName(%q) = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVPNFromProcessName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"openvpn", "OpenVPN"},
+		{"PanGPS.exe", "GlobalProtect"},
+		{"vpnagentd", "Cisco AnyConnect"},
+		{"tailscaled", ""},
+		{"bash", ""},
+	}
+	for _, tt := range tests {
+		if got := vpnFromProcessName(tt.name); got != tt.want {
+			t.Errorf("vpnFromProcessName(%q) = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hostinfo
+
+import (
+	"golang.org/x/sys/windows/registry"
+	"tailscale.com/util/winutil"
+)
+
+func init() {
+	processNames = processNamesWindows
+	vpnDrivers = vpnDriversWindows
+}
+
+func processNamesWindows() []string {
+	names, _ := winutil.ProcessNames()
+	return names
+}
+
+// vpnDriverServices maps the service names of VPN adapter drivers to their
+// product.
+var vpnDriverServices = map[string]string{
+	"tap0901":          "OpenVPN",
+	"ovpn-dco":         "OpenVPN",
+	"vpnva":            "Cisco AnyConnect",
+	"PanGpd":           "GlobalProtect",
+	"ftvnic":           "FortiClient",
+	"ZeroTierOneSvc":   "ZeroTier",
+	"WireGuardManager": "WireGuard",
+}
+
+func vpnDriversWindows() []string {
+	var products []string
+	for svc, product := range vpnDriverServices {
+		k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+svc, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		k.Close()
+		products = append(products, product)
+	}
+	return products
+}
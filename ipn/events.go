@@ -0,0 +1,138 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ipn
+
+import (
+	"fmt"
+	"strings"
+
+	"tailscale.com/health"
+	"tailscale.com/tailcfg"
+	"tailscale.com/util/set"
+)
+
+// EventTopic is a kind of change that a subscriber to the LocalAPI events
+// stream (/localapi/v0/events) can ask for. Unlike watching the IPN bus,
+// which delivers whole [Notify] messages, the stream carries only the parts
+// of them for the subscribed topics, and skips messages that have none.
+type EventTopic string
+
+const (
+	// EventTopicState is for changes to the backend [State].
+	EventTopicState EventTopic = "state"
+	// EventTopicPeers is for peers being added, removed or changed, and for
+	// changes to the self node.
+	EventTopicPeers EventTopic = "peers"
+	// EventTopicHealth is for changes to the health state.
+	EventTopicHealth EventTopic = "health"
+	// EventTopicExitNode is for the exit node being chosen, changed or
+	// turned off.
+	EventTopicExitNode EventTopic = "exitnode"
+)
+
+// eventTopicMasks are the IPN bus options that the events stream watches
+// with for each topic.
+var eventTopicMasks = map[EventTopic]NotifyWatchOpt{
+	EventTopicState:    NotifyInitialState,
+	EventTopicPeers:    NotifyPeerPatches,
+	EventTopicHealth:   NotifyInitialHealthState,
+	EventTopicExitNode: NotifyInitialPrefs,
+}
+
+// ParseEventTopics parses s, a comma-separated list of topics such as
+// "peers,exitnode", as taken by the events stream's "topics" parameter. An
+// empty s means all topics.
+func ParseEventTopics(s string) (set.Set[EventTopic], error) {
+	topics := set.Set[EventTopic]{}
+	if s == "" {
+		for t := range eventTopicMasks {
+			topics.Add(t)
+		}
+		return topics, nil
+	}
+	for t := range strings.SplitSeq(s, ",") {
+		t := EventTopic(strings.TrimSpace(t))
+		if _, ok := eventTopicMasks[t]; !ok {
+			return nil, fmt.Errorf("unknown event topic %q", t)
+		}
+		topics.Add(t)
+	}
+	return topics, nil
+}
+
+// Event is a message of the LocalAPI events stream. Only the fields of the
+// subscribed topics are ever set, and only those that changed.
+//
+// API maturity: this type is not considered a stable API and is
+// subject to change between releases.
+type Event struct {
+	// State is the new backend state, for EventTopicState.
+	State *State `json:",omitzero"`
+
+	// Health is the new health state, for EventTopicHealth.
+	Health *health.State `json:",omitzero"`
+
+	// ExitNodeID is the new exit node, for EventTopicExitNode. It's
+	// empty, but non-nil, when the exit node is turned off.
+	ExitNodeID *tailcfg.StableNodeID `json:",omitzero"`
+
+	// The rest are for EventTopicPeers, and as in [Notify].
+	SelfChange       *tailcfg.Node                              `json:",omitzero"`
+	PeersChanged     []*tailcfg.Node                            `json:",omitzero"`
+	PeersRemoved     []tailcfg.NodeID                           `json:",omitzero"`
+	PeerChangedPatch []*tailcfg.PeerChange                      `json:",omitzero"`
+	UserProfiles     map[tailcfg.UserID]tailcfg.UserProfileView `json:",omitzero"`
+}
+
+// EventFilter turns the [Notify] messages of an IPN bus watch into the
+// Events of a stream subscribed to a set of topics.
+type EventFilter struct {
+	topics       set.Set[EventTopic]
+	haveExitNode bool // whether exitNode has been set from prefs
+	exitNode     tailcfg.StableNodeID
+}
+
+// NewEventFilter returns an EventFilter for topics.
+func NewEventFilter(topics set.Set[EventTopic]) *EventFilter {
+	return &EventFilter{topics: topics}
+}
+
+// WatchOpt returns the options to watch the IPN bus with for f's topics.
+func (f *EventFilter) WatchOpt() NotifyWatchOpt {
+	var mask NotifyWatchOpt
+	for t := range f.topics {
+		mask |= eventTopicMasks[t]
+	}
+	return mask
+}
+
+// Event returns the Event that n carries for f's topics, if any.
+//
+// The exit node is reported from the first prefs n carries, which the
+// initial message of the watch has, and then only when it changes, as prefs
+// are sent on the IPN bus for many other reasons.
+func (f *EventFilter) Event(n *Notify) (ev Event, ok bool) {
+	if f.topics.Contains(EventTopicState) && n.State != nil {
+		ev.State, ok = n.State, true
+	}
+	if f.topics.Contains(EventTopicHealth) && n.Health != nil {
+		ev.Health, ok = n.Health, true
+	}
+	if f.topics.Contains(EventTopicExitNode) && n.Prefs != nil && n.Prefs.Valid() {
+		if id := n.Prefs.ExitNodeID(); !f.haveExitNode || id != f.exitNode {
+			f.haveExitNode, f.exitNode = true, id
+			ev.ExitNodeID, ok = &id, true
+		}
+	}
+	if f.topics.Contains(EventTopicPeers) {
+		ev.SelfChange = n.SelfChange
+		ev.PeersChanged = n.PeersChanged
+		ev.PeersRemoved = n.PeersRemoved
+		ev.PeerChangedPatch = n.PeerChangedPatch
+		ev.UserProfiles = n.UserProfiles
+		ok = ok || n.SelfChange != nil || len(n.PeersChanged) > 0 || len(n.PeersRemoved) > 0 ||
+			len(n.PeerChangedPatch) > 0 || len(n.UserProfiles) > 0
+	}
+	return ev, ok
+}
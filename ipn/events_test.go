@@ -0,0 +1,73 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ipn
+
+import (
+	"reflect"
+	"testing"
+
+	"tailscale.com/health"
+	"tailscale.com/tailcfg"
+	"tailscale.com/util/set"
+)
+
+func TestParseEventTopics(t *testing.T) {
+	got, err := ParseEventTopics("peers, exitnode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := set.Of(EventTopicPeers, EventTopicExitNode); !got.Equal(want) {
+		t.Errorf("got %v; want %v", got.Slice(), want.Slice())
+	}
+	if got := NewEventFilter(got).WatchOpt(); got != NotifyPeerPatches|NotifyInitialPrefs {
+		t.Errorf("WatchOpt = %v", got)
+	}
+
+	all, err := ParseEventTopics("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != len(eventTopicMasks) {
+		t.Errorf("empty topics = %v; want all", all.Slice())
+	}
+
+	if _, err := ParseEventTopics("peers,bogus"); err == nil {
+		t.Error("unknown topic: got nil error")
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	prefs := func(exitNode tailcfg.StableNodeID) *PrefsView {
+		pv := (&Prefs{ExitNodeID: exitNode}).View()
+		return &pv
+	}
+	running := Running
+	exit := tailcfg.StableNodeID("nExit")
+	none := tailcfg.StableNodeID("")
+
+	f := NewEventFilter(set.Of(EventTopicState, EventTopicExitNode))
+	steps := []struct {
+		name   string
+		n      *Notify
+		want   Event
+		wantOK bool
+	}{
+		{"initial", &Notify{State: &running, Prefs: prefs("")}, Event{State: &running, ExitNodeID: &none}, true},
+		{"exit-node-on", &Notify{Prefs: prefs(exit)}, Event{ExitNodeID: &exit}, true},
+		{"prefs-unchanged-exit-node", &Notify{Prefs: prefs(exit)}, Event{}, false},
+		{"other-topic", &Notify{Health: &health.State{}, PeersRemoved: []tailcfg.NodeID{1}}, Event{}, false},
+	}
+	for _, st := range steps {
+		got, ok := f.Event(st.n)
+		if ok != st.wantOK || !reflect.DeepEqual(got, st.want) {
+			t.Errorf("%s: got (%+v, %v); want (%+v, %v)", st.name, got, ok, st.want, st.wantOK)
+		}
+	}
+
+	peers := NewEventFilter(set.Of(EventTopicPeers))
+	got, ok := peers.Event(&Notify{State: &running, PeersRemoved: []tailcfg.NodeID{1}})
+	if want := (Event{PeersRemoved: []tailcfg.NodeID{1}}); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("peers: got (%+v, %v); want (%+v, true)", got, ok, want)
+	}
+}
@@ -356,6 +356,12 @@ type LocalBackend struct {
 	componentLogUntil map[string]componentLogState
 	currentUser       ipnauth.Actor
 	peerWGStateQueue  execqueue.ExecQueue // serializes WireGuard state transitions from wireguard-go
+
+	// withdrawSubnetRoutes is whether the routehealth extension has found
+	// the upstream network unhealthy, so subnet routes are left out of the
+	// Hostinfo sent to control. See [SubnetRouteHealth].
+	withdrawSubnetRoutes bool
+
 	// peerWGState is the current non-zero WireGuard session state per peer,
 	// keyed by stable node ID for delivery on the IPN bus.
 	// Entries are added/updated by [LocalBackend.handlePeerWireGuardState]
@@ -709,6 +715,9 @@ func NewLocalBackend(logf logger.Logf, logID logid.PublicID, sys *tsd.System, lo
 	if buildfeatures.HasPortList {
 		eventbus.SubscribeFunc(ec, b.setPortlistServices)
 	}
+	if buildfeatures.HasRouteHealth {
+		eventbus.SubscribeFunc(ec, b.setSubnetRouteHealth)
+	}
 	eventbus.SubscribeFunc(ec, b.onAppConnectorRouteUpdate)
 	eventbus.SubscribeFunc(ec, b.onAppConnectorStoreRoutes)
 	eventbus.SubscribeFunc(ec, b.onHomeDERPUpdate)
@@ -5681,6 +5690,31 @@ func (b *LocalBackend) setPortlistServices(sl []tailcfg.Service) {
 	b.doSetHostinfoFilterServices()
 }
 
+// SubnetRouteHealth is an eventbus topic for the routehealth extension to
+// report whether this node's upstream network is healthy enough for it to
+// keep advertising its subnet routes.
+type SubnetRouteHealth struct {
+	// Withdraw is whether to stop advertising subnet routes to control, so
+	// that another router advertising the same routes becomes primary for
+	// them. Exit node routes are still advertised.
+	Withdraw bool
+}
+
+func (b *LocalBackend) setSubnetRouteHealth(h SubnetRouteHealth) {
+	if !buildfeatures.HasRouteHealth { // redundant, but explicit for linker deadcode and humans
+		return
+	}
+
+	b.mu.Lock()
+	changed := b.withdrawSubnetRoutes != h.Withdraw
+	b.withdrawSubnetRoutes = h.Withdraw
+	b.mu.Unlock()
+
+	if changed {
+		b.doSetHostinfoFilterServices()
+	}
+}
+
 // doSetHostinfoFilterServices calls SetHostinfo on the controlclient,
 // possibly after mangling the given hostinfo.
 //
@@ -5734,6 +5768,12 @@ func (b *LocalBackend) hostInfoWithServicesLocked() *tailcfg.Hostinfo {
 	hi.Services = append(hi.Services[:c:c], peerAPIServices...)
 	hi.PushDeviceToken = b.pushDeviceToken.Load()
 
+	if b.withdrawSubnetRoutes && len(hi.RoutableIPs) > 0 {
+		hi.RoutableIPs = slices.DeleteFunc(slices.Clone(hi.RoutableIPs), func(p netip.Prefix) bool {
+			return !tsaddr.IsExitRoute(p)
+		})
+	}
+
 	// Compare the expected ports from peerAPIServices to the actual ports in hi.Services.
 	expectedPorts := extractPeerAPIPorts(peerAPIServices)
 	actualPorts := extractPeerAPIPorts(hi.Services)
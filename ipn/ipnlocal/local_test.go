@@ -9604,3 +9604,31 @@ func TestRouterConfigExitNodeBlackhole(t *testing.T) {
 		t.Errorf("no exit node: Routes = %v; want no default routes", rcfg.Routes)
 	}
 }
+
+func TestSubnetRouteHealthWithdrawsRoutes(t *testing.T) {
+	b := newTestLocalBackend(t)
+	subnet := netip.MustParsePrefix("10.0.0.0/24")
+	routes := []netip.Prefix{subnet, tsaddr.AllIPv4(), tsaddr.AllIPv6()}
+	b.mu.Lock()
+	b.hostinfo = &tailcfg.Hostinfo{RoutableIPs: routes}
+	b.mu.Unlock()
+
+	routableIPs := func() []netip.Prefix {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.hostInfoWithServicesLocked().RoutableIPs
+	}
+
+	b.setSubnetRouteHealth(SubnetRouteHealth{Withdraw: true})
+	if got, want := routableIPs(), []netip.Prefix{tsaddr.AllIPv4(), tsaddr.AllIPv6()}; !slices.Equal(got, want) {
+		t.Errorf("withdrawn RoutableIPs = %v; want %v", got, want)
+	}
+	if got := b.hostinfo.RoutableIPs; !slices.Equal(got, routes) {
+		t.Errorf("b.hostinfo.RoutableIPs mutated to %v", got)
+	}
+
+	b.setSubnetRouteHealth(SubnetRouteHealth{Withdraw: false})
+	if got := routableIPs(); !slices.Equal(got, routes) {
+		t.Errorf("restored RoutableIPs = %v; want %v", got, routes)
+	}
+}
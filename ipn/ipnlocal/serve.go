@@ -35,6 +35,7 @@
 
 	"github.com/pires/go-proxyproto"
 	"go4.org/mem"
+	"tailscale.com/feature"
 	"tailscale.com/ipn"
 	"tailscale.com/net/netmon"
 	"tailscale.com/net/netutil"
@@ -53,6 +54,7 @@
 )
 
 func init() {
+	feature.Register("funnel")
 	hookServeTCPHandlerForVIPService.Set((*LocalBackend).tcpHandlerForVIPService)
 	hookTCPHandlerForServe.Set((*LocalBackend).tcpHandlerForServe)
 	hookServeUpdateServeTCPPortNetMapAddrListenersLocked.Set((*LocalBackend).updateServeTCPPortNetMapAddrListenersLocked)
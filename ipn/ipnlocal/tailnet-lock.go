@@ -853,20 +853,10 @@ func (b *LocalBackend) TailnetLockSign(nodeKey key.NodePublic, rotationPublic []
 			return key.NodePublic{}, tka.NodeKeySignature{}, errors.New(tsconst.TailnetLockNotTrustedMsg)
 		}
 
-		p, err := nodeKey.MarshalBinary()
+		sig, err := tka.SignNodeKey(nlPriv, nodeKey, rotationPublic)
 		if err != nil {
 			return key.NodePublic{}, tka.NodeKeySignature{}, err
 		}
-		sig := tka.NodeKeySignature{
-			SigKind:        tka.SigDirect,
-			KeyID:          nlPriv.KeyID(),
-			Pubkey:         p,
-			WrappingPubkey: rotationPublic,
-		}
-		sig.Signature, err = nlPriv.SignNKS(sig.SigHash())
-		if err != nil {
-			return key.NodePublic{}, tka.NodeKeySignature{}, fmt.Errorf("signature failed: %w", err)
-		}
 
 		return b.pm.CurrentPrefs().Persist().PublicNodeKey(), sig, nil
 	}(nodeKey, rotationPublic)
@@ -881,6 +871,35 @@ func (b *LocalBackend) TailnetLockSign(nodeKey key.NodePublic, rotationPublic []
 	return nil
 }
 
+// TailnetLockSubmitSignature submits a node-key signature made elsewhere,
+// such as with [tka.SignNodeKey] and a signer backed by a hardware key,
+// to the control plane. The signature must authorize nodeKey under the
+// current key authority.
+func (b *LocalBackend) TailnetLockSubmitSignature(nodeKey key.NodePublic, sig tkatype.MarshaledSignature) error {
+	ourNodeKey, err := func() (key.NodePublic, error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.tka == nil {
+			return key.NodePublic{}, errTailnetLockNotActive
+		}
+		if err := b.tka.authority.NodeKeyAuthorized(nodeKey, sig); err != nil {
+			return key.NodePublic{}, fmt.Errorf("signature does not authorize %v: %w", nodeKey, err)
+		}
+		p := b.pm.CurrentPrefs()
+		if !p.Valid() || !p.Persist().Valid() {
+			return key.NodePublic{}, errMissingNetmap
+		}
+		return p.Persist().PublicNodeKey(), nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	b.logf("Submitting tailnet-lock signature for %v to control plane", nodeKey)
+	_, err = b.tkaSubmitSignature(ourNodeKey, sig)
+	return err
+}
+
 // Deprecated: use [LocalBackend.TailnetLockSign] instead.
 func (b *LocalBackend) NetworkLockSign(nodeKey key.NodePublic, rotationPublic []byte) error {
 	return b.TailnetLockSign(nodeKey, rotationPublic)
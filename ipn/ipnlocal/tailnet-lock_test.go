@@ -839,6 +839,66 @@ func TestTKASign(t *testing.T) {
 	}
 }
 
+func TestTKASubmitSignature(t *testing.T) {
+	nodePriv := key.NewNode()
+	toSign := key.NewNode()
+	nlPriv := key.NewNLPrivate()
+	hsmPriv := key.NewNLPrivate() // stands in for a key held by hardware
+
+	pm := setupProfileManager(t, nodePriv, nlPriv)
+
+	// Make a fake TKA authority, to seed local state.
+	state := tka.CreateStateForTest(
+		tka.Key{Kind: tka.Key25519, Public: nlPriv.Public().Verifier(), Votes: 2},
+		tka.Key{Kind: tka.Key25519, Public: hsmPriv.Public().Verifier(), Votes: 1},
+	)
+
+	varRoot, chonk := setupChonkStorage(t, pm)
+	authority, _, err := tka.Create(chonk, state, nlPriv)
+	if err != nil {
+		t.Fatalf("tka.Create() failed: %v", err)
+	}
+
+	ts, client := fakeNoiseServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch r.URL.Path {
+		case "/machine/tka/sign":
+			_, _, err := tkatest.HandleTKASign(w, r, authority)
+			if err != nil {
+				t.Errorf("HandleTKASign: %v", err)
+			}
+
+		default:
+			t.Errorf("unhandled endpoint path: %v", r.URL.Path)
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	b := newLocalBackendForTKA(t, varRoot, client, pm, authority, chonk)
+
+	sig, err := tka.SignNodeKey(hsmPriv, toSign.Public(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.TailnetLockSubmitSignature(toSign.Public(), sig.Serialize()); err != nil {
+		t.Errorf("TailnetLockSubmitSignature() failed: %v", err)
+	}
+
+	// A signature for another node key, or by an untrusted key, is
+	// rejected before it's sent to control.
+	if err := b.TailnetLockSubmitSignature(key.NewNode().Public(), sig.Serialize()); err == nil {
+		t.Error("TailnetLockSubmitSignature() for wrong node key succeeded")
+	}
+	sig, err = tka.SignNodeKey(key.NewNLPrivate(), toSign.Public(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.TailnetLockSubmitSignature(toSign.Public(), sig.Serialize()); err == nil {
+		t.Error("TailnetLockSubmitSignature() with untrusted key succeeded")
+	}
+}
+
 func TestTKAForceDisable(t *testing.T) {
 	nodePriv := key.NewNode()
 
@@ -38,6 +38,7 @@
 	"tailscale.com/net/neterror"
 	"tailscale.com/net/netns"
 	"tailscale.com/net/netutil"
+	"tailscale.com/net/preflight"
 	"tailscale.com/tailcfg"
 	"tailscale.com/tstime"
 	"tailscale.com/types/appctype"
@@ -93,6 +94,7 @@
 	"start":                (*Handler).serveStart,
 	"status":               (*Handler).serveStatus,
 	"user-profile":         (*Handler).serveUserProfile,
+	"version-meta":         (*Handler).serveVersionMeta,
 	"whois":                (*Handler).serveWhoIs,
 }
 
@@ -122,8 +124,12 @@ func init() {
 		Register("bugreport", (*Handler).serveBugReport)
 		Register("pprof", (*Handler).servePprof)
 	}
+	if buildfeatures.HasHealth {
+		Register("health", (*Handler).serveHealth)
+	}
 	if buildfeatures.HasIPNBus {
 		Register("watch-ipn-bus", (*Handler).serveWatchIPNBus)
+		Register("events", (*Handler).serveEvents)
 	}
 	if buildfeatures.HasDNS {
 		Register("dns-osconfig", (*Handler).serveDNSOSConfig)
@@ -161,6 +167,7 @@ func init() {
 		// want just this. They could "tail -f" or "journalctl -f" their logs
 		// themselves.
 		Register("logtap", (*Handler).serveLogTap)
+		Register("logs", (*Handler).serveLogs)
 	}
 }
 
@@ -392,6 +399,10 @@ func (h *Handler) serveIDToken(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// bugReportEnvTimeout is how long serveBugReport waits to log the host's
+// [hostinfo.EnvironmentFacts].
+const bugReportEnvTimeout = 5 * time.Second
+
 func (h *Handler) serveBugReport(w http.ResponseWriter, r *http.Request) {
 	if !h.PermitRead {
 		http.Error(w, "bugreport access denied", http.StatusForbidden)
@@ -441,6 +452,23 @@ func (h *Handler) serveBugReport(w http.ResponseWriter, r *http.Request) {
 	// OS-specific details
 	h.logf.JSON(1, "UserBugReportOS", osdiag.SupportInfo(osdiag.LogSupportInfoReasonBugReport))
 
+	// Facts about the host environment, and whether the LocalAPI can be
+	// connected to as the CLI does. Some of them run other programs, so
+	// don't hold up the bugreport for long if those are slow.
+	envc := make(chan hostinfo.EnvironmentFacts, 1)
+	go func() {
+		env := osdiag.EnvironmentFacts()
+		_, env.LocalAPIUnreachable = preflight.LocalAPIReachable()
+		envc <- env
+	}()
+	select {
+	case env := <-envc:
+		h.logf.JSON(1, "UserBugReportEnv", env)
+	case <-time.After(bugReportEnvTimeout):
+		h.logf("user bugreport: environment facts not gathered within %v", bugReportEnvTimeout)
+	case <-r.Context().Done():
+	}
+
 	// Tailnet Lock details
 	st := h.b.TailnetLockStatus()
 	if st.Enabled {
@@ -679,6 +707,39 @@ func (h *Handler) serveLogTap(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// serveLogs returns the recent tailscaled/logtail output kept in memory
+// (see TS_LOG_RING_SIZE), in the same format as serveLogTap. The optional
+// "since" parameter, in RFC 3339 format, limits it to the logs written
+// after that time.
+func (h *Handler) serveLogs(w http.ResponseWriter, r *http.Request) {
+	// Require write access (~root), as for logtap.
+	if !h.PermitWrite {
+		http.Error(w, "logs access denied", http.StatusForbidden)
+		return
+	}
+	if r.Method != httpm.GET {
+		http.Error(w, "GET required", http.StatusMethodNotAllowed)
+		return
+	}
+	var since time.Time
+	if v := r.FormValue("since"); v != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	logs, ok := logtail.RecentLogs(since)
+	if !ok {
+		http.Error(w, "recent logs not kept; set TS_LOG_RING_SIZE", http.StatusNotImplemented)
+		return
+	}
+	for _, l := range logs {
+		io.WriteString(w, l.JSON)
+	}
+}
+
 func (h *Handler) serveMetrics(w http.ResponseWriter, r *http.Request) {
 	metricDebugMetricsCalls.Add(1)
 	// Require write access out of paranoia that the metrics
@@ -786,6 +847,22 @@ func (h *Handler) serveCheckIPForwarding(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// serveHealth returns the current [health.State] as JSON: each unhealthy
+// Warnable by its stable code, with its severity and since when it's been
+// unhealthy.
+func (h *Handler) serveHealth(w http.ResponseWriter, r *http.Request) {
+	if !h.PermitRead {
+		http.Error(w, "health access denied", http.StatusForbidden)
+		return
+	}
+	if r.Method != httpm.GET {
+		http.Error(w, "want GET", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.b.HealthTracker().CurrentState())
+}
+
 // serveCheckSOMarkInUse reports whether SO_MARK is in use on the linux while
 // running without TUN. For any other OS, it reports false.
 func (h *Handler) serveCheckSOMarkInUse(w http.ResponseWriter, r *http.Request) {
@@ -933,6 +1010,50 @@ func (h *Handler) serveWatchIPNBus(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// serveEvents streams the changes to the backend for the topics in the
+// comma-separated "topics" parameter (all of them if it's empty), as lines
+// of JSON-encoded [ipn.Event]s. Unlike serveWatchIPNBus, which sends whole
+// Notify messages, it filters them down to the subscribed topics, so that
+// subscribers only decode what they asked for.
+func (h *Handler) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if !h.PermitRead {
+		http.Error(w, "events access denied", http.StatusForbidden)
+		return
+	}
+	if r.Method != httpm.GET {
+		http.Error(w, "want GET", http.StatusBadRequest)
+		return
+	}
+	f, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "not a flusher", http.StatusInternalServerError)
+		return
+	}
+	topics, err := ipn.ParseEventTopics(r.FormValue("topics"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter := ipn.NewEventFilter(topics)
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	h.b.WatchNotificationsAs(r.Context(), h.Actor, filter.WatchOpt(), f.Flush, func(roNotify *ipn.Notify) (keepGoing bool) {
+		ev, ok := filter.Event(roNotify)
+		if !ok {
+			return true
+		}
+		if err := enc.Encode(ev); err != nil {
+			if !neterror.IsClosedPipeError(err) {
+				h.logf("json.Encode: %v", err)
+			}
+			return false
+		}
+		f.Flush()
+		return true
+	})
+}
+
 func (h *Handler) serveLoginInteractive(w http.ResponseWriter, r *http.Request) {
 	if !h.PermitWrite {
 		http.Error(w, "login access denied", http.StatusForbidden)
@@ -1689,6 +1810,23 @@ func (h *Handler) serveUpdateCheck(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(cv)
 }
 
+// serveVersionMeta serves the [version.Meta] of tailscaled, including how it
+// was installed and whether "tailscale update" can update it.
+func (h *Handler) serveVersionMeta(w http.ResponseWriter, r *http.Request) {
+	if !h.PermitRead {
+		http.Error(w, "version-meta access denied", http.StatusForbidden)
+		return
+	}
+	if r.Method != httpm.GET {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	m := version.GetMeta()
+	m.SelfUpdate = feature.CanAutoUpdate()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
 // serveDNSOSConfig serves the current system DNS configuration as a JSON object, if
 // supported by the OS.
 func (h *Handler) serveDNSOSConfig(w http.ResponseWriter, r *http.Request) {
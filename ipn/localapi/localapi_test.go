@@ -39,6 +39,7 @@
 	"tailscale.com/types/logid"
 	"tailscale.com/util/eventbus/eventbustest"
 	"tailscale.com/util/slicesx"
+	"tailscale.com/version"
 	"tailscale.com/wgengine"
 )
 
@@ -521,6 +522,31 @@ func TestServeUserProfile(t *testing.T) {
 	})
 }
 
+func TestServeVersionMeta(t *testing.T) {
+	for _, permitRead := range []bool{true, false} {
+		h := handlerForTest(t, &Handler{PermitRead: permitRead})
+		rec := httptest.NewRecorder()
+		h.serveVersionMeta(rec, httptest.NewRequest("GET", "/v0/version-meta", nil))
+		wantCode := http.StatusOK
+		if !permitRead {
+			wantCode = http.StatusForbidden
+		}
+		if rec.Code != wantCode {
+			t.Fatalf("PermitRead=%v: status = %d, want %d", permitRead, rec.Code, wantCode)
+		}
+		if !permitRead {
+			continue
+		}
+		var got version.Meta
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("unmarshal body %q: %v", rec.Body.Bytes(), err)
+		}
+		if got.InstallSource != version.InstallSource() {
+			t.Errorf("InstallSource = %q, want %q", got.InstallSource, version.InstallSource())
+		}
+	}
+}
+
 func TestShouldDenyServeConfigForGOOSAndUserContext(t *testing.T) {
 	newHandler := func(connIsLocalAdmin bool) *Handler {
 		return handlerForTest(t, &Handler{
@@ -708,6 +734,102 @@ func TestServeWatchIPNBus(t *testing.T) {
 	}
 }
 
+func TestServeEvents(t *testing.T) {
+	tstest.Replace(t, &validLocalHostForTesting, true)
+	tests := []struct {
+		desc       string
+		permitRead bool
+		topics     string
+		wantStatus int
+	}{
+		{desc: "no-permission", topics: "state", wantStatus: http.StatusForbidden},
+		{desc: "state", permitRead: true, topics: "state", wantStatus: http.StatusOK},
+		{desc: "unknown-topic", permitRead: true, topics: "state,bogus", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			h := handlerForTest(t, &Handler{
+				PermitRead: tt.permitRead,
+				b:          newTestLocalBackend(t),
+			})
+			s := httptest.NewServer(h)
+			defer s.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, "GET", s.URL+"/localapi/v0/events?topics="+tt.topics, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := s.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			if res.StatusCode != tt.wantStatus {
+				body, _ := io.ReadAll(res.Body)
+				t.Fatalf("res.StatusCode=%d, want %d. body: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+			// The initial state is the first event, and the only field set.
+			var ev map[string]any
+			if err := json.NewDecoder(res.Body).Decode(&ev); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := ev["State"]; !ok || len(ev) != 1 {
+				t.Errorf("first event = %v; want only State", ev)
+			}
+		})
+	}
+}
+
+func TestServeHealth(t *testing.T) {
+	tstest.Replace(t, &validLocalHostForTesting, true)
+	tests := []struct {
+		desc       string
+		permitRead bool
+		method     string
+		wantStatus int
+	}{
+		{desc: "no-permission", method: "GET", wantStatus: http.StatusForbidden},
+		{desc: "get", permitRead: true, method: "GET", wantStatus: http.StatusOK},
+		{desc: "post", permitRead: true, method: "POST", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			h := handlerForTest(t, &Handler{
+				PermitRead: tt.permitRead,
+				b:          newTestLocalBackend(t),
+			})
+			s := httptest.NewServer(h)
+			defer s.Close()
+
+			req, err := http.NewRequest(tt.method, s.URL+"/localapi/v0/health", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := s.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			if res.StatusCode != tt.wantStatus {
+				body, _ := io.ReadAll(res.Body)
+				t.Fatalf("res.StatusCode=%d, want %d. body: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+			var st health.State
+			if err := json.NewDecoder(res.Body).Decode(&st); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
 func newTestLocalBackend(t testing.TB) *ipnlocal.LocalBackend {
 	var logf logger.Logf = logger.Discard
 	sys := tsd.NewSystemWithBus(eventbustest.NewBus(t))
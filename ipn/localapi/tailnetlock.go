@@ -29,6 +29,7 @@ func init() {
 	Register("tka/sign", (*Handler).serveTKASign)
 	Register("tka/status", (*Handler).serveTKAStatus)
 	Register("tka/submit-recovery-aum", (*Handler).serveTKASubmitRecoveryAUM)
+	Register("tka/submit-signature", (*Handler).serveTKASubmitSignature)
 	Register("tka/verify-deeplink", (*Handler).serveTKAVerifySigningDeeplink)
 	Register("tka/wrap-preauth-key", (*Handler).serveTKAWrapPreauthKey)
 }
@@ -80,6 +81,34 @@ type signRequest struct {
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h *Handler) serveTKASubmitSignature(w http.ResponseWriter, r *http.Request) {
+	if !h.PermitWrite {
+		http.Error(w, "lock sign access denied", http.StatusForbidden)
+		return
+	}
+	if r.Method != httpm.POST {
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	type submitRequest struct {
+		NodeKey   key.NodePublic
+		Signature tkatype.MarshaledSignature
+	}
+	var req submitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.b.TailnetLockSubmitSignature(req.NodeKey, req.Signature); err != nil {
+		http.Error(w, "submitting signature failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func (h *Handler) serveTKAInit(w http.ResponseWriter, r *http.Request) {
 	if !h.PermitWrite {
 		http.Error(w, "lock init access denied", http.StatusForbidden)
@@ -7,6 +7,7 @@
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"sync"
 
 	xmaps "golang.org/x/exp/maps"
@@ -78,6 +79,17 @@ func (s *Store) LoadFromJSON(data []byte) error {
 	return json.Unmarshal(data, &s.cache)
 }
 
+// LoadFromReader is like LoadFromJSON, but reads the JSON from r. It lets
+// tsnet and other embedders supply state from their own database or
+// secrets manager, in the format written by ExportToJSON.
+func (s *Store) LoadFromReader(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return s.LoadFromJSON(data)
+}
+
 // ExportToJSON exports the content of the cache to
 // JSON formatted []byte.
 func (s *Store) ExportToJSON() ([]byte, error) {
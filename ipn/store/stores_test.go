@@ -4,6 +4,7 @@
 package store
 
 import (
+	"bytes"
 	"maps"
 	"path/filepath"
 	"testing"
@@ -172,6 +173,28 @@ func TestMemoryStore(t *testing.T) {
 	testStoreDeleteSemantics(t, store)
 }
 
+func TestMemoryStoreLoadFromReader(t *testing.T) {
+	src := new(mem.Store)
+	if err := src.WriteState("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := src.ExportToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := new(mem.Store)
+	if err := store.LoadFromReader(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.ReadState("foo"); err != nil || string(got) != "bar" {
+		t.Errorf("ReadState(foo) = %q, %v; want %q", got, err, "bar")
+	}
+	if err := store.LoadFromReader(bytes.NewReader([]byte("not json"))); err == nil {
+		t.Error("LoadFromReader with bad JSON succeeded")
+	}
+}
+
 func TestFileStore(t *testing.T) {
 	tstest.PanicOnLog()
 
@@ -31,6 +31,7 @@
 	jsonv2 "github.com/go-json-experiment/json"
 	"github.com/go-json-experiment/json/jsontext"
 	"tailscale.com/envknob"
+	"tailscale.com/feature"
 	"tailscale.com/metrics"
 	"tailscale.com/net/netmon"
 	"tailscale.com/net/sockstats"
@@ -38,11 +39,16 @@
 	tslogger "tailscale.com/types/logger"
 	"tailscale.com/types/logid"
 	"tailscale.com/util/eventbus"
+	"tailscale.com/util/ringlog"
 	"tailscale.com/util/set"
 	"tailscale.com/util/truncate"
 	"tailscale.com/util/zstdframe"
 )
 
+func init() {
+	feature.Register("logupload")
+}
+
 // maxSize is the maximum size that a single log entry can be.
 // It is also the maximum body size that may be uploaded at a time.
 const maxSize = 256 << 10
@@ -783,6 +789,9 @@ func (lg *Logger) tryDrainWake() {
 
 func (lg *Logger) sendLocked(jsonBlob []byte) (int, error) {
 	tapSend(jsonBlob)
+	if rl := recentLogs(); rl != nil {
+		rl.Add(RecentLog{Time: lg.clock.Now(), JSON: string(jsonBlob)})
+	}
 	if logtailDisabled.Load() || lg.disabled.Load() {
 		return len(jsonBlob), nil
 	}
@@ -1112,6 +1121,39 @@ func RegisterLogTap(dst chan<- string) (unregister func()) {
 	}
 }
 
+// RecentLog is a log write kept in memory for [RecentLogs].
+type RecentLog struct {
+	Time time.Time // when it was written
+	JSON string    // as sent to log taps
+}
+
+var logRingSize = envknob.RegisterInt("TS_LOG_RING_SIZE")
+
+// recentLogs returns the ring buffer of recent log writes, or nil if
+// TS_LOG_RING_SIZE isn't set to a positive number of writes to keep.
+var recentLogs = sync.OnceValue(func() *ringlog.RingLog[RecentLog] {
+	if n := logRingSize(); n > 0 {
+		return ringlog.New[RecentLog](n)
+	}
+	return nil
+})
+
+// RecentLogs returns the log writes kept in memory that were written after
+// since, oldest first. They're kept whether or not logs are uploaded, for
+// the last TS_LOG_RING_SIZE writes. If that's not set, ok is false.
+func RecentLogs(since time.Time) (logs []RecentLog, ok bool) {
+	rl := recentLogs()
+	if rl == nil {
+		return nil, false
+	}
+	for _, l := range rl.GetAll() {
+		if l.Time.After(since) {
+			logs = append(logs, l)
+		}
+	}
+	return logs, true
+}
+
 // tapSend relays the JSON blob to any/all registered local debug log watchers
 // (somebody running "tailscale debug daemon-logs").
 func tapSend(jsonBlob []byte) {
@@ -60,4 +60,11 @@ func RegisterLogTap(dst chan<- string) (unregister func()) {
 	return func() {}
 }
 
+type RecentLog struct {
+	Time time.Time
+	JSON string
+}
+
+func RecentLogs(since time.Time) (logs []RecentLog, ok bool) { return nil, false }
+
 func (*Logger) SetNetMon(any) {}
@@ -26,6 +26,7 @@
 	"tailscale.com/tstime"
 	"tailscale.com/util/eventbus/eventbustest"
 	"tailscale.com/util/must"
+	"tailscale.com/util/ringlog"
 )
 
 // TestMain installs a safety net that refuses non-localhost dials for any
@@ -666,6 +667,38 @@ func TestLoggerSetEnabled(t *testing.T) {
 	}
 }
 
+func TestRecentLogs(t *testing.T) {
+	rl := ringlog.New[RecentLog](2)
+	tstest.Replace(t, &recentLogs, func() *ringlog.RingLog[RecentLog] { return rl })
+
+	clock := tstest.NewClock(tstest.ClockOpts{Start: time.Unix(123, 0)})
+	lg := &Logger{
+		clock:  clock,
+		buffer: NewMemoryBuffer(100),
+	}
+	lg.SetEnabled(false) // kept even when not uploaded
+	for _, msg := range []string{"one", "two", "three"} {
+		clock.Advance(time.Second)
+		if _, err := lg.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logs, ok := RecentLogs(time.Unix(125, 0))
+	if !ok {
+		t.Fatal("RecentLogs not ok")
+	}
+	// "one" fell out of the ring and "two" isn't after since.
+	if len(logs) != 1 || !strings.Contains(logs[0].JSON, "three") {
+		t.Errorf("got %v; want just three", logs)
+	}
+
+	tstest.Replace(t, &recentLogs, func() *ringlog.RingLog[RecentLog] { return nil })
+	if _, ok := RecentLogs(time.Time{}); ok {
+		t.Error("RecentLogs ok with no ring buffer")
+	}
+}
+
 func TestAppendMetadata(t *testing.T) {
 	var lg Logger
 	lg.clock = tstest.NewClock(tstest.ClockOpts{Start: time.Date(2000, 01, 01, 0, 0, 0, 0, time.UTC)})
@@ -10,6 +10,7 @@
 	"context"
 	"net"
 	"net/http"
+	"net/url"
 	"runtime"
 	"strconv"
 	"strings"
@@ -116,6 +117,39 @@ func (d *Detector) detectCaptivePortalWithGOOS(ctx context.Context, netMon *netm
 	return false
 }
 
+// DefaultProbeEndpoint is the endpoint that [BehindCaptivePortal] probes: the
+// Tailscale coordination server's generate_204 handler, fetched over plain
+// HTTP so that a portal can intercept it.
+var DefaultProbeEndpoint = Endpoint{
+	URL:        &url.URL{Scheme: "http", Host: "controlplane.tailscale.com", Path: "/generate_204"},
+	StatusCode: http.StatusNoContent,
+	Provider:   Tailscale,
+}
+
+// BehindCaptivePortal is a lightweight alternative to [Detector.Detect] for
+// connectivity diagnostics. It makes a single request to
+// [DefaultProbeEndpoint] over the default route and reports whether the
+// response looks like it came from a captive portal or a transparent proxy
+// rather than from Tailscale. See [Detector.ProbeEndpoint] to probe a
+// different endpoint, such as that of a custom control server.
+//
+// A non-nil error means the probe couldn't be completed, which is not
+// evidence either way. Conversely, any unexpected response is reported as a
+// portal, so a misbehaving network proxy or an endpoint that doesn't behave
+// as described can produce false positives.
+func BehindCaptivePortal(ctx context.Context) (bool, error) {
+	return NewDetector(logger.Discard).ProbeEndpoint(ctx, DefaultProbeEndpoint)
+}
+
+// ProbeEndpoint makes a single request to e over the default route and reports
+// whether the response looks like a captive portal's, as opposed to the
+// response that e is expected to return. Unlike [Detector.Detect], it does not
+// try each network interface.
+func (d *Detector) ProbeEndpoint(ctx context.Context, e Endpoint) (found bool, err error) {
+	defer d.httpClient.CloseIdleConnections()
+	return d.verifyCaptivePortalEndpoint(ctx, e, 0)
+}
+
 // interfaceNameDoesNotNeedCaptiveDetection returns true if an interface does not require captive portal detection
 // based on its name. This is useful to avoid making unnecessary HTTP requests on interfaces that are known to not
 // require it. We also avoid making requests on the interface prefixes "pdp" and "rmnet", which are cellular data
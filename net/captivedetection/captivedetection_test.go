@@ -129,6 +129,40 @@ func TestCaptivePortalRequest(t *testing.T) {
 	}
 }
 
+func TestProbeEndpoint(t *testing.T) {
+	portal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://portal.example/login", http.StatusFound)
+	}))
+	defer portal.Close()
+	clean := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer clean.Close()
+
+	for _, tt := range []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{"portal", portal.URL, true},
+		{"clean", clean.URL, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Endpoint{
+				URL:        must.Get(url.Parse(tt.url + "/generate_204")),
+				StatusCode: http.StatusNoContent,
+			}
+			found, err := NewDetector(t.Logf).ProbeEndpoint(t.Context(), e)
+			if err != nil {
+				t.Fatalf("ProbeEndpoint: %v", err)
+			}
+			if found != tt.want {
+				t.Errorf("ProbeEndpoint = %v, want %v", found, tt.want)
+			}
+		})
+	}
+}
+
 func TestAgainstDERPHandler(t *testing.T) {
 	d := NewDetector(t.Logf)
 
@@ -8,6 +8,7 @@
 	"context"
 	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/binary"
 	"errors"
@@ -20,6 +21,7 @@
 	"net/url"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -306,6 +308,11 @@ type forwarder struct {
 
 	controlKnobs *controlknobs.Knobs // or nil
 
+	// dotRootCAs, if non-nil, are the roots that DNS-over-TLS servers'
+	// certificates are verified against instead of the system's. It's only
+	// set in tests.
+	dotRootCAs *x509.CertPool
+
 	ctx       context.Context    // good until Close
 	ctxCancel context.CancelFunc // closes ctx
 
@@ -666,8 +673,15 @@ func (f *forwarder) send(ctx context.Context, fq *forwardQuery, rr resolverAndDe
 		return nil, fmt.Errorf("arbitrary https:// resolvers not supported yet")
 	}
 	if strings.HasPrefix(rr.name.Addr, "tls://") {
-		metricDNSFwdErrorType.Add(1)
-		return nil, fmt.Errorf("tls:// resolvers not supported yet")
+		metricDNSFwdDoT.Add(1)
+		res, err := f.sendTCP(ctx, fq, rr)
+		if err != nil {
+			return nil, err
+		}
+		// Like any answer over TCP, the response can be too large for a
+		// client that queried over UDP; truncate it for those.
+		res = checkResponseSizeAndSetTC(res, fq.packet, fq.family, f.logf)
+		return res, nil
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -926,8 +940,15 @@ func (f *forwarder) getDialerType() netx.DialFunc {
 }
 
 func (f *forwarder) sendTCP(ctx context.Context, fq *forwardQuery, rr resolverAndDelay) (ret []byte, err error) {
+	var tlsServerName string // non-empty for DNS over TLS
 	ipp, ok := rr.name.IPPort()
-	if !ok {
+	if dot, isDoT := strings.CutPrefix(rr.name.Addr, "tls://"); isDoT {
+		ipp, tlsServerName, err = dotAddr(dot, rr.name.BootstrapResolution)
+		if err != nil {
+			metricDNSFwdErrorType.Add(1)
+			return nil, err
+		}
+	} else if !ok {
 		metricDNSFwdErrorType.Add(1)
 		return nil, fmt.Errorf("unrecognized resolver type %q", rr.name.Addr)
 	}
@@ -959,6 +980,19 @@ func (f *forwarder) sendTCP(ctx context.Context, fq *forwardQuery, rr resolverAn
 		return nil, err2
 	}
 
+	if tlsServerName != "" {
+		tc := tls.Client(conn, &tls.Config{
+			ServerName: tlsServerName,
+			RootCAs:    f.dotRootCAs,
+			MinVersion: tls.VersionTLS12,
+		})
+		if err := tc.HandshakeContext(ctx); err != nil {
+			metricDNSFwdDoTErrorHandshake.Add(1)
+			return ctxOrErr(err)
+		}
+		conn = tc
+	}
+
 	// Write the query to the server.
 	query := make([]byte, len(fq.packet)+2)
 	binary.BigEndian.PutUint16(query, uint16(len(fq.packet)))
@@ -1017,6 +1051,36 @@ func (f *forwarder) sendTCP(ctx context.Context, fq *forwardQuery, rr resolverAn
 	return out, nil
 }
 
+// dotPort is the default port for DNS over TLS, per RFC 7858.
+const dotPort = 853
+
+// dotAddr returns the address to dial and the TLS server name to verify for
+// addr, the part of a "tls://" resolver address after the scheme: a host or
+// IP with an optional port. A hostname is dialed at the first of its
+// bootstrap addresses, as there's no resolver to look it up with.
+func dotAddr(addr string, bootstrap []netip.Addr) (_ netip.AddrPort, serverName string, _ error) {
+	host, port := addr, uint16(dotPort)
+	if h, p, err := net.SplitHostPort(addr); err == nil {
+		n, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return netip.AddrPort{}, "", fmt.Errorf("invalid port in DNS-over-TLS resolver %q", addr)
+		}
+		host, port = h, uint16(n)
+	} else {
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	}
+	if host == "" {
+		return netip.AddrPort{}, "", fmt.Errorf("invalid DNS-over-TLS resolver %q", addr)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return netip.AddrPortFrom(ip, port), host, nil
+	}
+	if len(bootstrap) == 0 {
+		return netip.AddrPort{}, "", fmt.Errorf("DNS-over-TLS resolver %q has no BootstrapResolution", addr)
+	}
+	return netip.AddrPortFrom(bootstrap[0], port), host, nil
+}
+
 // applySchemes resolves any custom-scheme entries in rrs using the provided
 // scheme handlers, returning the resulting slice. Entries whose handler returns
 // an error or empty string are dropped. Entries with no registered scheme pass
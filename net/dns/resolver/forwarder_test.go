@@ -6,11 +6,14 @@
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/binary"
 	"flag"
 	"fmt"
 	"io"
 	"net"
+	"net/http/httptest"
 	"net/netip"
 	"os"
 	"reflect"
@@ -238,6 +241,106 @@ func TestGetKnownDoHClientForProvider(t *testing.T) {
 	t.Logf("Got: %+v", res)
 }
 
+func TestDoTAddr(t *testing.T) {
+	bootstrap := []netip.Addr{netip.MustParseAddr("9.9.9.9")}
+	tests := []struct {
+		addr       string
+		bootstrap  []netip.Addr
+		want       string
+		serverName string
+		wantErr    bool
+	}{
+		{addr: "1.1.1.1", want: "1.1.1.1:853", serverName: "1.1.1.1"},
+		{addr: "1.1.1.1:8853", want: "1.1.1.1:8853", serverName: "1.1.1.1"},
+		{addr: "[2606:4700:4700::1111]", want: "[2606:4700:4700::1111]:853", serverName: "2606:4700:4700::1111"},
+		{addr: "[2606:4700:4700::1111]:8853", want: "[2606:4700:4700::1111]:8853", serverName: "2606:4700:4700::1111"},
+		{addr: "dns.quad9.net", bootstrap: bootstrap, want: "9.9.9.9:853", serverName: "dns.quad9.net"},
+		{addr: "dns.quad9.net:8853", bootstrap: bootstrap, want: "9.9.9.9:8853", serverName: "dns.quad9.net"},
+		{addr: "dns.quad9.net", wantErr: true},
+		{addr: "1.1.1.1:port", wantErr: true},
+		{addr: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, serverName, err := dotAddr(tt.addr, tt.bootstrap)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("dotAddr(%q) = %v, %q; want error", tt.addr, got, serverName)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("dotAddr(%q): %v", tt.addr, err)
+			continue
+		}
+		if got.String() != tt.want || serverName != tt.serverName {
+			t.Errorf("dotAddr(%q) = %v, %q; want %v, %q", tt.addr, got, serverName, tt.want, tt.serverName)
+		}
+	}
+}
+
+func TestForwarderDoT(t *testing.T) {
+	const domain = "dot.tailscale.com."
+	request := makeTestRequest(t, domain, dns.TypeA, 0)
+	response := makeTestResponse(t, domain, dns.RCodeSuccess, netip.MustParseAddr("100.64.0.1"))
+
+	// The httptest server is only used for its certificate, which is valid
+	// for example.com.
+	ts := httptest.NewUnstartedServer(nil)
+	ts.StartTLS()
+	defer ts.Close()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", ts.TLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				var n uint16
+				if err := binary.Read(c, binary.BigEndian, &n); err != nil {
+					return
+				}
+				got := make([]byte, n)
+				if _, err := io.ReadFull(c, got); err != nil {
+					return
+				}
+				if !bytes.Equal(got, request) {
+					t.Errorf("invalid request\ngot: %+v\nwant: %+v", got, request)
+				}
+				binary.Write(c, binary.BigEndian, uint16(len(response)))
+				c.Write(response)
+			}()
+		}
+	}()
+
+	resolver := &dnstype.Resolver{
+		Addr:                fmt.Sprintf("tls://example.com:%d", ln.Addr().(*net.TCPAddr).Port),
+		BootstrapResolution: []netip.Addr{netip.MustParseAddr("127.0.0.1")},
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+	trustServer := func(f *forwarder) { f.dotRootCAs = roots }
+
+	resp, err := runTestQueryWithResolvers(t, request, "udp", trustServer, resolver)
+	if err != nil {
+		t.Fatalf("error making request: %v", err)
+	}
+	if !bytes.Equal(resp, response) {
+		t.Errorf("invalid response\ngot: %+v\nwant: %+v", resp, response)
+	}
+
+	// The server's certificate isn't trusted without its root.
+	resp, err = runTestQueryWithResolvers(t, request, "udp", nil, resolver)
+	if err == nil && bytes.Equal(resp, response) {
+		t.Error("got response from untrusted DNS-over-TLS server")
+	}
+}
+
 // TestControlDPremiumDoHLive exercises the real DoH dial path against Control D's
 // live infrastructure for a premium resolver, to confirm end-to-end that we use
 // reachable DoH endpoints (see ESC-30: we previously synthesized per-resolver
@@ -565,6 +668,14 @@ func runTestQuery(tb testing.TB, request []byte, modify func(*forwarder), ports
 }
 
 func runTestQueryWithFamily(tb testing.TB, request []byte, family string, modify func(*forwarder), ports ...uint16) ([]byte, error) {
+	resolvers := make([]*dnstype.Resolver, len(ports))
+	for i, port := range ports {
+		resolvers[i] = &dnstype.Resolver{Addr: fmt.Sprintf("127.0.0.1:%d", port)}
+	}
+	return runTestQueryWithResolvers(tb, request, family, modify, resolvers...)
+}
+
+func runTestQueryWithResolvers(tb testing.TB, request []byte, family string, modify func(*forwarder), resolvers ...*dnstype.Resolver) ([]byte, error) {
 	logf := tstest.WhileTestRunningLogger(tb)
 	bus := eventbustest.NewBus(tb)
 	netMon, err := netmon.New(bus, logf)
@@ -581,9 +692,9 @@ func runTestQueryWithFamily(tb testing.TB, request []byte, family string, modify
 		modify(fwd)
 	}
 
-	resolvers := make([]resolverAndDelay, len(ports))
-	for i, port := range ports {
-		resolvers[i].name = &dnstype.Resolver{Addr: fmt.Sprintf("127.0.0.1:%d", port)}
+	rrs := make([]resolverAndDelay, len(resolvers))
+	for i, r := range resolvers {
+		rrs[i].name = r
 	}
 
 	rpkt := packet{
@@ -595,7 +706,7 @@ func runTestQueryWithFamily(tb testing.TB, request []byte, family string, modify
 	rchan := make(chan packet, 1)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	tb.Cleanup(cancel)
-	err = fwd.forwardWithDestChan(ctx, rpkt, rchan, resolvers...)
+	err = fwd.forwardWithDestChan(ctx, rpkt, rchan, rrs...)
 	select {
 	case res := <-rchan:
 		return res.bs, err
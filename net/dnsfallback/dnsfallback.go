@@ -22,6 +22,8 @@
 	"net/url"
 	"os"
 	"reflect"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -37,6 +39,22 @@
 	"tailscale.com/util/slicesx"
 )
 
+func init() {
+	feature.Register("embeddedderpmap")
+	feature.HookEmbeddedDERPRegions.Set(embeddedDERPRegions)
+}
+
+// embeddedDERPRegions returns the sorted region codes of the DERP map that
+// was compiled into this binary.
+func embeddedDERPRegions() []string {
+	var codes []string
+	for _, r := range getStaticDERPMap().Regions {
+		codes = append(codes, r.RegionCode)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
 // MakeLookupFunc creates a function that can be used to resolve hostnames
 // (e.g. as a LookupIPFallback from dnscache.Resolver).
 // The netMon parameter is optional; if non-nil it's used to do faster interface lookups.
@@ -73,13 +91,26 @@ type nameIP struct {
 		ip      netip.Addr
 	}
 
+	// On an IPv6-only network, reach the IPv4-only DERP servers, and
+	// return addresses for IPv4-only hosts, through the network's NAT64.
+	var nat64 netip.Prefix
+	if netMon != nil {
+		if st := netMon.InterfaceState(); st != nil && st.HaveV6 && !st.HaveV4 {
+			nat64 = nat64PrefixFor(ctx, st, logf)
+		}
+	}
+
 	dm := GetDERPMap()
 
 	var cands4, cands6 []nameIP
 	for _, dr := range dm.Regions {
 		for _, n := range dr.Nodes {
 			if ip, err := netip.ParseAddr(n.IPv4); err == nil {
-				cands4 = append(cands4, nameIP{n.HostName, ip})
+				if nat64.IsValid() {
+					cands6 = append(cands6, nameIP{n.HostName, nat64Embed(nat64, ip)})
+				} else {
+					cands4 = append(cands4, nameIP{n.HostName, ip})
+				}
 			}
 			if ip, err := netip.ParseAddr(n.IPv6); err == nil {
 				cands6 = append(cands6, nameIP{n.HostName, ip})
@@ -102,6 +133,10 @@ type nameIP struct {
 		}
 	}
 	if len(cands) == 0 {
+		if ips := cachedHostAddrs(host); len(ips) > 0 {
+			logf("no bootstrapDNS candidates for %q; using cached %v", host, ips)
+			return withNAT64(nat64, ips), nil
+		}
 		return nil, fmt.Errorf("no DNS fallback options for %q", host)
 	}
 	for _, cand := range cands {
@@ -119,12 +154,17 @@ type nameIP struct {
 		if ips := dm[host]; len(ips) > 0 {
 			slicesx.Shuffle(ips)
 			logf("bootstrapDNS(%q, %q) for %q = %v", cand.dnsName, cand.ip, host, ips)
-			return ips, nil
+			updateHostCache(host, ips, logf)
+			return withNAT64(nat64, ips), nil
 		}
 	}
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	if ips := cachedHostAddrs(host); len(ips) > 0 {
+		logf("bootstrapDNS for %q failed; using cached %v", host, ips)
+		return withNAT64(nat64, ips), nil
+	}
 	return nil, fmt.Errorf("no DNS fallback candidates remain for %q", host)
 }
 
@@ -282,3 +322,74 @@ func SetCachePath(path string, logf logger.Logf) {
 	cachedDERPMap.Store(dm)
 	logf("[v2] dnsfallback: SetCachePath loaded cached DERP map")
 }
+
+// maxCachedHosts is the maximum number of hosts whose addresses are kept in
+// the host cache. Only control and DERP hostnames are expected.
+const maxCachedHosts = 32
+
+var (
+	hostCacheMu   sync.Mutex
+	hostCache     dnsMap // guarded by hostCacheMu
+	hostCachePath string // guarded by hostCacheMu; or empty to not persist
+)
+
+// SetHostCachePath sets the path to the on-disk cache of the addresses that
+// bootstrap DNS returned for each host, which lookups use when no DERP server
+// answers, such as when the network blocks them or the DERP map is out of
+// date after a restart. If a file at this path exists, it's loaded.
+func SetHostCachePath(path string, logf logger.Logf) {
+	hostCacheMu.Lock()
+	defer hostCacheMu.Unlock()
+	hostCachePath = path
+
+	d, err := os.ReadFile(path)
+	if err != nil {
+		logf("[v1] dnsfallback: SetHostCachePath error reading %q: %v", path, err)
+		return
+	}
+	var dm dnsMap
+	if err := json.Unmarshal(d, &dm); err != nil {
+		logf("[v1] dnsfallback: SetHostCachePath error decoding %q: %v", path, err)
+		return
+	}
+	hostCache = dm
+	logf("[v2] dnsfallback: SetHostCachePath loaded %d cached hosts", len(dm))
+}
+
+// updateHostCache records that bootstrap DNS resolved host to ips, writing
+// the host cache back to disk if it changed.
+func updateHostCache(host string, ips []netip.Addr, logf logger.Logf) {
+	ips = slices.Clone(ips)
+	slices.SortFunc(ips, netip.Addr.Compare)
+
+	hostCacheMu.Lock()
+	defer hostCacheMu.Unlock()
+	if old, ok := hostCache[host]; ok && slices.Equal(old, ips) {
+		return
+	} else if !ok && len(hostCache) >= maxCachedHosts {
+		return
+	}
+	if hostCache == nil {
+		hostCache = make(dnsMap)
+	}
+	hostCache[host] = ips
+
+	if hostCachePath == "" {
+		return
+	}
+	d, err := json.Marshal(hostCache)
+	if err != nil {
+		logf("[v1] dnsfallback: updateHostCache error marshaling: %v", err)
+		return
+	}
+	if err := atomicfile.WriteFile(hostCachePath, d, 0600); err != nil {
+		logf("[v1] dnsfallback: updateHostCache error writing: %v", err)
+	}
+}
+
+// cachedHostAddrs returns the cached addresses of host, if any.
+func cachedHostAddrs(host string) []netip.Addr {
+	hostCacheMu.Lock()
+	defer hostCacheMu.Unlock()
+	return slices.Clone(hostCache[host])
+}
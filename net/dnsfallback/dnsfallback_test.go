@@ -7,11 +7,14 @@
 	"context"
 	"encoding/json"
 	"flag"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"testing"
 
+	"tailscale.com/feature"
 	"tailscale.com/net/netmon"
 	"tailscale.com/tailcfg"
 	"tailscale.com/types/logger"
@@ -28,6 +31,19 @@ func TestGetDERPMap(t *testing.T) {
 	}
 }
 
+func TestEmbeddedDERPRegions(t *testing.T) {
+	if !feature.HasEmbeddedDERPMap() {
+		t.Error("HasEmbeddedDERPMap = false")
+	}
+	codes := feature.EmbeddedDERPRegions()
+	if len(codes) != len(getStaticDERPMap().Regions) {
+		t.Errorf("got %d region codes; want %d", len(codes), len(getStaticDERPMap().Regions))
+	}
+	if !slices.IsSorted(codes) {
+		t.Errorf("region codes not sorted: %q", codes)
+	}
+}
+
 func TestCache(t *testing.T) {
 	cacheFile := filepath.Join(t.TempDir(), "cache.json")
 
@@ -178,6 +194,39 @@ func TestCacheUnchanged(t *testing.T) {
 
 var extNetwork = flag.Bool("use-external-network", false, "use the external network in tests")
 
+func TestHostCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "hosts.json")
+	t.Cleanup(func() {
+		hostCacheMu.Lock()
+		defer hostCacheMu.Unlock()
+		hostCache = nil
+		hostCachePath = ""
+	})
+
+	SetHostCachePath(cacheFile, t.Logf)
+	ips := []netip.Addr{netip.MustParseAddr("2001:db8::1"), netip.MustParseAddr("1.2.3.4")}
+	updateHostCache("controlplane.example.com", ips, t.Logf)
+	want := []netip.Addr{ips[1], ips[0]} // sorted
+	if got := cachedHostAddrs("controlplane.example.com"); !slices.Equal(got, want) {
+		t.Fatalf("cached %v; want %v", got, want)
+	}
+
+	// Forget the in-memory cache, as on restart, and reload it from disk.
+	hostCacheMu.Lock()
+	hostCache = nil
+	hostCacheMu.Unlock()
+	if got := cachedHostAddrs("controlplane.example.com"); got != nil {
+		t.Fatalf("cached %v after reset; want nil", got)
+	}
+	SetHostCachePath(cacheFile, t.Logf)
+	if got := cachedHostAddrs("controlplane.example.com"); !slices.Equal(got, want) {
+		t.Errorf("reloaded %v; want %v", got, want)
+	}
+	if got := cachedHostAddrs("other.example.com"); got != nil {
+		t.Errorf("other host cached as %v; want nil", got)
+	}
+}
+
 func TestLookup(t *testing.T) {
 	if !*extNetwork {
 		t.Skip("skipping test without --use-external-network")
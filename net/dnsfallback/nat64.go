@@ -0,0 +1,153 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package dnsfallback
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"tailscale.com/net/netmon"
+	"tailscale.com/types/logger"
+)
+
+// wellKnownNAT64Prefix is the NAT64 prefix from RFC 6052, section 2.1,
+// assumed when an IPv6-only network doesn't let us discover its prefix.
+var wellKnownNAT64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// ipv4OnlyAddrs are the well-known IPv4 addresses of "ipv4only.arpa",
+// from RFC 7050, section 2.2.
+var ipv4OnlyAddrs = []netip.Addr{
+	netip.MustParseAddr("192.0.0.170"),
+	netip.MustParseAddr("192.0.0.171"),
+}
+
+// nat64PrefixLens are the NAT64 prefix lengths permitted by RFC 6052,
+// section 2.2, longest first.
+var nat64PrefixLens = []int{96, 64, 56, 48, 40, 32}
+
+// lookupIPv4Only returns the AAAA records of "ipv4only.arpa". It's a
+// variable for tests.
+var lookupIPv4Only = func(ctx context.Context) ([]netip.Addr, error) {
+	return net.DefaultResolver.LookupNetIP(ctx, "ip6", "ipv4only.arpa")
+}
+
+// discoverNAT64Prefix returns the NAT64 prefix of the current network,
+// discovered as described in RFC 7050 by asking the system DNS64 resolver
+// for the IPv6 addresses it synthesizes for "ipv4only.arpa". If discovery
+// fails, it returns the well-known prefix.
+func discoverNAT64Prefix(ctx context.Context, logf logger.Logf) netip.Prefix {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	ips, err := lookupIPv4Only(ctx)
+	if err != nil {
+		logf("dnsfallback: NAT64 prefix discovery failed, assuming %v: %v", wellKnownNAT64Prefix, err)
+		return wellKnownNAT64Prefix
+	}
+	for _, ip := range ips {
+		if pfx, ok := nat64PrefixFromAddr(ip); ok {
+			logf("dnsfallback: discovered NAT64 prefix %v", pfx)
+			return pfx
+		}
+	}
+	logf("dnsfallback: no NAT64 prefix in %v, assuming %v", ips, wellKnownNAT64Prefix)
+	return wellKnownNAT64Prefix
+}
+
+// nat64Cache is the NAT64 prefix discovered on the network that netmon
+// described with state st. The netmon.Monitor only replaces its State when
+// the network changes, so the prefix is rediscovered only then.
+var nat64Cache struct {
+	mu  sync.Mutex
+	st  *netmon.State
+	pfx netip.Prefix
+}
+
+// nat64PrefixFor returns the NAT64 prefix of the network with state st,
+// discovering it with discoverNAT64Prefix the first time it's asked for st.
+func nat64PrefixFor(ctx context.Context, st *netmon.State, logf logger.Logf) netip.Prefix {
+	nat64Cache.mu.Lock()
+	defer nat64Cache.mu.Unlock()
+	if nat64Cache.st == st {
+		return nat64Cache.pfx
+	}
+	pfx := discoverNAT64Prefix(ctx, logf)
+	if ctx.Err() == nil {
+		nat64Cache.st, nat64Cache.pfx = st, pfx
+	}
+	return pfx
+}
+
+// nat64PrefixFromAddr returns the NAT64 prefix that ip, an address
+// synthesized for "ipv4only.arpa", was made with.
+func nat64PrefixFromAddr(ip netip.Addr) (_ netip.Prefix, ok bool) {
+	if !ip.Is6() || ip.Is4In6() {
+		return netip.Prefix{}, false
+	}
+	for _, bits := range nat64PrefixLens {
+		pfx := netip.PrefixFrom(ip, bits).Masked()
+		// Bits 64 through 71 (the "u" octet) must be zero unless they
+		// are part of a /96 prefix.
+		if bits < 96 && ip.As16()[8] != 0 {
+			continue
+		}
+		ip4 := extractNAT64(ip, bits)
+		if nat64Embed(pfx, ip4) != ip {
+			continue // non-zero suffix
+		}
+		if slices.Contains(ipv4OnlyAddrs, ip4) {
+			return pfx, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// nat64Embed returns the IPv6 address that NAT64 prefix pfx maps the IPv4
+// address ip4 to, as described in RFC 6052, section 2.2.
+func nat64Embed(pfx netip.Prefix, ip4 netip.Addr) netip.Addr {
+	a := pfx.Masked().Addr().As16()
+	i := pfx.Bits() / 8
+	for _, b := range ip4.As4() {
+		if i == 8 {
+			i++ // skip the "u" octet
+		}
+		a[i] = b
+		i++
+	}
+	return netip.AddrFrom16(a)
+}
+
+// extractNAT64 returns the IPv4 address embedded in ip by a NAT64 prefix of
+// the given length. It's the inverse of nat64Embed.
+func extractNAT64(ip netip.Addr, bits int) netip.Addr {
+	a := ip.As16()
+	var b [4]byte
+	i := bits / 8
+	for j := range b {
+		if i == 8 {
+			i++
+		}
+		b[j] = a[i]
+		i++
+	}
+	return netip.AddrFrom4(b)
+}
+
+// withNAT64 returns ips with, if pfx is valid, the addresses that pfx maps
+// each IPv4 address in ips to prepended, so that they're tried first.
+func withNAT64(pfx netip.Prefix, ips []netip.Addr) []netip.Addr {
+	if !pfx.IsValid() {
+		return ips
+	}
+	var out []netip.Addr
+	for _, ip := range ips {
+		if ip.Is4() {
+			out = append(out, nat64Embed(pfx, ip))
+		}
+	}
+	return append(out, ips...)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package dnsfallback
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"tailscale.com/net/netmon"
+	"tailscale.com/types/logger"
+)
+
+func TestNAT64PrefixFromAddr(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string // or empty for none
+	}{
+		{"64:ff9b::c000:aa", "64:ff9b::/96"},
+		{"64:ff9b::c000:ab", "64:ff9b::/96"},
+		{"2001:db8:1:2::c000:aa", "2001:db8:1:2::/96"},
+		{"2001:db8:1:2:c0:0:aa00:0", "2001:db8:1:2::/64"},
+		{"2001:db8:c000:aa::", "2001:db8::/32"},
+		{"64:ff9b::c000:1", ""},
+		{"64:ff9b::c000:aa:0", ""},
+		{"192.0.0.170", ""},
+		{"::ffff:192.0.0.170", ""},
+	}
+	for _, tt := range tests {
+		got, ok := nat64PrefixFromAddr(netip.MustParseAddr(tt.ip))
+		if tt.want == "" {
+			if ok {
+				t.Errorf("nat64PrefixFromAddr(%s) = %v; want none", tt.ip, got)
+			}
+			continue
+		}
+		if !ok || got != netip.MustParsePrefix(tt.want) {
+			t.Errorf("nat64PrefixFromAddr(%s) = %v, %v; want %v", tt.ip, got, ok, tt.want)
+		}
+	}
+}
+
+func TestNAT64Embed(t *testing.T) {
+	ip4 := netip.MustParseAddr("192.0.2.33")
+	for _, tt := range []struct{ pfx, want string }{
+		// From RFC 6052, section 2.4.
+		{"2001:db8::/32", "2001:db8:c000:221::"},
+		{"2001:db8:100::/40", "2001:db8:1c0:2:21::"},
+		{"2001:db8:122::/48", "2001:db8:122:c000:2:2100::"},
+		{"2001:db8:122:300::/56", "2001:db8:122:3c0:0:221::"},
+		{"2001:db8:122:344::/64", "2001:db8:122:344:c0:2:2100:0"},
+		{"2001:db8:122:344::/96", "2001:db8:122:344::192.0.2.33"},
+	} {
+		pfx := netip.MustParsePrefix(tt.pfx)
+		got := nat64Embed(pfx, ip4)
+		if got != netip.MustParseAddr(tt.want) {
+			t.Errorf("nat64Embed(%v) = %v; want %v", pfx, got, tt.want)
+		}
+		if back := extractNAT64(got, pfx.Bits()); back != ip4 {
+			t.Errorf("extractNAT64(%v, %d) = %v; want %v", got, pfx.Bits(), back, ip4)
+		}
+	}
+}
+
+func TestDiscoverNAT64Prefix(t *testing.T) {
+	old := lookupIPv4Only
+	t.Cleanup(func() { lookupIPv4Only = old })
+
+	lookupIPv4Only = func(context.Context) ([]netip.Addr, error) {
+		return []netip.Addr{netip.MustParseAddr("2001:db8:64::c000:ab")}, nil
+	}
+	if got, want := discoverNAT64Prefix(t.Context(), logger.Discard), netip.MustParsePrefix("2001:db8:64::/96"); got != want {
+		t.Errorf("discovered %v; want %v", got, want)
+	}
+
+	lookupIPv4Only = func(context.Context) ([]netip.Addr, error) {
+		return nil, errors.New("no DNS64")
+	}
+	if got := discoverNAT64Prefix(t.Context(), logger.Discard); got != wellKnownNAT64Prefix {
+		t.Errorf("on failure got %v; want %v", got, wellKnownNAT64Prefix)
+	}
+}
+
+func TestNAT64PrefixForCaches(t *testing.T) {
+	old := lookupIPv4Only
+	t.Cleanup(func() { lookupIPv4Only = old })
+	var lookups int
+	lookupIPv4Only = func(context.Context) ([]netip.Addr, error) {
+		lookups++
+		return []netip.Addr{netip.MustParseAddr("2001:db8:64::c000:aa")}, nil
+	}
+
+	st1, st2 := new(netmon.State), new(netmon.State)
+	want := netip.MustParsePrefix("2001:db8:64::/96")
+	for i, st := range []*netmon.State{st1, st1, st2, st2} {
+		if got := nat64PrefixFor(t.Context(), st, logger.Discard); got != want {
+			t.Errorf("call %d: got %v; want %v", i, got, want)
+		}
+	}
+	if lookups != 2 {
+		t.Errorf("got %d lookups; want 2, one per network state", lookups)
+	}
+}
+
+func TestWithNAT64(t *testing.T) {
+	ips := []netip.Addr{netip.MustParseAddr("1.2.3.4"), netip.MustParseAddr("2001:db8::1")}
+	if got := withNAT64(netip.Prefix{}, ips); !slices.Equal(got, ips) {
+		t.Errorf("without prefix got %v; want %v", got, ips)
+	}
+	want := []netip.Addr{netip.MustParseAddr("64:ff9b::102:304"), ips[0], ips[1]}
+	if got := withNAT64(wellKnownNAT64Prefix, ips); !slices.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
@@ -125,6 +125,14 @@ type ChangeDelta struct {
 	AvailableProtocolsChanged   bool // whether we have seen a change in available IPv4/IPv6
 	DefaultInterfaceMaybeViable bool // whether the default interface is potentially viable (has usable IPs, is up and is not the tunnel itself)
 	IsInitialState              bool // whether this is the initial state (old == nil, new != nil)
+	ExpensiveChanged            bool // whether the default interface changed between expensive (e.g. metered) and not
+
+	// InterfacesUp and InterfacesDown are the sorted names of the
+	// interfaces, other than Tailscale's own, that came up or went down
+	// since the old state, including those that appeared up or
+	// disappeared while up. Both are nil for the initial state.
+	InterfacesUp   []string
+	InterfacesDown []string
 
 	// RebindLikelyRequired combines the various fields above to report whether this change likely requires us
 	// to rebind sockets.  This is a very conservative estimate and covers a number ofcases where a rebind
@@ -170,8 +178,10 @@ func NewChangeDelta(old, new *State, jumpDuration time.Duration, forceViability
 		cd.AvailableProtocolsChanged = (cd.old.HaveV4 != cd.new.HaveV4) || (cd.old.HaveV6 != cd.new.HaveV6)
 		cd.DefaultInterfaceChanged = cd.old.DefaultRouteInterface != cd.new.DefaultRouteInterface
 		cd.IsLessExpensive = cd.old.IsExpensive && !cd.new.IsExpensive
+		cd.ExpensiveChanged = cd.old.IsExpensive != cd.new.IsExpensive
 		cd.HasPACOrProxyConfigChanged = (cd.old.PAC != cd.new.PAC) || (cd.old.HTTPProxy != cd.new.HTTPProxy)
 		cd.InterfaceIPsChanged = cd.isInterestingInterfaceChange()
+		cd.InterfacesUp, cd.InterfacesDown = cd.interfacesUpDown()
 	}
 
 	cd.DefaultRouteInterface = new.DefaultRouteInterface
@@ -265,6 +275,26 @@ func (cd *ChangeDelta) AnyInterfaceUp() bool {
 	return cd.new.AnyInterfaceUp()
 }
 
+// interfacesUpDown returns the names of the interfaces that came up and
+// went down between cd.old and cd.new, which must both be non-nil.
+func (cd *ChangeDelta) interfacesUpDown() (up, down []string) {
+	tsIfName, ifNameErr := TailscaleInterfaceName()
+	isTailscale := func(name string) bool { return ifNameErr == nil && name == tsIfName }
+	for name, ni := range cd.new.Interface {
+		if !isTailscale(name) && ni.IsUp() && !cd.old.Interface[name].IsUp() {
+			up = append(up, name)
+		}
+	}
+	for name, oi := range cd.old.Interface {
+		if !isTailscale(name) && oi.IsUp() && !cd.new.Interface[name].IsUp() {
+			down = append(down, name)
+		}
+	}
+	slices.Sort(up)
+	slices.Sort(down)
+	return up, down
+}
+
 // isInterestingInterfaceChange reports whether any interfaces have changed in a meaningful way.
 // This excludes interfaces that are not interesting per IsInterestingInterface and
 // filters out changes to interface IPs that are uninteresting (e.g. link-local addresses).
@@ -8,6 +8,7 @@
 	"net"
 	"net/netip"
 	"reflect"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -683,6 +684,48 @@ func withIsInterestingInterface(t *testing.T, fn func(Interface, []netip.Prefix)
 	t.Cleanup(func() { IsInterestingInterface = old })
 }
 
+func TestChangeDeltaInterfacesUpDown(t *testing.T) {
+	ifc := func(name string, flags net.Flags) Interface {
+		return Interface{Interface: &net.Interface{Name: name, Flags: flags}}
+	}
+	s1 := &State{
+		Interface: map[string]Interface{
+			"en0": ifc("en0", net.FlagUp),
+			"en1": ifc("en1", 0),
+			"en2": ifc("en2", net.FlagUp),
+		},
+	}
+	s2 := &State{
+		IsExpensive: true,
+		Interface: map[string]Interface{
+			"en0": ifc("en0", 0),
+			"en1": ifc("en1", net.FlagUp),
+			"en3": ifc("en3", net.FlagUp),
+		},
+	}
+	cd, err := NewChangeDelta(s1, s2, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"en1", "en3"}; !slices.Equal(cd.InterfacesUp, want) {
+		t.Errorf("InterfacesUp = %q; want %q", cd.InterfacesUp, want)
+	}
+	if want := []string{"en0", "en2"}; !slices.Equal(cd.InterfacesDown, want) {
+		t.Errorf("InterfacesDown = %q; want %q", cd.InterfacesDown, want)
+	}
+	if !cd.ExpensiveChanged {
+		t.Error("ExpensiveChanged = false; want true")
+	}
+
+	cd, err = NewChangeDelta(nil, s2, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cd.InterfacesUp != nil || cd.InterfacesDown != nil || cd.ExpensiveChanged {
+		t.Errorf("initial state: up=%q down=%q expensiveChanged=%v; want none", cd.InterfacesUp, cd.InterfacesDown, cd.ExpensiveChanged)
+	}
+}
+
 func TestIncludesRoutableIP(t *testing.T) {
 	routable := []netip.Prefix{
 		netip.MustParsePrefix("1.2.3.4/32"),
@@ -30,3 +30,7 @@ func (c *Client) getUPnPPortMapping(
 ) (external netip.AddrPort, ok bool) {
 	return netip.AddrPort{}, false
 }
+
+func (c *Client) upnpExternalIP(ctx context.Context, gw netip.Addr) (netip.Addr, error) {
+	return netip.Addr{}, ErrNoPortMappingServices
+}
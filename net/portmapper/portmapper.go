@@ -122,6 +122,8 @@ type Client struct {
 	testPxPPort  uint16 // if non-zero, pxpPort to use for tests
 	testUPnPPort uint16 // if non-zero, uPnPPort to use for tests
 
+	timeNow func() time.Time // or nil for time.Now; for tests
+
 	mu syncs.Mutex // guards following, and all fields thereof
 
 	// runningCreate is whether we're currently working on creating
@@ -539,6 +541,13 @@ func (c *Client) createMapping() {
 	}
 }
 
+func (c *Client) now() time.Time {
+	if c.timeNow != nil {
+		return c.timeNow()
+	}
+	return time.Now()
+}
+
 // wildcardIP is used when the previous external IP is not known for PCP port mapping.
 var wildcardIP = netip.MustParseAddr("0.0.0.0")
 
@@ -562,7 +571,7 @@ func (c *Client) createOrGetMapping(ctx context.Context) (mapping mapping, exter
 		return nil, netip.AddrPort{}, NoMappingError{ErrGatewayIPv6}
 	}
 
-	now := time.Now()
+	now := c.now()
 
 	// Log what kind of portmap we obtained
 	reusedExisting := false
@@ -628,6 +637,11 @@ func (c *Client) createOrGetMapping(ctx context.Context) (mapping mapping, exter
 		}
 		// The mapping might still be valid, so just try to renew it.
 		prevPort = m.External().Port()
+		if now.Before(m.GoodUntil()) {
+			metricRenew.Add(1)
+		} else {
+			metricExpired.Add(1)
+		}
 	}
 
 	if c.debug.DisablePCP() && c.debug.DisablePMP() {
@@ -866,6 +880,31 @@ func parsePMPResponse(pkt []byte) (res pmpResponse, ok bool) {
 	return res, true
 }
 
+// GatewayExternalIP returns the external (WAN) IPv4 address of the gateway,
+// as reported by NAT-PMP or, failing that, UPnP. It probes the gateway
+// first, but doesn't create a mapping. It returns ErrNoPortMappingServices
+// if the gateway supports neither.
+func (c *Client) GatewayExternalIP(ctx context.Context) (netip.Addr, error) {
+	res, err := c.Probe(ctx)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	c.mu.Lock()
+	pub := c.pmpPubIP
+	c.mu.Unlock()
+	if res.PMP && pub.IsValid() {
+		return pub, nil
+	}
+	if !res.UPnP {
+		return netip.Addr{}, ErrNoPortMappingServices
+	}
+	gw, _, ok := c.gatewayAndSelfIP()
+	if !ok {
+		return netip.Addr{}, ErrGatewayRange
+	}
+	return c.upnpExternalIP(ctx, gw)
+}
+
 // Probe returns a summary of which port mapping services are
 // available on the network.
 //
@@ -1234,6 +1273,19 @@ func (c *Client) maybeInvalidatePCPMappingLocked(epoch uint32) {
 	"MAN: \"ssdp:discover\"\r\n" +
 	"MX: 2\r\n\r\n")
 
+// Mapping lifetime metrics, for all protocols
+var (
+	// metricRenew counts the number of times we tried to renew a mapping
+	// that was due for renewal but still valid.
+	metricRenew = clientmetric.NewCounter("portmap_renew")
+
+	// metricExpired counts the number of times we found a mapping had
+	// expired before it was renewed, such as after failed renewals or
+	// while the machine slept. The new mapping may get a different
+	// external port, breaking direct connections that used the old one.
+	metricExpired = clientmetric.NewCounter("portmap_expired")
+)
+
 // PCP/PMP metrics
 var (
 	// metricPXPResponse counts the number of times we received a PMP/PCP response.
@@ -12,6 +12,7 @@
 	"time"
 
 	"tailscale.com/net/portmapper/portmappertype"
+	"tailscale.com/tstest"
 	"tailscale.com/util/eventbus/eventbustest"
 )
 
@@ -125,6 +126,51 @@ func TestPCPIntegration(t *testing.T) {
 	}
 }
 
+func TestMappingLifetimeMetrics(t *testing.T) {
+	igd, err := NewTestIGD(t, TestIGDOptions{PCP: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer igd.Close()
+
+	clock := tstest.NewClock(tstest.ClockOpts{Start: time.Now()})
+	c := newTestClient(t, igd, nil)
+	c.timeNow = clock.Now
+	if _, err := c.Probe(t.Context()); err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	m, _, err := c.createOrGetMapping(t.Context())
+	if err != nil {
+		t.Fatalf("failed to get mapping: %v", err)
+	}
+
+	// A mapping that's due for renewal but still good is renewed.
+	renew, expired := metricRenew.Value(), metricExpired.Value()
+	clock.AdvanceTo(m.RenewAfter().Add(time.Second))
+	if m, _, err = c.createOrGetMapping(t.Context()); err != nil {
+		t.Fatalf("failed to renew mapping: %v", err)
+	}
+	if got := metricRenew.Value() - renew; got != 1 {
+		t.Errorf("portmap_renew went up by %d; want 1", got)
+	}
+	if got := metricExpired.Value() - expired; got != 0 {
+		t.Errorf("portmap_expired went up by %d; want 0", got)
+	}
+
+	// One that's lapsed counts as expired instead.
+	renew, expired = metricRenew.Value(), metricExpired.Value()
+	clock.AdvanceTo(m.GoodUntil().Add(time.Second))
+	if _, _, err = c.createOrGetMapping(t.Context()); err != nil {
+		t.Fatalf("failed to replace expired mapping: %v", err)
+	}
+	if got := metricRenew.Value() - renew; got != 0 {
+		t.Errorf("portmap_renew went up by %d; want 0", got)
+	}
+	if got := metricExpired.Value() - expired; got != 1 {
+		t.Errorf("portmap_expired went up by %d; want 1", got)
+	}
+}
+
 // Test to ensure that metric names generated by this function do not contain
 // invalid characters.
 //
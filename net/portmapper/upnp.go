@@ -13,6 +13,7 @@
 	"cmp"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand/v2"
@@ -584,6 +585,44 @@ type step struct {
 	return netip.AddrPort{}, false
 }
 
+// upnpExternalIP returns the external IP address that the best service of
+// gw's UPnP IGD reports, trying each device that answered discovery in turn.
+func (c *Client) upnpExternalIP(ctx context.Context, gw netip.Addr) (netip.Addr, error) {
+	c.mu.Lock()
+	metas := c.uPnPMetas
+	ctx = upnpHTTPClientKey.WithValue(ctx, c.upnpHTTPClientLocked())
+	c.mu.Unlock()
+
+	var errs []error
+	for _, meta := range metas {
+		rootDev, loc, err := getUPnPRootDevice(ctx, c.logf, c.debug, gw, meta)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if rootDev == nil {
+			continue
+		}
+		svc, err := selectBestService(ctx, c.logf, rootDev, loc)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		extIP, err := svc.GetExternalIPAddressCtx(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ip, err := netip.ParseAddr(extIP)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return ip, nil
+	}
+	return netip.Addr{}, fmt.Errorf("no UPnP device reported an external IP address: %w", errors.Join(errs...))
+}
+
 // tryUPnPPortmapWithDevice attempts to perform a port forward from the given
 // UPnP device to the 'internal' address. It tries to re-use the previous port,
 // if a non-zero value is provided, and handles retries and errors about
@@ -0,0 +1,139 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"tailscale.com/hostinfo"
+)
+
+// dnsVersionProbeTimeout is how long DNSResolverVendor waits for the
+// resolver to answer.
+const dnsVersionProbeTimeout = time.Second
+
+// DNSResolverVendor returns the name of the software of the host's primary
+// configured DNS resolver (see [hostinfo.DNSResolverAddr]), if it can be
+// identified: one of "dnsmasq", "pi-hole", "unbound", "bind", "powerdns",
+// "knot-resolver", "systemd-resolved" or "tailscale". Resolvers that rewrite
+// or intercept queries, such as the ones built into home routers (usually
+// dnsmasq) or ad blockers like Pi-hole, are a common cause of split DNS and
+// MagicDNS failures. It returns the empty string if the resolver can't be
+// identified.
+//
+// Resolvers that [hostinfo.DNSResolverVendor] recognizes by their address
+// aren't probed. Otherwise, it sends the resolver the customary
+// "version.bind" TXT query in the CHAOS class, which most resolver software
+// answers with its name and version, waiting up to a second for an answer.
+// Many resolvers, including most corporate and ISP ones, are configured not
+// to answer it. It returns an error if the resolver didn't answer, and when
+// ctx is done.
+//
+// The query doesn't reveal anything about the host beyond its address,
+// which the resolver sees anyway. The answer can be a custom string chosen
+// by whoever runs the resolver, so only the recognized software name is
+// returned, never the answer itself.
+func DNSResolverVendor(ctx context.Context) (string, error) {
+	if v := hostinfo.DNSResolverVendor(); v != "" {
+		return v, nil
+	}
+	addr := hostinfo.DNSResolverAddr()
+	if !addr.IsValid() {
+		return "", nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, dnsVersionProbeTimeout)
+	defer cancel()
+	banner, err := dnsVersionBind(ctx, netip.AddrPortFrom(addr, 53))
+	if err != nil {
+		return "", err
+	}
+	return dnsVendorOfBanner(banner), nil
+}
+
+// dnsVersionBind returns the answer of the DNS server at addr to a
+// "version.bind" CHAOS TXT query.
+func dnsVersionBind(ctx context.Context, addr netip.AddrPort) (string, error) {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "udp", addr.String())
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		c.SetDeadline(deadline)
+	}
+
+	id := uint16(time.Now().UnixNano())
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id})
+	b.StartQuestions()
+	b.Question(dnsmessage.Question{
+		Name:  dnsmessage.MustNewName("version.bind."),
+		Type:  dnsmessage.TypeTXT,
+		Class: dnsmessage.ClassCHAOS,
+	})
+	q, err := b.Finish()
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.Write(q); err != nil {
+		return "", err
+	}
+	buf := make([]byte, 512)
+	for {
+		n, err := c.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		var p dnsmessage.Parser
+		h, err := p.Start(buf[:n])
+		if err != nil || h.ID != id || !h.Response {
+			continue // not an answer to our query
+		}
+		if err := p.SkipAllQuestions(); err != nil {
+			return "", err
+		}
+		for {
+			rh, err := p.AnswerHeader()
+			if err != nil {
+				return "", err // including when there's no TXT answer
+			}
+			if rh.Type != dnsmessage.TypeTXT {
+				p.SkipAnswer()
+				continue
+			}
+			txt, err := p.TXTResource()
+			if err != nil {
+				return "", err
+			}
+			return strings.Join(txt.TXT, ""), nil
+		}
+	}
+}
+
+// dnsVendorOfBanner returns the DNSResolverVendor name of the resolver
+// software whose "version.bind" answer is banner, or the empty string if
+// it's not recognized.
+func dnsVendorOfBanner(banner string) string {
+	b := strings.ToLower(banner)
+	switch {
+	case strings.Contains(b, "pi-hole"):
+		return "pi-hole" // its FTL is a fork of dnsmasq, reporting "dnsmasq-pi-hole-v2.90"
+	case strings.HasPrefix(b, "dnsmasq"):
+		return "dnsmasq"
+	case strings.HasPrefix(b, "unbound"):
+		return "unbound"
+	case strings.HasPrefix(b, "powerdns"):
+		return "powerdns"
+	case strings.HasPrefix(b, "knot resolver"), strings.HasPrefix(b, "knot-resolver"):
+		return "knot-resolver"
+	case strings.HasPrefix(b, "9.") && strings.Count(b, ".") >= 2:
+		return "bind" // BIND reports just its version, such as "9.18.24"
+	}
+	return ""
+}
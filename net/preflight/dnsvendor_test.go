@@ -0,0 +1,77 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDNSVendorOfBanner(t *testing.T) {
+	tests := []struct {
+		banner string
+		want   string
+	}{
+		{"dnsmasq-2.89", "dnsmasq"},
+		{"dnsmasq-pi-hole-v2.90+1", "pi-hole"},
+		{"unbound 1.17.1", "unbound"},
+		{"PowerDNS Recursor 4.9.3", "powerdns"},
+		{"Knot Resolver 5.7.1", "knot-resolver"},
+		{"9.18.24-1-Debian", "bind"},
+		{"none of your business", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := dnsVendorOfBanner(tt.banner); got != tt.want {
+			t.Errorf("dnsVendorOfBanner(%q) = %q; want %q", tt.banner, got, tt.want)
+		}
+	}
+}
+
+func TestDNSVersionBind(t *testing.T) {
+	pc, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, 512)
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var p dnsmessage.Parser
+		h, err := p.Start(buf[:n])
+		if err != nil {
+			return
+		}
+		q, err := p.Question()
+		if err != nil || q.Class != dnsmessage.ClassCHAOS {
+			return
+		}
+		h.Response = true
+		b := dnsmessage.NewBuilder(nil, h)
+		b.StartQuestions()
+		b.Question(q)
+		b.StartAnswers()
+		b.TXTResource(dnsmessage.ResourceHeader{Name: q.Name, Class: q.Class}, dnsmessage.TXTResource{TXT: []string{"dnsmasq-2.89"}})
+		resp, _ := b.Finish()
+		pc.WriteTo(resp, addr)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := dnsVersionBind(ctx, netip.MustParseAddrPort(pc.LocalAddr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "dnsmasq-2.89" {
+		t.Errorf("dnsVersionBind = %q; want %q", got, "dnsmasq-2.89")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"time"
+
+	"tailscale.com/hostinfo"
+)
+
+// Keepalive intervals that RecommendedKeepaliveInterval picks from.
+const (
+	// shortKeepaliveInterval is WireGuard's recommended persistent
+	// keepalive interval, which is shorter than the 30 second UDP mapping
+	// timeout of the most aggressive NATs.
+	shortKeepaliveInterval = 25 * time.Second
+
+	// longKeepaliveInterval is shorter than the two minute timeout of
+	// stateful firewalls, such as Linux's conntrack, for UDP flows that
+	// have seen traffic both ways.
+	longKeepaliveInterval = 110 * time.Second
+)
+
+// RecommendedKeepaliveInterval returns the interval at which the node
+// should send keepalives on otherwise idle direct connections, to keep the
+// NAT mappings and firewall state that they rely on alive.
+//
+// It's 25 seconds, unless one of these says that a
+// longer interval, 110 seconds, is the better trade-off:
+//
+//   - the host is on battery power (see [hostinfo.OnBatteryPower]), where
+//     waking the radio less often saves more than keeping idle connections
+//     direct is worth; when a NAT mapping does expire, traffic goes through
+//     DERP until the direct connection is reestablished
+//   - the last [NATType] probe found no NAT, so only stateful firewalls
+//     need keeping alive, and they time out idle UDP flows later
+//   - the last NATType probe found a symmetric NAT, behind which direct
+//     connections rarely work at all
+//
+// Until NATType has been called, the NAT is assumed to be the kind that
+// needs the short interval. Whether the connection is metered isn't
+// considered, as that isn't known here.
+func RecommendedKeepaliveInterval() time.Duration {
+	battery, _ := hostinfo.OnBatteryPower()
+	natType, _ := lastNATType.Load().(string)
+	return keepaliveInterval(battery, natType)
+}
+
+// keepaliveInterval returns the RecommendedKeepaliveInterval for a host that
+// is on battery power or not, behind a NAT of type natType ("" if unknown).
+func keepaliveInterval(battery bool, natType string) time.Duration {
+	if battery || natType == NATNone || natType == NATSymmetric {
+		return longKeepaliveInterval
+	}
+	return shortKeepaliveInterval
+}
@@ -0,0 +1,29 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeepaliveInterval(t *testing.T) {
+	tests := []struct {
+		battery bool
+		natType string
+		want    time.Duration
+	}{
+		{false, "", 25 * time.Second},
+		{false, NATCone, 25 * time.Second},
+		{false, NATNone, 110 * time.Second},
+		{false, NATSymmetric, 110 * time.Second},
+		{true, "", 110 * time.Second},
+		{true, NATCone, 110 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := keepaliveInterval(tt.battery, tt.natType); got != tt.want {
+			t.Errorf("keepaliveInterval(%v, %q) = %v; want %v", tt.battery, tt.natType, got, tt.want)
+		}
+	}
+}
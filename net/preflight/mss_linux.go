@@ -0,0 +1,92 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+
+	"golang.org/x/sys/unix"
+)
+
+func init() {
+	probeMSS = probeMSSLinux
+}
+
+// tcpiOptTimestamps is Linux's TCPI_OPT_TIMESTAMPS, the tcpi_options bit
+// for a connection using TCP timestamps.
+const tcpiOptTimestamps = 1
+
+// tcpTimestampsOptionLen is the space TCP timestamps take in each segment,
+// padding included.
+const tcpTimestampsOptionLen = 12
+
+func probeMSSLinux(ctx context.Context, target string) (mss, want int, err error) {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "tcp", target)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer c.Close()
+	tc, ok := c.(*net.TCPConn)
+	if !ok {
+		return 0, 0, errors.New("not a TCP connection")
+	}
+	rc, err := tc.SyscallConn()
+	if err != nil {
+		return 0, 0, err
+	}
+	var info *unix.TCPInfo
+	var sockErr error
+	if err := rc.Control(func(fd uintptr) {
+		info, sockErr = unix.GetsockoptTCPInfo(int(fd), unix.IPPROTO_TCP, unix.TCP_INFO)
+	}); err != nil {
+		return 0, 0, err
+	}
+	if sockErr != nil {
+		return 0, 0, sockErr
+	}
+	// The send MSS excludes the TCP options sent in every segment; add
+	// back the timestamps to get the MSS as advertised.
+	mss = int(info.Snd_mss)
+	if info.Options&tcpiOptTimestamps != 0 {
+		mss += tcpTimestampsOptionLen
+	}
+
+	local := tc.LocalAddr().(*net.TCPAddr).AddrPort().Addr().Unmap()
+	mtu, ok := interfaceMTUOf(local)
+	if !ok {
+		return 0, 0, fmt.Errorf("no interface with address %v", local)
+	}
+	hdrs := 40 // IPv4 and TCP headers
+	if local.Is6() {
+		hdrs = 60
+	}
+	return mss, mtu - hdrs, nil
+}
+
+// interfaceMTUOf returns the MTU of the network interface with address ip.
+func interfaceMTUOf(ip netip.Addr) (int, bool) {
+	ifs, err := net.Interfaces()
+	if err != nil {
+		return 0, false
+	}
+	for _, ifc := range ifs {
+		addrs, err := ifc.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if ipn, ok := a.(*net.IPNet); ok {
+				if aip, ok := netip.AddrFromSlice(ipn.IP); ok && aip.Unmap() == ip {
+					return ifc.MTU, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
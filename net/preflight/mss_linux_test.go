@@ -0,0 +1,67 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestProbeMSSLinux(t *testing.T) {
+	// listen returns a loopback TCP listener whose connections advertise
+	// an MSS of mss, or the default if it's zero.
+	listen := func(t *testing.T, mss int) net.Listener {
+		lc := net.ListenConfig{
+			Control: func(network, address string, c syscall.RawConn) error {
+				var err error
+				if mss != 0 {
+					c.Control(func(fd uintptr) {
+						err = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_MAXSEG, mss)
+					})
+				}
+				return err
+			},
+		}
+		ln, err := lc.Listen(context.Background(), "tcp4", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { ln.Close() })
+		go func() {
+			for {
+				c, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				c.Close()
+			}
+		}()
+		return ln
+	}
+	ctx := context.Background()
+
+	// Over loopback, with its 64KiB MTU, the kernel bounds the MSS to half
+	// the peer's window, so it's reported as clamped, but not as much.
+	ln := listen(t, 0)
+	_, mss, err := MSSClampingDetectedTo(ctx, ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mss <= 1000 {
+		t.Errorf("with the default server MSS: got MSS %d; want more than 1000", mss)
+	}
+
+	ln = listen(t, 1000)
+	clamped, mss, err := MSSClampingDetectedTo(ctx, ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !clamped || mss != 1000 {
+		t.Errorf("with a 1000 byte server MSS: got (%v, %d); want (true, 1000)", clamped, mss)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync/atomic"
+	"time"
+
+	"tailscale.com/feature"
+	"tailscale.com/net/stun"
+)
+
+// DefaultNATSTUNServers are the STUN servers that [NATType] queries. They
+// must have different IP addresses for the mapping to be compared.
+var DefaultNATSTUNServers = []string{
+	"derp1.tailscale.com:3478",
+	"derp2.tailscale.com:3478",
+}
+
+// NAT types returned by [NATType].
+const (
+	NATNone      = "none"      // the node has a public address
+	NATCone      = "cone"      // endpoint-independent mapping
+	NATSymmetric = "symmetric" // mapping varies by destination
+)
+
+// lastNATType is the result of the last successful NATTypeWith call, for
+// RecommendedKeepaliveInterval.
+var lastNATType atomic.Value // of string
+
+// NATType classifies the IPv4 NAT the node is behind, querying
+// [DefaultNATSTUNServers]. See [NATTypeWith].
+func NATType(ctx context.Context) (string, error) {
+	return NATTypeWith(ctx, DefaultNATSTUNServers)
+}
+
+// NATTypeWith classifies the IPv4 NAT the node is behind by sending STUN
+// binding requests from a single UDP socket to each of stunServers
+// (host:port pairs, at least two, on different IP addresses) and comparing
+// the public addresses they report:
+//
+//   - [NATNone] if the reported address is the socket's own: the node has a
+//     public IP address and isn't behind a NAT
+//   - [NATCone] if all servers report the same address: the NAT maps the
+//     socket to one public address regardless of destination, which lets
+//     peers connect directly, as with full-cone, restricted-cone and
+//     port-restricted-cone NATs
+//   - [NATSymmetric] if they report different addresses: the NAT maps
+//     each destination separately, which usually prevents direct
+//     connections unless the peer isn't behind such a NAT
+//
+// Telling the kinds of cone NAT apart requires servers that can answer from
+// a different address or port (RFC 3489's CHANGE-REQUEST), which Tailscale's
+// and most public STUN servers don't support, so it isn't attempted.
+//
+// Servers that don't answer within two seconds are skipped. It fails if
+// fewer than two answer, and when ctx is done.
+func NATTypeWith(ctx context.Context, stunServers []string) (string, error) {
+	pc, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return "", err
+	}
+	defer pc.Close()
+	stop := context.AfterFunc(ctx, func() { pc.SetDeadline(time.Now()) })
+	defer stop()
+
+	var mapped []netip.AddrPort
+	var local netip.AddrPort
+	var errs []error
+	for _, server := range stunServers {
+		if ctx.Err() != nil {
+			break
+		}
+		dst, err := net.DefaultResolver.LookupNetIP(ctx, "ip4", hostOnly(server))
+		if err == nil && len(dst) == 0 {
+			err = fmt.Errorf("no IPv4 address for %q", server)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, port, _ := net.SplitHostPort(server)
+		addr, err := net.ResolveUDPAddr("udp4", net.JoinHostPort(dst[0].Unmap().String(), port))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !local.IsValid() {
+			local = localAddrFor(addr, pc.LocalAddr())
+		}
+		m, err := stunMappedAddr(pc, addr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", server, err))
+			continue
+		}
+		mapped = append(mapped, m)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if len(mapped) < 2 {
+		return "", fmt.Errorf("need answers from at least two STUN servers, got %d: %w", len(mapped), errors.Join(errs...))
+	}
+	t := classifyNAT(local, mapped)
+	lastNATType.Store(t)
+	return t, nil
+}
+
+// classifyNAT returns the NAT type of a socket bound to local, given the
+// public addresses that STUN servers reported for it.
+func classifyNAT(local netip.AddrPort, mapped []netip.AddrPort) string {
+	for _, m := range mapped[1:] {
+		if m != mapped[0] {
+			return NATSymmetric
+		}
+	}
+	if mapped[0] == local {
+		return NATNone
+	}
+	return NATCone
+}
+
+// hostOnly returns the host part of hostPort, or hostPort itself if it has
+// no port.
+func hostOnly(hostPort string) string {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return hostPort
+	}
+	return host
+}
+
+// localAddrFor returns the local address of a socket bound to bound, a
+// wildcard address, when sending to dst: the source IP address the routing
+// table picks, and bound's port.
+func localAddrFor(dst *net.UDPAddr, bound net.Addr) netip.AddrPort {
+	port := bound.(*net.UDPAddr).AddrPort().Port()
+	// Connecting a UDP socket sends nothing, but selects the source address.
+	c, err := net.DialUDP("udp4", nil, dst)
+	if err != nil {
+		return netip.AddrPort{}
+	}
+	defer c.Close()
+	return netip.AddrPortFrom(c.LocalAddr().(*net.UDPAddr).AddrPort().Addr().Unmap(), port)
+}
+
+// stunMappedAddr sends a STUN binding request to dst over pc and returns
+// the address in the response, waiting up to two seconds for it.
+func stunMappedAddr(pc net.PacketConn, dst *net.UDPAddr) (netip.AddrPort, error) {
+	txID := stun.NewTxID()
+	if _, err := pc.WriteTo(stun.Request(txID), dst); err != nil {
+		return netip.AddrPort{}, err
+	}
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	for {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			return netip.AddrPort{}, err
+		}
+		gotTxID, addr, err := stun.ParseResponse(buf[:n])
+		if err != nil || gotTxID != txID {
+			continue // not a response to this request
+		}
+		return netip.AddrPortFrom(addr.Addr().Unmap(), addr.Port()), nil
+	}
+}
+
+// PortMappingAvailable probes the default gateway for the port mapping
+// protocols that tailscaled can use to make its UDP port reachable from
+// outside the NAT, which makes direct connections far more likely. It
+// returns whether each of "upnp", "nat-pmp" and "pcp" is available:
+//
+//   - UPnP IGD: an SSDP discovery request for an Internet gateway device,
+//     sent to the gateway and to the SSDP multicast group
+//   - NAT-PMP: an external address request to the gateway's port 5351
+//   - PCP: an ANNOUNCE request to the gateway's port 5351
+//
+// The requests are sent together and the gateway's answers collected until
+// ctx is done or a short timeout passes. The probe is that of the
+// portmapper feature; it fails with [feature.ErrUnavailable] in builds
+// without it, and with the portmapper's error when the gateway can't be
+// determined or isn't in a private address range.
+func PortMappingAvailable(ctx context.Context) (map[string]bool, error) {
+	probe, ok := feature.HookProbePortMapping.GetOk()
+	if !ok {
+		return nil, feature.ErrUnavailable
+	}
+	upnp, pmp, pcp, err := probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]bool{"upnp": upnp, "nat-pmp": pmp, "pcp": pcp}, nil
+}
+
+// DoubleNAT reports whether the node appears to be behind two layers of
+// NAT, such as a home router behind an ISP's carrier-grade NAT or another
+// router. Hole punching through both rarely works, so nodes behind a
+// double NAT often only ever connect through DERP.
+//
+// It asks the default gateway for its external (WAN) address with NAT-PMP
+// or, failing that, UPnP (see [PortMappingAvailable]), and compares that
+// with the node's public address as seen by [DefaultSTUNServer]. If they
+// differ, another NAT beyond the gateway translates the gateway's address
+// again. It fails if the gateway can't be queried, which needs the
+// portmapper feature (it's [feature.ErrUnavailable] without it) and a
+// gateway that supports one of those protocols, if the STUN server can't
+// be reached, and when ctx is done.
+func DoubleNAT(ctx context.Context) (bool, error) {
+	gatewayExternalIP, ok := feature.HookGatewayExternalIP.GetOk()
+	if !ok {
+		return false, feature.ErrUnavailable
+	}
+	wan, err := gatewayExternalIP(ctx)
+	if err != nil {
+		return false, fmt.Errorf("querying the gateway's external address: %w", err)
+	}
+	public, err := stunPublicIP(ctx, DefaultSTUNServer)
+	if err != nil {
+		return false, err
+	}
+	return wan.Unmap() != public, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"tailscale.com/feature"
+	"tailscale.com/net/stun/stuntest"
+)
+
+func TestClassifyNAT(t *testing.T) {
+	local := netip.MustParseAddrPort("192.168.1.10:41641")
+	pub := netip.MustParseAddrPort("203.0.113.5:41641")
+	tests := []struct {
+		name   string
+		mapped []netip.AddrPort
+		want   string
+	}{
+		{"none", []netip.AddrPort{local, local}, NATNone},
+		{"cone", []netip.AddrPort{pub, pub, pub}, NATCone},
+		{"symmetric_port", []netip.AddrPort{pub, netip.MustParseAddrPort("203.0.113.5:50000")}, NATSymmetric},
+		{"symmetric_ip", []netip.AddrPort{pub, netip.MustParseAddrPort("203.0.113.6:41641")}, NATSymmetric},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyNAT(local, tt.mapped); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNATTypeWith(t *testing.T) {
+	addr1, cleanup1 := stuntest.Serve(t)
+	defer cleanup1()
+	addr2, cleanup2 := stuntest.Serve(t)
+	defer cleanup2()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// On loopback, the servers see the socket's own address.
+	got, err := NATTypeWith(ctx, []string{addr1.String(), addr2.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != NATNone {
+		t.Errorf("NATTypeWith = %q; want %q", got, NATNone)
+	}
+
+	if _, err := NATTypeWith(ctx, []string{addr1.String()}); err == nil {
+		t.Error("NATTypeWith with one server succeeded; want error")
+	}
+}
+
+func TestPortMappingAvailable(t *testing.T) {
+	defer feature.HookProbePortMapping.SetForTest(func(context.Context) (upnp, pmp, pcp bool, err error) {
+		return false, true, true, nil
+	})()
+	got, err := PortMappingAvailable(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"upnp": false, "nat-pmp": true, "pcp": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestDoubleNAT(t *testing.T) {
+	defer feature.HookGatewayExternalIP.SetForTest(func(context.Context) (netip.Addr, error) {
+		return netip.Addr{}, errors.New("no gateway")
+	})()
+	if _, err := DoubleNAT(context.Background()); err == nil {
+		t.Error("with an unqueryable gateway: got nil error")
+	}
+}
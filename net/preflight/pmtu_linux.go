@@ -0,0 +1,100 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func init() {
+	probePathMTU = probePathMTULinux
+}
+
+// pathMTUReplyTimeout is how long probePathMTULinux waits for each echo
+// reply.
+const pathMTUReplyTimeout = time.Second
+
+// ipv4HeaderLen is the size of an IPv4 header without options, which the
+// kernel adds to the ICMP messages written to ICMP sockets.
+const ipv4HeaderLen = 20
+
+func probePathMTULinux(ctx context.Context, dst netip.Addr, sizes []int) (int, error) {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.IPPROTO_ICMP)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errNoICMPSocket, err)
+	}
+	// IP_PMTUDISC_PROBE sets the Don't Fragment bit but, unlike
+	// IP_PMTUDISC_DO, ignores the kernel's cached path MTU, which would
+	// otherwise reject larger packets before they're sent.
+	if err := unix.SetsockoptInt(fd, unix.IPPROTO_IP, unix.IP_MTU_DISCOVER, unix.IP_PMTUDISC_PROBE); err != nil {
+		unix.Close(fd)
+		return 0, err
+	}
+	f := os.NewFile(uintptr(fd), "icmp")
+	c, err := net.FilePacketConn(f)
+	f.Close()
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+	stop := context.AfterFunc(ctx, func() { c.SetDeadline(time.Now()) })
+	defer stop()
+
+	dstAddr := &net.UDPAddr{IP: dst.AsSlice()}
+	buf := make([]byte, 1500)
+	mtu := 0
+	for i, size := range sizes {
+		seq := uint16(i + 1)
+		req := make([]byte, size-ipv4HeaderLen)
+		req[0] = 8 // echo request; the kernel sets the ID and checksum
+		req[6], req[7] = byte(seq>>8), byte(seq)
+		if _, err := c.WriteTo(req, dstAddr); err != nil {
+			if errors.Is(err, unix.EMSGSIZE) {
+				// Larger than the outgoing interface's MTU.
+				break
+			}
+			return 0, err
+		}
+		if !awaitEchoReply(ctx, c, seq, buf) {
+			break
+		}
+		mtu = size
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if mtu == 0 {
+		return 0, fmt.Errorf("%w from %v with %d bytes", errNoEchoReply, dst, sizes[0])
+	}
+	return mtu, nil
+}
+
+// awaitEchoReply reports whether an ICMP echo reply with sequence number seq
+// arrives on c within pathMTUReplyTimeout.
+func awaitEchoReply(ctx context.Context, c net.PacketConn, seq uint16, buf []byte) bool {
+	deadline := time.Now().Add(pathMTUReplyTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.SetReadDeadline(deadline)
+	for ctx.Err() == nil {
+		n, _, err := c.ReadFrom(buf)
+		if err != nil {
+			return false
+		}
+		// The reply is an ICMP message, without the IPv4 header.
+		if n >= 8 && buf[0] == 0 && uint16(buf[6])<<8|uint16(buf[7]) == seq {
+			return true
+		}
+	}
+	return false
+}
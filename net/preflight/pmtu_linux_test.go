@@ -0,0 +1,29 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestProbePathMTULinux(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	mtu, err := probePathMTULinux(ctx, netip.MustParseAddr("127.0.0.1"), pathMTUProbeSizes)
+	if errors.Is(err, unix.EACCES) || errors.Is(err, unix.EPERM) || errors.Is(err, unix.EPROTONOSUPPORT) {
+		t.Skipf("unprivileged ICMP sockets not permitted: %v", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mtu != 1500 {
+		t.Errorf("loopback path MTU = %d; want 1500", mtu)
+	}
+}
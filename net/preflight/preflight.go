@@ -0,0 +1,722 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package preflight contains active checks of the host's network, such as
+// whether the control server, DNS and the internet over UDP and IPv6 are
+// reachable, and what kind of NAT the host is behind, for diagnosing
+// connectivity problems.
+//
+// Unlike the facts that package hostinfo reports, the checks here send
+// traffic and can take seconds, so they're meant to be run on demand, such
+// as from the CLI, and not from tailscaled's request paths.
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"tailscale.com/hostinfo"
+	"tailscale.com/net/bakedroots"
+	"tailscale.com/net/stun"
+	"tailscale.com/net/tsaddr"
+	"tailscale.com/paths"
+	"tailscale.com/safesocket"
+	"tailscale.com/tailcfg"
+)
+
+// DefaultControlURL is the control server that [ControlReachable] probes. It
+// matches tailscale.com/ipn.DefaultControlURL, which preflight doesn't
+// depend on.
+const DefaultControlURL = "https://controlplane.tailscale.com"
+
+// ControlReachable reports whether the node can reach the default control
+// server, [DefaultControlURL], over HTTPS. See [ControlURLReachable].
+func ControlReachable(ctx context.Context) (bool, error) {
+	return ControlURLReachable(ctx, DefaultControlURL)
+}
+
+// ControlURLReachable reports whether the node can reach the control server
+// at controlURL (such as "https://controlplane.tailscale.com" or a custom
+// server's URL) by fetching its unauthenticated public key endpoint. It's a
+// preflight for the most common kind of connectivity failure.
+//
+// It tests reachability only: a true result means a TLS connection was made
+// and the server answered, not that the node could log in. The request goes
+// through the HTTP proxy from the environment or, failing that, the system
+// proxy settings (see [hostinfo.OutboundProxy]). It fails when ctx is done.
+func ControlURLReachable(ctx context.Context, controlURL string) (bool, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = proxyForRequest
+	defer tr.CloseIdleConnections()
+	return controlURLReachable(ctx, &http.Client{Transport: tr}, controlURL)
+}
+
+func controlURLReachable(ctx context.Context, c *http.Client, controlURL string) (bool, error) {
+	keyURL := fmt.Sprintf("%v/key?v=%d", strings.TrimSuffix(controlURL, "/"), tailcfg.CurrentCapabilityVersion)
+	req, err := http.NewRequestWithContext(ctx, "GET", keyURL, nil)
+	if err != nil {
+		return false, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode >= 500 {
+		return false, fmt.Errorf("control server %v returned %v", controlURL, res.Status)
+	}
+	return true, nil
+}
+
+// DefaultDoHURL is the DNS-over-HTTPS resolver that [CanDoDoH] queries,
+// Cloudflare's.
+const DefaultDoHURL = "https://cloudflare-dns.com/dns-query"
+
+// CanDoDoH reports whether the node can resolve names with DNS-over-HTTPS,
+// querying [DefaultDoHURL]. See [CanDoDoHWith].
+func CanDoDoH(ctx context.Context) (bool, error) {
+	return CanDoDoHWith(ctx, DefaultDoHURL)
+}
+
+// CanDoDoHWith reports whether the node can resolve names with
+// DNS-over-HTTPS, using the resolver at dohURL (such as
+// "https://dns.google/dns-query"). Networks that intercept or block DNS
+// often let DoH through, so it's a fallback when the configured resolvers
+// don't work, unless the network blocks that too.
+//
+// It POSTs an RFC 8484 query for the A records of the host of
+// [DefaultControlURL] and reports true if the resolver answers it with a
+// DNS response, whether or not that has any records. The request goes
+// through the same proxy as [ControlURLReachable]'s. It fails if the
+// request fails or is answered with something else, and when ctx is done.
+func CanDoDoHWith(ctx context.Context, dohURL string) (bool, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = proxyForRequest
+	defer tr.CloseIdleConnections()
+	return canDoDoH(ctx, &http.Client{Transport: tr}, dohURL)
+}
+
+func canDoDoH(ctx context.Context, c *http.Client, dohURL string) (bool, error) {
+	u, err := url.Parse(DefaultControlURL)
+	if err != nil {
+		return false, err
+	}
+	name, err := dnsmessage.NewName(u.Hostname() + ".")
+	if err != nil {
+		return false, err
+	}
+	// RFC 8484 recommends an ID of 0, for cacheability.
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{RecursionDesired: true})
+	b.StartQuestions()
+	b.Question(dnsmessage.Question{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET})
+	q, err := b.Finish()
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", dohURL, bytes.NewReader(q))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	res, err := c.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if err != nil {
+		return false, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("DoH server %v returned %v", dohURL, res.Status)
+	}
+	var p dnsmessage.Parser
+	h, err := p.Start(body)
+	if err != nil {
+		return false, fmt.Errorf("DoH server %v returned an invalid DNS response: %w", dohURL, err)
+	}
+	if !h.Response {
+		return false, fmt.Errorf("DoH server %v didn't return a DNS response", dohURL)
+	}
+	return true, nil
+}
+
+// magicDNSProbeName is the name that MagicDNSReady looks up. tailscaled's
+// resolver always answers it with its own address, 100.100.100.100; it's
+// dnsSymbolicFQDN in tailscale.com/net/dns/resolver.
+const magicDNSProbeName = "magicdns.localhost-tailscale-daemon."
+
+// MagicDNSReady reports whether MagicDNS is working: whether the system's
+// configured resolver sends queries to tailscaled's, which answers them.
+// Right after the node joins a tailnet, or when the DNS configuration
+// changes, it takes a moment for tailscaled to install itself as the
+// system's resolver, and tailnet names don't resolve until then.
+//
+// It looks up the special name that tailscaled's resolver answers with its
+// own address, 100.100.100.100, as configured for MagicDNS, through the
+// system's resolver, and reports true if that's the answer. A name that
+// doesn't exist, or resolves elsewhere, means the queries go somewhere
+// else, and it reports false. Where the system only sends queries for the
+// tailnet's own domains to tailscaled (split DNS, as with systemd-resolved
+// when MagicDNS doesn't override the local DNS settings), it reports false
+// even though tailnet names resolve.
+//
+// It returns an error if the resolver can't be reached or fails
+// otherwise, and when ctx is done.
+func MagicDNSReady(ctx context.Context) (bool, error) {
+	return magicDNSReady(ctx, net.DefaultResolver)
+}
+
+func magicDNSReady(ctx context.Context, r *net.Resolver) (bool, error) {
+	ips, err := r.LookupNetIP(ctx, "ip4", magicDNSProbeName)
+	if err != nil {
+		if dnsErr, ok := errors.AsType[*net.DNSError](err); ok && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return slices.Contains(ips, tsaddr.TailscaleServiceIP()), nil
+}
+
+// TLSInterceptionDetected reports whether TLS connections to the control
+// server, [DefaultControlURL], appear to be intercepted by a TLS proxy, as
+// corporate networks' "TLS inspection" appliances do, and describes the
+// certificate presented in its place. Interception breaks the verification
+// that the control client and DERP connections do, so it's a common cause of
+// nodes in enterprises failing to connect.
+//
+// It makes an HTTPS request to the control server, through the HTTP proxy
+// that [ControlURLReachable] would use, and verifies the certificate chain
+// it's presented against the Let's Encrypt roots that Tailscale's control
+// certificates are issued under (see [bakedroots.Get]). A chain that doesn't
+// verify against them is taken to be from an interceptor, and the
+// description names its issuer and whether the system trusts it: a
+// corporate CA installed in the system's trust store is the usual sign of
+// deliberate inspection. It returns (false, "", nil) when the chain looks
+// clean, and an error if no TLS connection could be made or when ctx is
+// done.
+//
+// False positives are possible when control's certificates move to another
+// CA, as the baked-in roots then also have to change. An interceptor that
+// passes the connection through untouched, as some do for hosts on an
+// exclusion list, isn't detected.
+func TLSInterceptionDetected(ctx context.Context) (bool, string, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = proxyForRequest
+	// Verification is done by tlsInterception, so that the certificate of
+	// an interceptor can be described rather than just failing.
+	tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	defer tr.CloseIdleConnections()
+	return tlsInterceptionDetected(ctx, &http.Client{Transport: tr}, DefaultControlURL, bakedroots.Get())
+}
+
+func tlsInterceptionDetected(ctx context.Context, c *http.Client, rawURL string, roots *x509.CertPool) (bool, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return false, "", err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return false, "", err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.TLS == nil || len(res.TLS.PeerCertificates) == 0 {
+		return false, "", fmt.Errorf("no TLS certificate from %v", rawURL)
+	}
+	intercepted, desc := tlsInterception(res.TLS.PeerCertificates, req.URL.Hostname(), roots)
+	return intercepted, desc, nil
+}
+
+// tlsInterception reports whether certs, the chain a server presented for
+// host, fails to verify against roots, and if so describes it.
+func tlsInterception(certs []*x509.Certificate, host string, roots *x509.CertPool) (bool, string) {
+	leaf := certs[0]
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	opts := x509.VerifyOptions{DNSName: host, Roots: roots, Intermediates: intermediates}
+	if _, err := leaf.Verify(opts); err == nil {
+		return false, ""
+	}
+	trust := "which the system doesn't trust"
+	opts.Roots = nil // the system's roots
+	if _, err := leaf.Verify(opts); err == nil {
+		trust = "which the system trusts"
+	}
+	return true, fmt.Sprintf("certificate for %v is issued by %q, %v", host, leaf.Issuer.String(), trust)
+}
+
+// proxyForRequest returns the HTTP proxy to use for req: the one from the
+// environment, or else the system one.
+func proxyForRequest(req *http.Request) (*url.URL, error) {
+	if u, err := http.ProxyFromEnvironment(req); u != nil || err != nil {
+		return u, err
+	}
+	p := hostinfo.SystemProxy()
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.Contains(p, "://") {
+		p = "http://" + p
+	}
+	return url.Parse(p)
+}
+
+// localAPIDialTimeout is how long LocalAPIReachable waits to connect.
+const localAPIDialTimeout = 2 * time.Second
+
+// LocalAPIReachable reports whether the tailscaled LocalAPI at
+// [paths.DefaultTailscaledSocket] accepts connections and, if not, why: "not
+// running", "permission denied", a timeout (which usually means a firewall),
+// or the connection error.
+//
+// It connects the way the CLI does, with [safesocket.ConnectContext]: over
+// the Unix socket on Linux and the other Unix platforms, over the named pipe
+// on Windows, and on macOS over the localhost TCP port of the sandboxed
+// tailscaled if there is one, otherwise the Unix socket. It hangs up without
+// sending a request. Early in the process's life safesocket retries in case
+// tailscaled is still starting, so a daemon that isn't running may then
+// report a timeout instead.
+func LocalAPIReachable() (bool, string) {
+	path := paths.DefaultTailscaledSocket()
+	if path == "" {
+		return false, "no LocalAPI socket on " + runtime.GOOS
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), localAPIDialTimeout)
+	defer cancel()
+	c, err := safesocket.ConnectContext(ctx, path)
+	if err != nil {
+		return false, localAPIFailureReason(err)
+	}
+	c.Close()
+	return true, ""
+}
+
+// errConnRefused is syscall.ECONNREFUSED, or nil on Plan 9, which doesn't
+// define it.
+var errConnRefused error
+
+// localAPIFailureReason describes why connecting to the LocalAPI failed with
+// err.
+func localAPIFailureReason(err error) string {
+	switch {
+	case errors.Is(err, fs.ErrNotExist), errConnRefused != nil && errors.Is(err, errConnRefused):
+		return "not running"
+	case errors.Is(err, fs.ErrPermission):
+		return "permission denied"
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return "timed out; likely blocked by a firewall"
+	}
+	return err.Error()
+}
+
+// pathMTUProbeSizes are the IPv4 packet sizes PathMTUTo probes with, in
+// increasing order. The smallest is the minimum every IPv4 host must
+// accept, and the largest Ethernet's MTU.
+var pathMTUProbeSizes = []int{576, 1280, 1400, 1420, 1460, 1480, 1492, 1500}
+
+// probePathMTU, if non-nil, sends an ICMP echo request of each of sizes
+// bytes (including the IPv4 header), without fragmentation, to dst in turn,
+// and returns the largest size that got a reply before the first one that
+// didn't.
+var probePathMTU func(ctx context.Context, dst netip.Addr, sizes []int) (int, error)
+
+// Errors returned by probePathMTU when no echo request is answered, and
+// when it can't open an ICMP socket, which may not be permitted.
+var (
+	errNoEchoReply  = errors.New("no ICMP echo reply")
+	errNoICMPSocket = errors.New("opening ICMP socket")
+)
+
+// PathMTUToControl probes the path MTU to the default control server, the
+// host of [DefaultControlURL]. See [PathMTUTo].
+func PathMTUToControl(ctx context.Context) (mtu int, constrained bool, err error) {
+	u, err := url.Parse(DefaultControlURL)
+	if err != nil {
+		return 0, false, err
+	}
+	return PathMTUTo(ctx, u.Hostname())
+}
+
+// PathMTUTo probes the IPv4 path MTU to host, a hostname or IP address, and
+// reports whether it's constrained, less than the 1500 bytes of Ethernet.
+// Links along the way with a smaller MTU that drop oversized packets without
+// sending back an ICMP "fragmentation needed" error (a path MTU black hole)
+// make connections stall once they send full-sized packets, which is among
+// the hardest connectivity failures to diagnose.
+//
+// It sends ICMP echo requests with the Don't Fragment bit set, of 576, 1280,
+// 1400, 1420, 1460, 1480, 1492 and 1500 bytes in turn, waiting up to a second
+// for each reply, and returns the size of the largest one answered before
+// the first unanswered or locally rejected one. It fails if none is
+// answered, which is also the case if host drops pings, and when ctx is done.
+// The probe is only supported on Linux, using an unprivileged ICMP socket,
+// which requires the process's group to be allowed by the
+// net.ipv4.ping_group_range sysctl.
+func PathMTUTo(ctx context.Context, host string) (mtu int, constrained bool, err error) {
+	if probePathMTU == nil {
+		return 0, false, fmt.Errorf("path MTU probing not supported on %s", runtime.GOOS)
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip4", host)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(ips) == 0 {
+		return 0, false, fmt.Errorf("no IPv4 address for %q", host)
+	}
+	mtu, err = probePathMTU(ctx, ips[0].Unmap(), pathMTUProbeSizes)
+	if err != nil {
+		return 0, false, err
+	}
+	return mtu, mtu < pathMTUProbeSizes[len(pathMTUProbeSizes)-1], nil
+}
+
+// probeMSS, if non-nil, makes a TCP connection to target (a host:port) and
+// returns the MSS the connection ended up with, and the one it would have
+// had if nothing along the path lowered it, as derived from the outgoing
+// interface's MTU.
+var probeMSS func(ctx context.Context, target string) (mss, want int, err error)
+
+// MSSClampingDetected reports whether a middlebox appears to clamp the TCP
+// MSS of connections to the default control server, the host of
+// [DefaultControlURL], and to what. See [MSSClampingDetectedTo].
+func MSSClampingDetected(ctx context.Context) (clamped bool, mss int, err error) {
+	u, err := url.Parse(DefaultControlURL)
+	if err != nil {
+		return false, 0, err
+	}
+	return MSSClampingDetectedTo(ctx, net.JoinHostPort(u.Hostname(), "443"))
+}
+
+// MSSClampingDetectedTo reports whether a middlebox appears to clamp the
+// TCP maximum segment size (MSS) of connections to target, a host:port, and
+// returns the MSS the connection got. Routers and firewalls rewrite the
+// MSS that TCP handshakes advertise to fit a smaller MTU further along, such
+// as that of a PPPoE or VPN link; aggressive clamping, to well below what
+// the path needs, cuts throughput.
+//
+// It connects to target and compares the MSS the kernel settled on, the
+// smaller of its own and the one the server's SYN-ACK advertised, with what
+// the outgoing interface's MTU allows (the MTU minus 40 bytes of IPv4 and
+// TCP headers, or 60 with IPv6). A smaller MSS is reported as clamping. That
+// can't tell a middlebox's rewriting apart from the server advertising a
+// small MSS itself, or from the node's own firewall clamping connections,
+// or find clamping done only to outgoing SYNs. Over links with very large
+// MTUs, such as loopback, Linux bounds the MSS by half the server's receive
+// window, which is also reported as clamping. The probe is only supported
+// on Linux, where the MSS is read with TCP_INFO. It fails if target can't
+// be connected to, and when ctx is done.
+func MSSClampingDetectedTo(ctx context.Context, target string) (clamped bool, mss int, err error) {
+	if probeMSS == nil {
+		return false, 0, fmt.Errorf("MSS probing not supported on %s", runtime.GOOS)
+	}
+	mss, want, err := probeMSS(ctx, target)
+	if err != nil {
+		return false, 0, err
+	}
+	return mss < want, mss, nil
+}
+
+// DefaultSTUNServer is the STUN server that [HasStablePublicIP] queries. All
+// Tailscale DERP servers also serve STUN on port 3478.
+const DefaultSTUNServer = "derp1.tailscale.com:3478"
+
+// publicIPRecheckDelay is how long PublicIPStable waits between its two
+// queries.
+const publicIPRecheckDelay = 5 * time.Second
+
+// HasStablePublicIP reports whether the node's public IP address appears
+// stable, querying [DefaultSTUNServer]. See [PublicIPStable].
+func HasStablePublicIP(ctx context.Context) (stable bool, ip string, err error) {
+	return PublicIPStable(ctx, DefaultSTUNServer)
+}
+
+// PublicIPStable reports whether the node's public IP address appears
+// stable, and returns it. It asks the STUN server stunServer (a host:port)
+// for the node's public address twice, five seconds apart, and compares the
+// IP addresses. Nodes behind NATs that change their public address often
+// have their direct connections break and re-establish (connection churn).
+//
+// It's a heuristic: an address that only changes every few hours looks
+// stable, and NATs that spread connections over several public addresses
+// look unstable. Only the address family that stunServer is reached over
+// is tested. It fails if either query fails or ctx is done before both
+// finish.
+func PublicIPStable(ctx context.Context, stunServer string) (stable bool, ip string, err error) {
+	first, err := stunPublicIP(ctx, stunServer)
+	if err != nil {
+		return false, "", err
+	}
+	select {
+	case <-ctx.Done():
+		return false, "", ctx.Err()
+	case <-time.After(publicIPRecheckDelay):
+	}
+	second, err := stunPublicIP(ctx, stunServer)
+	if err != nil {
+		return false, "", err
+	}
+	return first == second, second.String(), nil
+}
+
+// udpProbeAttempts is how many STUN requests OutboundUDPBlockedTo sends.
+const udpProbeAttempts = 3
+
+// udpProbeTimeout is how long OutboundUDPBlockedTo waits for the response
+// to each request. It's a variable for tests.
+var udpProbeTimeout = 2 * time.Second
+
+// OutboundUDPBlocked reports whether outbound UDP appears to be blocked,
+// querying [DefaultSTUNServer]. See [OutboundUDPBlockedTo].
+func OutboundUDPBlocked(ctx context.Context) (bool, error) {
+	return OutboundUDPBlockedTo(ctx, DefaultSTUNServer)
+}
+
+// OutboundUDPBlockedTo reports whether outbound UDP appears to be blocked,
+// as determined by sending STUN requests to stunServer (a host:port). When
+// it is, nodes can't make direct connections to each other and all traffic
+// is relayed through DERP.
+//
+// It sends up to three requests, waiting up to two seconds for a response
+// to each, and reports true only if none was answered. That's a heuristic:
+// the absence of a response isn't proof of blocking, as the server may be
+// down or the packets lost, and a response only shows that UDP to that
+// server's port works. It returns an error, rather than reporting UDP
+// blocked, if stunServer can't be resolved, the request can't be sent or
+// is actively refused, and when ctx is done.
+func OutboundUDPBlockedTo(ctx context.Context, stunServer string) (bool, error) {
+	for range udpProbeAttempts {
+		attemptCtx, cancel := context.WithTimeout(ctx, udpProbeTimeout)
+		_, err := stunPublicIP(attemptCtx, stunServer)
+		cancel()
+		switch {
+		case err == nil:
+			return false, nil
+		case ctx.Err() != nil:
+			return false, ctx.Err()
+		case !errors.Is(err, os.ErrDeadlineExceeded):
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// DefaultIPv6ProbeTarget is the host:port that [IPv6InternetReachable]
+// connects to. The host has only IPv6 addresses, so reaching it can't be
+// mistaken for IPv4 connectivity.
+const DefaultIPv6ProbeTarget = "ipv6.google.com:443"
+
+// ipv6ProbeTimeout is how long IPv6InternetReachableTo waits to connect.
+const ipv6ProbeTimeout = 5 * time.Second
+
+// IPv6InternetReachable reports whether the node can reach the internet over
+// IPv6, connecting to [DefaultIPv6ProbeTarget]. See
+// [IPv6InternetReachableTo].
+func IPv6InternetReachable(ctx context.Context) (bool, error) {
+	return IPv6InternetReachableTo(ctx, DefaultIPv6ProbeTarget)
+}
+
+// IPv6InternetReachableTo reports whether the node can reach target, a
+// host:port, over IPv6. Unlike having a global IPv6 address, which many
+// hosts do with their router dropping or not routing IPv6 traffic, success
+// shows that IPv6 paths to the internet work, so that IPv6 endpoints and
+// DERP addresses are worth trying.
+//
+// It makes a TCP connection to target's IPv6 addresses, waiting up to five
+// seconds, and hangs up once connected. If it can't connect, it returns
+// false and an error saying why, such as that there's no IPv6 route or
+// that the connection timed out. It also fails when ctx is done.
+func IPv6InternetReachableTo(ctx context.Context, target string) (bool, error) {
+	d := net.Dialer{Timeout: ipv6ProbeTimeout}
+	c, err := d.DialContext(ctx, "tcp6", target)
+	if err != nil {
+		return false, fmt.Errorf("connecting to %v over IPv6: %w", target, err)
+	}
+	c.Close()
+	return true, nil
+}
+
+// icmpProbeHost is the host that ICMPBlocked pings, the host of
+// [DefaultSTUNServer]. DERP servers answer pings.
+const icmpProbeHost = "derp1.tailscale.com"
+
+// icmpProbeAttempts is how many echo requests ICMPBlockedTo sends.
+const icmpProbeAttempts = 3
+
+// ICMPBlocked reports whether ICMP appears to be blocked, pinging a
+// Tailscale DERP server. See [ICMPBlockedTo].
+func ICMPBlocked(ctx context.Context) (bool, error) {
+	return ICMPBlockedTo(ctx, icmpProbeHost)
+}
+
+// ICMPBlockedTo reports whether ICMP between the node and host, a hostname
+// or IPv4 address, appears to be blocked. Firewalls that drop ICMP also
+// drop the "fragmentation needed" errors that path MTU discovery relies
+// on, so oversized packets vanish without a trace (see [PathMTUTo]).
+//
+// It sends up to three echo requests to host's first IPv4 address, each
+// waiting up to a second for a reply, and reports true only if none was
+// answered. On Linux, they're sent from an unprivileged ICMP socket. Where
+// that's not permitted (the process's group isn't in the
+// net.ipv4.ping_group_range sysctl) and on other platforms, which need
+// privileges for raw ICMP sockets, it runs the system's ping command
+// instead, as that's installed with the privileges it needs.
+//
+// That's a heuristic: a missing reply may equally mean host itself drops
+// pings, and a reply only shows that ICMP echo, not every ICMP message,
+// gets through. It returns an error, rather than reporting ICMP blocked, if
+// host can't be resolved or pinged at all, and when ctx is done.
+func ICMPBlockedTo(ctx context.Context, host string) (bool, error) {
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip4", host)
+	if err != nil {
+		return false, err
+	}
+	if len(ips) == 0 {
+		return false, fmt.Errorf("no IPv4 address for %q", host)
+	}
+	for range icmpProbeAttempts {
+		ok, err := ping(ctx, ips[0].Unmap())
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ping sends an ICMP echo request to ip and reports whether a reply arrived
+// within about a second. It uses probePathMTU if permitted, and the
+// system's ping command otherwise.
+func ping(ctx context.Context, ip netip.Addr) (bool, error) {
+	if probePathMTU != nil {
+		_, err := probePathMTU(ctx, ip, pathMTUProbeSizes[:1])
+		switch {
+		case err == nil:
+			return true, nil
+		case ctx.Err() != nil:
+			return false, ctx.Err()
+		case errors.Is(err, errNoEchoReply):
+			return false, nil
+		case !errors.Is(err, errNoICMPSocket):
+			return false, err
+		}
+	}
+	args := []string{"-c", "1", ip.String()}
+	if runtime.GOOS == "windows" {
+		args = []string{"-n", "1", "-w", "1000", ip.String()}
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(pingCtx, "ping", args...).Output()
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && pingCtx.Err() == nil {
+		return false, err
+	}
+	return pingReplied(out), nil
+}
+
+// pingReplied reports whether the output of the system's ping command shows
+// an echo reply. The replies printed on Linux, the BSDs, macOS and Windows
+// all include the packet's TTL, which a timeout or error message doesn't.
+func pingReplied(out []byte) bool {
+	return strings.Contains(strings.ToLower(string(out)), "ttl=")
+}
+
+// defaultGateway and neighborResolved are hostinfo's, swapped out in tests.
+var (
+	defaultGateway   = hostinfo.DefaultGateway
+	neighborResolved = hostinfo.NeighborResolved
+)
+
+// GatewayReachable reports whether the node's IPv4 default gateway, the next
+// hop of its primary default route, is reachable. A node that can't reach
+// its own gateway has a problem with its local network, not with Tailscale.
+//
+// It pings the gateway up to three times, as [ICMPBlockedTo] does, and
+// reports it reachable once one is answered. Many home routers and
+// firewalls don't answer pings, so on Linux, a gateway that doesn't
+// answer is still reported reachable if the pings got the kernel to resolve
+// its hardware address with ARP, as found in /proc/net/arp. That only shows
+// that the gateway is on the local link and answering ARP.
+//
+// It fails if the gateway can't be determined, which is supported on Linux,
+// macOS and Windows, when no ping can be sent, and when ctx is done.
+func GatewayReachable(ctx context.Context) (bool, error) {
+	gw, ok := defaultGateway()
+	if !ok {
+		return false, fmt.Errorf("finding the default gateway not supported on %s", runtime.GOOS)
+	}
+	if !gw.IsValid() {
+		return false, errors.New("no IPv4 default gateway")
+	}
+	for range icmpProbeAttempts {
+		ok, err := ping(ctx, gw)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return neighborResolved(gw), nil
+}
+
+// stunPublicIP returns the node's public IP address as reported by the STUN
+// server stunServer.
+func stunPublicIP(ctx context.Context, stunServer string) (netip.Addr, error) {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "udp", stunServer)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	defer c.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(5 * time.Second)
+	}
+	c.SetDeadline(deadline)
+
+	txID := stun.NewTxID()
+	if _, err := c.Write(stun.Request(txID)); err != nil {
+		return netip.Addr{}, err
+	}
+	buf := make([]byte, 1024)
+	for {
+		n, err := c.Read(buf)
+		if err != nil {
+			return netip.Addr{}, err
+		}
+		gotTxID, addr, err := stun.ParseResponse(buf[:n])
+		if err != nil || gotTxID != txID {
+			continue // not a response to our request
+		}
+		return addr.Addr().Unmap(), nil
+	}
+}
@@ -0,0 +1,12 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !plan9
+
+package preflight
+
+import "syscall"
+
+func init() {
+	errConnRefused = syscall.ECONNREFUSED
+}
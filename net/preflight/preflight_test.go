@@ -0,0 +1,328 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package preflight
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"tailscale.com/net/stun/stuntest"
+)
+
+func TestControlURLReachable(t *testing.T) {
+	var gotPath string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	ok, err := controlURLReachable(context.Background(), ts.Client(), ts.URL+"/")
+	if !ok || err != nil {
+		t.Errorf("got (%v, %v); want (true, nil)", ok, err)
+	}
+	if gotPath != "/key" {
+		t.Errorf("requested path %q; want /key", gotPath)
+	}
+
+	ts.Close()
+	if ok, err := controlURLReachable(context.Background(), ts.Client(), ts.URL); ok || err == nil {
+		t.Errorf("closed server: got (%v, %v); want (false, error)", ok, err)
+	}
+}
+
+func TestTLSInterceptionDetected(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	c := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	ctx := context.Background()
+
+	trusted := x509.NewCertPool()
+	trusted.AddCert(ts.Certificate())
+	if got, desc, err := tlsInterceptionDetected(ctx, c, ts.URL, trusted); got || desc != "" || err != nil {
+		t.Errorf("with trusted certificate: got (%v, %q, %v); want (false, \"\", nil)", got, desc, err)
+	}
+
+	got, desc, err := tlsInterceptionDetected(ctx, c, ts.URL, x509.NewCertPool())
+	if !got || err != nil {
+		t.Errorf("with untrusted certificate: got (%v, %q, %v); want (true, _, nil)", got, desc, err)
+	}
+	if want := "which the system doesn't trust"; !strings.Contains(desc, "Acme Co") || !strings.HasSuffix(desc, want) {
+		t.Errorf("description %q doesn't name the issuer or end with %q", desc, want)
+	}
+
+	ts.Close()
+	if _, _, err := tlsInterceptionDetected(ctx, c, ts.URL, trusted); err == nil {
+		t.Error("with closed server: got nil error")
+	}
+}
+
+func TestCanDoDoH(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q, _ := io.ReadAll(r.Body)
+		var p dnsmessage.Parser
+		h, err := p.Start(q)
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/dns-message" || err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		qs, _ := p.AllQuestions()
+		h.Response = true
+		b := dnsmessage.NewBuilder(nil, h)
+		b.StartQuestions()
+		for _, q := range qs {
+			b.Question(q)
+		}
+		resp, _ := b.Finish()
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(resp)
+	}))
+	defer ts.Close()
+	ctx := context.Background()
+
+	if ok, err := canDoDoH(ctx, ts.Client(), ts.URL+"/dns-query"); !ok || err != nil {
+		t.Errorf("got (%v, %v); want (true, nil)", ok, err)
+	}
+	ts.Close()
+	if ok, err := canDoDoH(ctx, ts.Client(), ts.URL+"/dns-query"); ok || err == nil {
+		t.Errorf("closed server: got (%v, %v); want (false, error)", ok, err)
+	}
+
+	notDNS := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>captive portal</html>"))
+	}))
+	defer notDNS.Close()
+	if ok, err := canDoDoH(ctx, notDNS.Client(), notDNS.URL); ok || err == nil {
+		t.Errorf("non-DNS server: got (%v, %v); want (false, error)", ok, err)
+	}
+}
+
+// fakeDNSResolver returns a resolver whose queries are answered by a DNS
+// server on localhost that answers A queries for name with ip, and others
+// with NXDOMAIN.
+func fakeDNSResolver(t *testing.T, name string, ip netip.Addr) *net.Resolver {
+	pc, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var p dnsmessage.Parser
+			h, err := p.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			q, err := p.Question()
+			if err != nil {
+				continue
+			}
+			h.Response = true
+			found := q.Name.String() == name && q.Type == dnsmessage.TypeA
+			if !found {
+				h.RCode = dnsmessage.RCodeNameError
+			}
+			b := dnsmessage.NewBuilder(nil, h)
+			b.StartQuestions()
+			b.Question(q)
+			if found {
+				b.StartAnswers()
+				b.AResource(dnsmessage.ResourceHeader{Name: q.Name, Class: q.Class, TTL: 60}, dnsmessage.AResource{A: ip.As4()})
+			}
+			resp, _ := b.Finish()
+			pc.WriteTo(resp, addr)
+		}
+	}()
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", pc.LocalAddr().String())
+		},
+	}
+}
+
+func TestMagicDNSReady(t *testing.T) {
+	ctx := context.Background()
+	r := fakeDNSResolver(t, magicDNSProbeName, netip.MustParseAddr("100.100.100.100"))
+	if ok, err := magicDNSReady(ctx, r); !ok || err != nil {
+		t.Errorf("with MagicDNS: got (%v, %v); want (true, nil)", ok, err)
+	}
+	r = fakeDNSResolver(t, "example.com.", netip.MustParseAddr("192.0.2.1"))
+	if ok, err := magicDNSReady(ctx, r); ok || err != nil {
+		t.Errorf("without MagicDNS: got (%v, %v); want (false, nil)", ok, err)
+	}
+}
+
+func TestSTUNPublicIP(t *testing.T) {
+	addr, cleanup := stuntest.Serve(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ip, err := stunPublicIP(ctx, addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ip.IsLoopback() {
+		t.Errorf("stunPublicIP = %v; want a loopback address", ip)
+	}
+}
+
+func TestOutboundUDPBlockedTo(t *testing.T) {
+	addr, cleanup := stuntest.Serve(t)
+	defer cleanup()
+	ctx := context.Background()
+	if blocked, err := OutboundUDPBlockedTo(ctx, addr.String()); blocked || err != nil {
+		t.Errorf("with STUN server: got (%v, %v); want (false, nil)", blocked, err)
+	}
+
+	// A UDP socket that never answers looks like blocked UDP.
+	silent, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	old := udpProbeTimeout
+	udpProbeTimeout = 50 * time.Millisecond
+	defer func() { udpProbeTimeout = old }()
+	if blocked, err := OutboundUDPBlockedTo(ctx, silent.LocalAddr().String()); !blocked || err != nil {
+		t.Errorf("with silent server: got (%v, %v); want (true, nil)", blocked, err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := OutboundUDPBlockedTo(canceled, silent.LocalAddr().String()); err == nil {
+		t.Error("with canceled context: got nil error")
+	}
+}
+
+func TestIPv6InternetReachableTo(t *testing.T) {
+	ln, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("no IPv6 loopback: %v", err)
+	}
+	addr := ln.Addr().String()
+	ctx := context.Background()
+	if ok, err := IPv6InternetReachableTo(ctx, addr); !ok || err != nil {
+		t.Errorf("with listener: got (%v, %v); want (true, nil)", ok, err)
+	}
+	ln.Close()
+	if ok, err := IPv6InternetReachableTo(ctx, addr); ok || err == nil {
+		t.Errorf("with closed listener: got (%v, %v); want (false, error)", ok, err)
+	}
+	if ok, err := IPv6InternetReachableTo(ctx, "127.0.0.1:443"); ok || err == nil {
+		t.Errorf("with IPv4 target: got (%v, %v); want (false, error)", ok, err)
+	}
+}
+
+func TestICMPBlockedTo(t *testing.T) {
+	old := probePathMTU
+	defer func() { probePathMTU = old }()
+	ctx := context.Background()
+
+	probePathMTU = func(ctx context.Context, dst netip.Addr, sizes []int) (int, error) {
+		return sizes[0], nil
+	}
+	if blocked, err := ICMPBlockedTo(ctx, "127.0.0.1"); blocked || err != nil {
+		t.Errorf("with replies: got (%v, %v); want (false, nil)", blocked, err)
+	}
+
+	probePathMTU = func(ctx context.Context, dst netip.Addr, sizes []int) (int, error) {
+		return 0, errNoEchoReply
+	}
+	if blocked, err := ICMPBlockedTo(ctx, "127.0.0.1"); !blocked || err != nil {
+		t.Errorf("without replies: got (%v, %v); want (true, nil)", blocked, err)
+	}
+}
+
+func TestGatewayReachable(t *testing.T) {
+	oldGW, oldNeigh, oldProbe := defaultGateway, neighborResolved, probePathMTU
+	defer func() { defaultGateway, neighborResolved, probePathMTU = oldGW, oldNeigh, oldProbe }()
+	ctx := context.Background()
+	gw := netip.MustParseAddr("192.168.1.1")
+	defaultGateway = func() (netip.Addr, bool) { return gw, true }
+
+	probePathMTU = func(ctx context.Context, dst netip.Addr, sizes []int) (int, error) {
+		if dst != gw {
+			t.Errorf("pinged %v; want %v", dst, gw)
+		}
+		return sizes[0], nil
+	}
+	if ok, err := GatewayReachable(ctx); !ok || err != nil {
+		t.Errorf("answering pings: got (%v, %v); want (true, nil)", ok, err)
+	}
+
+	probePathMTU = func(ctx context.Context, dst netip.Addr, sizes []int) (int, error) {
+		return 0, errNoEchoReply
+	}
+	neighborResolved = func(netip.Addr) bool { return true }
+	if ok, err := GatewayReachable(ctx); !ok || err != nil {
+		t.Errorf("answering ARP: got (%v, %v); want (true, nil)", ok, err)
+	}
+	neighborResolved = func(netip.Addr) bool { return false }
+	if ok, err := GatewayReachable(ctx); ok || err != nil {
+		t.Errorf("unreachable: got (%v, %v); want (false, nil)", ok, err)
+	}
+
+	defaultGateway = func() (netip.Addr, bool) { return netip.Addr{}, true }
+	if _, err := GatewayReachable(ctx); err == nil {
+		t.Error("without a gateway: got nil error")
+	}
+}
+
+func TestPingReplied(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want bool
+	}{
+		{"linux", "64 bytes from 1.1.1.1: icmp_seq=1 ttl=57 time=3.12 ms\n", true},
+		{"windows", "Reply from 1.1.1.1: bytes=32 time=3ms TTL=57\r\n", true},
+		{"linux-timeout", "1 packets transmitted, 0 received, 100% packet loss, time 0ms\n", false},
+		{"windows-timeout", "Request timed out.\r\n", false},
+		{"windows-unreachable", "Reply from 192.168.1.1: Destination host unreachable.\r\n", false},
+	}
+	for _, tt := range tests {
+		if got := pingReplied([]byte(tt.out)); got != tt.want {
+			t.Errorf("%s: pingReplied = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLocalAPIFailureReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ENOENT}}, "not running"},
+		{&net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: errConnRefused}}, "not running"},
+		{&net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.EACCES}}, "permission denied"},
+		{context.DeadlineExceeded, "timed out; likely blocked by a firewall"},
+		{errors.New("boom"), "boom"},
+	}
+	for _, tt := range tests {
+		if got := localAPIFailureReason(tt.err); got != tt.want {
+			t.Errorf("localAPIFailureReason(%v) = %q; want %q", tt.err, got, tt.want)
+		}
+	}
+}
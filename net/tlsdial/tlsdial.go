@@ -33,12 +33,16 @@
 	"tailscale.com/hostinfo"
 	"tailscale.com/net/bakedroots"
 	"tailscale.com/net/tlsdial/blockblame"
+	"tailscale.com/version"
 )
 
 var counterFallbackOK int32 // atomic
 
 var debug = envknob.RegisterBool("TS_DEBUG_TLS_DIAL")
 
+// minTLSVersion is [version.MinTLSVersion], replaced in tests.
+var minTLSVersion = version.MinTLSVersion
+
 // tlsdialWarningPrinted tracks whether we've printed a warning about a given
 // hostname already, to avoid log spam for users with custom DERP servers,
 // Headscale, etc.
@@ -80,6 +84,12 @@ func Config(ht *health.Tracker, base *tls.Config) *tls.Config {
 	}
 	conf.RootCAs = nil // we do our own verification in VerifyConnection
 
+	// Builds whose policy requires TLS 1.3 don't negotiate anything older,
+	// even if base allows it.
+	if minTLSVersion() == "1.3" && conf.MinVersion < tls.VersionTLS13 {
+		conf.MinVersion = tls.VersionTLS13
+	}
+
 	// Note: we do NOT set conf.ServerName here (as we accidentally did
 	// previously), as this path is also used when dialing an HTTPS proxy server
 	// (through which we'll send a CONNECT request to get a TCP connection to do
@@ -4,6 +4,7 @@
 package tlsdial
 
 import (
+	"crypto/tls"
 	"io"
 	"net"
 	"net/http"
@@ -110,3 +111,29 @@ func TestFallbackRootWorks(t *testing.T) {
 func sayHi(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, "hi")
 }
+
+func TestConfigMinTLSVersion(t *testing.T) {
+	old := minTLSVersion
+	t.Cleanup(func() { minTLSVersion = old })
+
+	tests := []struct {
+		policy  string
+		baseMin uint16 // base config's MinVersion, or 0 for a nil base
+		want    uint16
+	}{
+		{"1.2", 0, 0},
+		{"1.2", tls.VersionTLS13, tls.VersionTLS13},
+		{"1.3", 0, tls.VersionTLS13},
+		{"1.3", tls.VersionTLS12, tls.VersionTLS13},
+	}
+	for _, tt := range tests {
+		minTLSVersion = func() string { return tt.policy }
+		var base *tls.Config
+		if tt.baseMin != 0 {
+			base = &tls.Config{MinVersion: tt.baseMin}
+		}
+		if got := Config(nil, base).MinVersion; got != tt.want {
+			t.Errorf("policy %s, base MinVersion %#x: MinVersion = %#x; want %#x", tt.policy, tt.baseMin, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tstun
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tailscale.com/net/packet"
+)
+
+// BandwidthLimit caps the rate of the packets that arrive from WireGuard
+// with a source or destination address in Prefix. A single peer is limited
+// with its Tailscale IP as a single-address prefix; a subnet route with the
+// route's prefix.
+//
+// The limit is shared by all matching packets, so that, on a subnet router,
+// one noisy peer can't saturate the uplink.
+type BandwidthLimit struct {
+	Prefix     netip.Prefix
+	BitsPerSec int64
+}
+
+// bandwidthUnits are the suffixes ParseBandwidthLimits accepts for rates,
+// by multiplier.
+var bandwidthUnits = []struct {
+	suffix string
+	mult   int64
+}{
+	{"gbit", 1e9},
+	{"mbit", 1e6},
+	{"kbit", 1e3},
+	{"bit", 1},
+}
+
+// ParseBandwidthLimits parses s, a comma-separated list of limits of the
+// form "ip-or-prefix=rate" such as "100.64.0.5=10Mbit,10.0.0.0/24=500kbit".
+// The rate is in bits per second, with an optional case-insensitive
+// "bit", "kbit", "mbit" or "gbit" suffix.
+func ParseBandwidthLimits(s string) ([]BandwidthLimit, error) {
+	var limits []BandwidthLimit
+	for f := range strings.SplitSeq(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		addr, rate, ok := strings.Cut(f, "=")
+		if !ok {
+			return nil, fmt.Errorf("bandwidth limit %q: want ip-or-prefix=rate", f)
+		}
+		var pfx netip.Prefix
+		var err error
+		if strings.Contains(addr, "/") {
+			pfx, err = netip.ParsePrefix(addr)
+			pfx = pfx.Masked()
+		} else {
+			var ip netip.Addr
+			ip, err = netip.ParseAddr(addr)
+			pfx = netip.PrefixFrom(ip, ip.BitLen())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bandwidth limit %q: %w", f, err)
+		}
+		bps, err := parseBitRate(rate)
+		if err != nil {
+			return nil, fmt.Errorf("bandwidth limit %q: %w", f, err)
+		}
+		limits = append(limits, BandwidthLimit{Prefix: pfx, BitsPerSec: bps})
+	}
+	return limits, nil
+}
+
+func parseBitRate(s string) (int64, error) {
+	num, mult := strings.ToLower(s), int64(1)
+	for _, u := range bandwidthUnits {
+		if n, ok := strings.CutSuffix(num, u.suffix); ok {
+			num, mult = n, u.mult
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	return int64(v * float64(mult)), nil
+}
+
+// minBandwidthBurst is the smallest burst, in bytes, that a bandwidth
+// limit allows, so that a single GRO-coalesced packet can always pass.
+const minBandwidthBurst = 64 << 10
+
+// tokenBucket is a byte-based token bucket for one BandwidthLimit.
+type tokenBucket struct {
+	prefix netip.Prefix
+	rate   float64 // bytes per second
+	burst  float64 // bytes
+
+	tokens float64
+	last   time.Time
+}
+
+// shaper applies a set of BandwidthLimits to packets.
+type shaper struct {
+	mu      sync.Mutex
+	buckets []*tokenBucket // in the order of the limits; first match wins
+}
+
+func newShaper(limits []BandwidthLimit) *shaper {
+	s := new(shaper)
+	for _, l := range limits {
+		rate := float64(l.BitsPerSec) / 8
+		burst := max(rate/10, minBandwidthBurst) // 100ms worth
+		s.buckets = append(s.buckets, &tokenBucket{
+			prefix: l.Prefix,
+			rate:   rate,
+			burst:  burst,
+			tokens: burst,
+		})
+	}
+	return s
+}
+
+// allow reports whether p fits within the first limit that matches it at
+// time now, taking its size from the limit if so. Packets that match no
+// limit are always allowed.
+func (s *shaper) allow(p *packet.Parsed, now time.Time) bool {
+	src, dst := p.Src.Addr(), p.Dst.Addr()
+	for _, b := range s.buckets {
+		if !b.prefix.Contains(src) && !b.prefix.Contains(dst) {
+			continue
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !b.last.IsZero() {
+			b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		}
+		b.last = now
+		n := float64(len(p.Buffer()))
+		if b.tokens < n {
+			return false
+		}
+		b.tokens -= n
+		return true
+	}
+	return true
+}
+
+// SetBandwidthLimits sets the limits on the rate of packets arriving from
+// WireGuard, replacing any set before. Packets over a limit are dropped.
+// A nil or empty limits removes all limits.
+func (t *Wrapper) SetBandwidthLimits(limits []BandwidthLimit) {
+	if len(limits) == 0 {
+		t.shaper.Store(nil)
+		return
+	}
+	t.shaper.Store(newShaper(limits))
+}
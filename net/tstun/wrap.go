@@ -169,6 +169,9 @@ type Wrapper struct {
 	// jailedFilter is the packet filter for jailed nodes.
 	// Can be nil, which means drop all packets.
 	jailedFilter atomic.Pointer[filter.Filter]
+	// shaper, if non-nil, enforces the limits set by SetBandwidthLimits
+	// on packets from WireGuard that the filter accepted.
+	shaper atomic.Pointer[shaper]
 
 	// PreFilterPacketInboundFromWireGuard is the inbound filter function that runs before the main filter
 	// and therefore sees the packets that may be later dropped by it.
@@ -315,6 +318,15 @@ func wrap(logf logger.Logf, tdev tun.Device, isTAP bool, m *usermetric.Registry,
 		}
 	}
 
+	if v := envknob.String("TS_DEBUG_PEER_BANDWIDTH"); v != "" {
+		limits, err := ParseBandwidthLimits(v)
+		if err != nil {
+			w.logf("ignoring TS_DEBUG_PEER_BANDWIDTH: %v", err)
+		} else {
+			w.SetBandwidthLimits(limits)
+		}
+	}
+
 	w.eventClient = bus.Client("net.tstun")
 	w.discoKeyAdvertisementPub = eventbus.Publish[events.DiscoKeyAdvertisement](w.eventClient)
 
@@ -1204,6 +1216,11 @@ func (t *Wrapper) filterPacketInboundFromWireGuard(p *packet.Parsed, captHook pa
 		return filter.Drop, gro
 	}
 
+	if sh := t.shaper.Load(); sh != nil && !sh.allow(p, t.now()) {
+		metricPacketInDropBandwidth.Add(1)
+		return filter.DropSilently, gro
+	}
+
 	if t.PostFilterPacketInboundFromWireGuardAppConnector != nil {
 		if res := t.PostFilterPacketInboundFromWireGuardAppConnector(p, t); res.IsDrop() {
 			// Handled by userspaceEngine's configured hook for Connectors 2025 app connectors.
@@ -1510,6 +1527,7 @@ func (t *Wrapper) SetConnectionCounter(fn netlogfunc.ConnectionCounter) {
 	metricPacketInDrop          = clientmetric.NewCounter("tstun_in_from_wg_drop")
 	metricPacketInDropFilter    = clientmetric.NewCounter("tstun_in_from_wg_drop_filter")
 	metricPacketInDropSelfDisco = clientmetric.NewCounter("tstun_in_from_wg_drop_self_disco")
+	metricPacketInDropBandwidth = clientmetric.NewCounter("tstun_in_from_wg_drop_bandwidth")
 
 	metricPacketOut              = clientmetric.NewCounter("tstun_out_to_wg")
 	metricPacketOutDrop          = clientmetric.NewCounter("tstun_out_to_wg_drop")
@@ -1269,3 +1269,56 @@ func TestFilterDropTSMP(t *testing.T) {
 			metricPacketOutDropTSMP.Value(), wantMetric)
 	}
 }
+
+func TestParseBandwidthLimits(t *testing.T) {
+	got, err := ParseBandwidthLimits("100.64.0.5=10Mbit, 10.0.0.1/24=500kbit,fd7a::1=2000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BandwidthLimit{
+		{netip.MustParsePrefix("100.64.0.5/32"), 10e6},
+		{netip.MustParsePrefix("10.0.0.0/24"), 500e3},
+		{netip.MustParsePrefix("fd7a::1/128"), 2000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	for _, bad := range []string{"100.64.0.5", "100.64.0.5=fast", "bogus=1Mbit", "100.64.0.5=-1bit"} {
+		if _, err := ParseBandwidthLimits(bad); err == nil {
+			t.Errorf("ParseBandwidthLimits(%q) succeeded; want error", bad)
+		}
+	}
+}
+
+func TestShaper(t *testing.T) {
+	// 4 Mbit/s is 500 KB/s, with the minimum burst of 64 KiB.
+	sh := newShaper([]BandwidthLimit{{netip.MustParsePrefix("10.0.0.0/24"), 4e6}})
+	now := time.Unix(1, 0)
+
+	limited := new(packet.Parsed)
+	limited.Decode(udp4("100.64.0.5", "10.0.0.7", 1, 2))
+	other := new(packet.Parsed)
+	other.Decode(udp4("100.64.0.5", "10.0.1.7", 1, 2))
+	size := len(limited.Buffer())
+
+	sent := 0
+	for sh.allow(limited, now) {
+		sent += size
+	}
+	if sent > minBandwidthBurst || sent < minBandwidthBurst-size {
+		t.Errorf("burst allowed %d bytes; want about %d", sent, minBandwidthBurst)
+	}
+	if !sh.allow(other, now) {
+		t.Error("packet matching no limit was dropped")
+	}
+
+	// 10ms later, 5 KB more is allowed.
+	now = now.Add(10 * time.Millisecond)
+	sent = 0
+	for sh.allow(limited, now) {
+		sent += size
+	}
+	if sent > 5000 || sent < 5000-size {
+		t.Errorf("after 10ms, allowed %d bytes; want about 5000", sent)
+	}
+}
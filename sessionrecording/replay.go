@@ -0,0 +1,78 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package sessionrecording
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Replay plays back the asciinema v2 recording read from r, as written for
+// Tailscale SSH sessions, by writing its output to w with the original
+// timing. Pauses longer than maxIdle are shortened to maxIdle, unless
+// maxIdle is zero.
+func Replay(ctx context.Context, w io.Writer, r io.Reader, maxIdle time.Duration) error {
+	br := bufio.NewReader(r)
+	line, err := br.ReadBytes('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	var hdr CastHeader
+	if err := json.Unmarshal(line, &hdr); err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+	if hdr.Version != 2 {
+		return fmt.Errorf("unsupported asciinema version %d", hdr.Version)
+	}
+
+	var last float64 // time of the previous event, in seconds
+	for n := 2; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			var ev []any
+			if err := json.Unmarshal(line, &ev); err != nil {
+				return fmt.Errorf("line %d: %w", n, err)
+			}
+			if len(ev) != 3 {
+				return fmt.Errorf("line %d: invalid event", n)
+			}
+			t, tok := ev[0].(float64)
+			typ, _ := ev[1].(string)
+			data, dok := ev[2].(string)
+			if !tok || !dok {
+				return fmt.Errorf("line %d: invalid event", n)
+			}
+			if typ != "o" {
+				continue
+			}
+			d := time.Duration((t - last) * float64(time.Second))
+			last = t
+			if maxIdle > 0 {
+				d = min(d, maxIdle)
+			}
+			if d > 0 {
+				timer := time.NewTimer(d)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+			if _, err := io.WriteString(w, data); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package sessionrecording
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name    string
+		cast    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "output",
+			cast: `{"version":2,"width":80,"height":24,"timestamp":1}
+[0.001,"o","hello "]
+[0.002,"i","ignored"]
+[0.5,"o","world\r\n"]`,
+			want: "hello world\r\n",
+		},
+		{
+			name: "header-only",
+			cast: `{"version":2,"width":80,"height":24,"timestamp":1}` + "\n",
+		},
+		{
+			name:    "bad-version",
+			cast:    `{"version":1}`,
+			wantErr: "unsupported asciinema version 1",
+		},
+		{
+			name: "bad-event",
+			cast: `{"version":2}
+[0.1,"o"]`,
+			wantErr: "line 2: invalid event",
+		},
+		{
+			name:    "empty",
+			wantErr: "reading header",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got strings.Builder
+			// The 0.5s pause is shortened by maxIdle.
+			err := Replay(context.Background(), &got, strings.NewReader(tt.cast), time.Millisecond)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Replay error = %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %q; want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
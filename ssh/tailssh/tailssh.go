@@ -1150,6 +1150,19 @@ func (ss *sshSession) run() {
 // coordination server. This will be removed in the future.
 var recordSSHToLocalDisk = envknob.RegisterBool("TS_DEBUG_LOG_SSH")
 
+// localRecordingDir, if non-empty, is a directory to record SSH sessions to
+// as asciinema files, for when recordings shouldn't leave the host. Like
+// TS_DEBUG_LOG_SSH, it's only used if there is no recording configured by
+// the coordination server. Recordings can be played back with
+// "tailscale debug ssh-replay".
+var localRecordingDir = envknob.RegisterString("TS_SSH_RECORDING_DIR")
+
+// recordLocally reports whether to record sessions to local storage when
+// the coordination server configures no recorders.
+func recordLocally() bool {
+	return recordSSHToLocalDisk() || localRecordingDir() != ""
+}
+
 // recorders returns the list of recorders to use for this session.
 // If the final action has a non-empty list of recorders, that list is
 // returned. Otherwise, the list of recorders from the initial action
@@ -1163,7 +1176,7 @@ func (ss *sshSession) recorders() ([]netip.AddrPort, *tailcfg.SSHRecorderFailure
 
 func (ss *sshSession) shouldRecord() bool {
 	recs, _ := ss.recorders()
-	return len(recs) > 0 || recordSSHToLocalDisk()
+	return len(recs) > 0 || recordLocally()
 }
 
 type sshConnInfo struct {
@@ -1309,11 +1322,14 @@ func randBytes(n int) []byte {
 }
 
 func (ss *sshSession) openFileForRecording(now time.Time) (_ io.WriteCloser, err error) {
-	varRoot := ss.conn.srv.lb.TailscaleVarRoot()
-	if varRoot == "" {
-		return nil, errors.New("no var root for recording storage")
+	dir := localRecordingDir()
+	if dir == "" {
+		varRoot := ss.conn.srv.lb.TailscaleVarRoot()
+		if varRoot == "" {
+			return nil, errors.New("no var root for recording storage")
+		}
+		dir = filepath.Join(varRoot, "ssh-sessions")
 	}
-	dir := filepath.Join(varRoot, "ssh-sessions")
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return nil, err
 	}
@@ -1337,7 +1353,7 @@ func (ss *sshSession) startNewRecording() (_ *recording, err error) {
 	recorders, onFailure := ss.recorders()
 	var localRecording bool
 	if len(recorders) == 0 {
-		if recordSSHToLocalDisk() {
+		if recordLocally() {
 			localRecording = true
 		} else {
 			return nil, errors.New("no recorders configured")
@@ -1614,6 +1614,10 @@ type CapGrant struct {
 	// relay endpoints to the peer which has this capability.
 	PeerCapabilityRelayTarget PeerCapability = "tailscale.com/cap/relay-target"
 
+	// PeerCapabilityPeerServices grants a peer the ability to list the
+	// services registered on this node with the peer services feature.
+	PeerCapabilityPeerServices PeerCapability = "tailscale.com/cap/peer-services"
+
 	// PeerCapabilityTsIDP grants a peer tsidp-specific
 	// capabilities, such as the ability to add user groups to the OIDC
 	// claim
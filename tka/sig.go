@@ -347,6 +347,38 @@ func (s *NodeKeySignature) rotationDetails() (*RotationDetails, error) {
 	return sri, nil
 }
 
+// NodeKeySigner makes node-key signatures with a tailnet lock key.
+//
+// key.NLPrivate implements it, but the point of the interface is that the
+// private key needn't be in memory, or on disk: it can be held by a
+// hardware security module such as a PIV smart card or a TPM.
+type NodeKeySigner interface {
+	// KeyID returns the ID of the signing key, as in the key authority.
+	KeyID() tkatype.KeyID
+	// SignNKS returns an ed25519 signature over sigHash.
+	SignNKS(sigHash tkatype.NKSSigHash) ([]byte, error)
+}
+
+// SignNodeKey returns a SigDirect signature of nodeKey by signer.
+// rotationPublic, if specified, must be an ed25519 public key.
+func SignNodeKey(signer NodeKeySigner, nodeKey key.NodePublic, rotationPublic []byte) (NodeKeySignature, error) {
+	nk, err := nodeKey.MarshalBinary()
+	if err != nil {
+		return NodeKeySignature{}, fmt.Errorf("marshalling node-key: %w", err)
+	}
+	sig := NodeKeySignature{
+		SigKind:        SigDirect,
+		KeyID:          signer.KeyID(),
+		Pubkey:         nk,
+		WrappingPubkey: rotationPublic,
+	}
+	sig.Signature, err = signer.SignNKS(sig.SigHash())
+	if err != nil {
+		return NodeKeySignature{}, fmt.Errorf("signature failed: %w", err)
+	}
+	return sig, nil
+}
+
 // ResignNKS re-signs a node-key signature for a new node-key.
 //
 // This only matters on tailnet-locked tailnets, because node-key signatures are
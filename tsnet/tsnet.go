@@ -137,6 +137,8 @@
 package tsnet
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	crand "crypto/rand"
 	"crypto/tls"
@@ -197,6 +199,7 @@
 	"tailscale.com/util/set"
 	"tailscale.com/util/testenv"
 	"tailscale.com/wgengine"
+	"tailscale.com/wgengine/magicsock"
 	"tailscale.com/wgengine/netstack"
 )
 
@@ -303,11 +306,32 @@ type Server struct {
 	// that the control server will allow the node to adopt that tag.
 	AdvertiseTags []string
 
+	// AdvertiseRoutes specifies subnet routes that this node offers to
+	// route for other nodes. Connections to them are handled by
+	// [Server.RegisterFallbackTCPHandler] handlers or, with a custom Tun,
+	// by the Tun. The control server may still need to approve them.
+	AdvertiseRoutes []netip.Prefix
+
 	// Tun, if non-nil, specifies a custom tun.Device to use for packet I/O.
 	//
 	// This field must be set before calling Start.
 	Tun tun.Device
 
+	// PathPolicy, if non-nil, overrides the choice of UDP path to peers.
+	// See [magicsock.PathPolicy].
+	//
+	// This field must be set before calling Start.
+	PathPolicy magicsock.PathPolicy
+
+	// OnCertRenew, if non-nil, is called when [Server.GetCertificate] (and
+	// so ListenTLS and ListenFunnel) starts serving a different certificate
+	// for a name than it did before, as when the certificate was renewed.
+	// Renewal happens in the background as expiry nears, so cert is only
+	// seen by the first handshake after it.
+	//
+	// It's called during that TLS handshake and must not block.
+	OnCertRenew func(name string, cert *tls.Certificate)
+
 	initOnce            sync.Once
 	initErr             error
 	lb                  *ipnlocal.LocalBackend
@@ -329,12 +353,15 @@ type Server struct {
 	logtail             *logtail.Logger
 	logid               logid.PublicID
 
+	reconfigMu sync.Mutex // serializes Reconfigure calls
+
 	mu                  sync.Mutex
 	listeners           map[listenKey]*listener
 	nextEphemeralPort   uint16 // next port to try in ephemeral range; 0 means use ephemeralPortFirst
 	fallbackTCPHandlers set.HandleSet[FallbackTCPHandler]
 	dialer              *tsdial.Dialer
 	advertisedServices  map[tailcfg.ServiceName]int
+	certLeaf            map[string][]byte // name => DER of the last leaf certificate served, for OnCertRenew
 	closeOnce           sync.Once
 }
 
@@ -353,6 +380,13 @@ type Server struct {
 
 // Dial connects to the address on the tailnet.
 // It will start the server if it has not been started yet.
+//
+// Addresses outside the tailnet are dialed through Tailscale too if they're
+// routed via a peer: a subnet router's routes, or any address when an exit
+// node is selected (for instance with [local.Client.EditPrefs] setting
+// [ipn.Prefs.ExitNodeID]). This lets a program send its egress traffic via
+// an exit node without any changes to the OS's routing. Other addresses are
+// dialed with the system dialer.
 func (s *Server) Dial(ctx context.Context, network, address string) (net.Conn, error) {
 	if err := s.Start(); err != nil {
 		return nil, err
@@ -395,7 +429,7 @@ func (s *Server) awaitRunning(ctx context.Context) error {
 // HTTPClient returns an HTTP client that is configured to connect over Tailscale.
 //
 // This is useful if you need to have your tsnet services connect to other devices on
-// your tailnet.
+// your tailnet, or to the internet via an exit node. See [Server.Dial].
 func (s *Server) HTTPClient() *http.Client {
 	return &http.Client{
 		Transport: &http.Transport{
@@ -709,6 +743,107 @@ func (s *Server) TailscaleIPs() (ip4, ip6 netip.Addr) {
 	return ip4, ip6
 }
 
+// SetServeConfig replaces the server's serve config, the same configuration
+// that "tailscale serve" and "tailscale funnel" edit. It lets a program
+// reverse proxy paths to local backends, serve static files or text, and
+// expose them over Funnel (via
+// [ipn.ServeConfig.AllowFunnel]) without running the CLI or a separate
+// tailscaled. A nil or empty sc clears the config.
+//
+// It calls [Server.Up] first, as the server's first Up clears any serve
+// config set before it. Contents served this way are handled by the
+// server itself; connections for ports it serves don't reach listeners
+// from [Server.Listen] or [Server.ListenTLS].
+//
+// Listeners from [Server.ListenFunnel] and [Server.ListenService] edit the
+// serve config too, so replacing it while they're open may break them.
+// Use [Server.LocalClient] and its GetServeConfig to edit it in place
+// instead.
+func (s *Server) SetServeConfig(ctx context.Context, sc *ipn.ServeConfig) error {
+	if _, err := s.Up(ctx); err != nil {
+		return err
+	}
+	if sc == nil {
+		sc = new(ipn.ServeConfig)
+	}
+	return s.lb.SetServeConfig(sc, "")
+}
+
+// Config is the part of a [Server]'s configuration that
+// [Server.Reconfigure] can change while the server is running. Its fields
+// are as in Server, except that zero values keep the current setting: an
+// empty Hostname keeps the current hostname, and nil AdvertiseTags or
+// AdvertiseRoutes keep the current tags or routes. To stop advertising all
+// tags or routes, use a non-nil empty slice.
+type Config struct {
+	Hostname        string
+	AdvertiseTags   []string
+	AdvertiseRoutes []netip.Prefix
+}
+
+// Reconfigure applies cfg to the running server, starting it first if
+// needed, without restarting it. The node keeps its node key and Tailscale
+// IPs, and existing connections are unaffected.
+//
+// Changing the hostname or advertised routes takes effect immediately.
+// Changing AdvertiseTags requires the node to log in again, with a new auth
+// key resolved from the server's AuthKey, ClientSecret or ID token for the
+// new tags, so it fails if the server has none of those.
+func (s *Server) Reconfigure(ctx context.Context, cfg Config) error {
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("tsnet: %w", err)
+	}
+	s.reconfigMu.Lock()
+	defer s.reconfigMu.Unlock()
+
+	hostname := cmp.Or(cfg.Hostname, s.hostname)
+	cur := s.lb.Prefs()
+	if cfg.AdvertiseTags == nil {
+		cfg.AdvertiseTags = cur.AdvertiseTags().AsSlice()
+	}
+	if cfg.AdvertiseRoutes == nil {
+		cfg.AdvertiseRoutes = cur.AdvertiseRoutes().AsSlice()
+	}
+	tagsChanged := !slices.Equal(cur.AdvertiseTags().AsSlice(), cfg.AdvertiseTags)
+	if !tagsChanged {
+		_, err := s.lb.EditPrefs(&ipn.MaskedPrefs{
+			HostnameSet:        true,
+			AdvertiseRoutesSet: true,
+			Prefs: ipn.Prefs{
+				Hostname:        hostname,
+				AdvertiseRoutes: cfg.AdvertiseRoutes,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("tsnet: %w", err)
+		}
+		s.hostname, s.AdvertiseRoutes = hostname, cfg.AdvertiseRoutes
+		return nil
+	}
+
+	oldTags := s.AdvertiseTags
+	s.AdvertiseTags = cfg.AdvertiseTags
+	authKey, err := s.resolveAuthKey()
+	if err == nil && authKey == "" {
+		err = errors.New("changing AdvertiseTags requires an auth key, client secret or ID token")
+	}
+	if err != nil {
+		s.AdvertiseTags = oldTags
+		return fmt.Errorf("tsnet: %w", err)
+	}
+	prefs := cur.AsStruct()
+	prefs.Hostname = hostname
+	prefs.AdvertiseTags = cfg.AdvertiseTags
+	prefs.AdvertiseRoutes = cfg.AdvertiseRoutes
+	if err := s.lb.Start(ipn.Options{AuthKey: authKey, UpdatePrefs: prefs}); err != nil {
+		s.AdvertiseTags = oldTags
+		return fmt.Errorf("tsnet: %w", err)
+	}
+	s.hostname, s.AdvertiseRoutes = hostname, cfg.AdvertiseRoutes
+	_, err = s.Up(ctx)
+	return err
+}
+
 // LogtailWriter returns an [io.Writer] that writes to Tailscale's logging service and will be only visible to Tailscale's
 // support team. Logs written there cannot be retrieved by the user. This method always returns a non-nil value.
 func (s *Server) LogtailWriter() io.Writer {
@@ -857,6 +992,7 @@ func (s *Server) start() (reterr error) {
 		HealthTracker: sys.HealthTracker.Get(),
 		ExtraRootCAs:  sys.ExtraRootCAs,
 		Metrics:       sys.UserMetricsRegistry(),
+		PathPolicy:    s.PathPolicy,
 	})
 	if err != nil {
 		return err
@@ -947,6 +1083,7 @@ func (s *Server) start() (reterr error) {
 	prefs.ControlURL = s.getControlURL()
 	prefs.RunWebClient = s.RunWebClient
 	prefs.AdvertiseTags = s.AdvertiseTags
+	prefs.AdvertiseRoutes = s.AdvertiseRoutes
 	authKey, err := s.resolveAuthKey()
 	if err != nil {
 		return fmt.Errorf("error resolving auth key: %w", err)
@@ -1297,7 +1434,16 @@ func (s *Server) ListenSSH(addr string) (net.Listener, error) {
 //
 // The network must be "udp", "udp4" or "udp6". The addr must be of the form
 // "ip:port" (or "[ip]:port") where ip is a valid IPv4 or IPv6 address
-// corresponding to "udp4" or "udp6" respectively. IP must be specified.
+// corresponding to "udp4" or "udp6" respectively.
+//
+// If the IP is omitted or unspecified, as in ":53", the PacketConn is bound
+// to the node's own Tailscale IP of the network's family: its IPv4 address
+// for "udp4" and its IPv6 address for "udp6". As a PacketConn has a single
+// local address, "udp" binds only the IPv4 address (or the IPv6 address if
+// the node has no IPv4 one); call ListenPacket again with "udp6" to also
+// listen on IPv6. The node's addresses aren't known until it's running, so
+// unlike with a concrete IP, that fails if s isn't running yet; call Up
+// first.
 //
 // If s has not been started yet, it will be started.
 func (s *Server) ListenPacket(network, addr string) (net.PacketConn, error) {
@@ -1306,7 +1452,12 @@ func (s *Server) ListenPacket(network, addr string) (net.PacketConn, error) {
 		return nil, err
 	}
 	if !ap.Addr().IsValid() {
-		return nil, fmt.Errorf("tsnet.ListenPacket(%q, %q): address must be a valid IP", network, addr)
+		ip, err := s.listenPacketIP(network)
+		if err != nil {
+			return nil, fmt.Errorf("tsnet.ListenPacket(%q, %q): %w", network, addr, err)
+		}
+		ap = netip.AddrPortFrom(ip, ap.Port())
+		addr = ap.String()
 	}
 	if network == "udp" {
 		if ap.Addr().Is4() {
@@ -1345,6 +1496,31 @@ func (s *Server) ListenPacket(network, addr string) (net.PacketConn, error) {
 	}, nil
 }
 
+// listenPacketIP returns the node's Tailscale IP that ListenPacket binds to
+// for network when no IP is given. It fails if s isn't running.
+func (s *Server) listenPacketIP(network string) (netip.Addr, error) {
+	if err := s.Start(); err != nil {
+		return netip.Addr{}, err
+	}
+	if st := s.lb.State(); st != ipn.Running {
+		return netip.Addr{}, fmt.Errorf("node is %v, not running; call Up first", st)
+	}
+	ip4, ip6 := s.TailscaleIPs()
+	var ip netip.Addr
+	switch network {
+	case "udp4":
+		ip = ip4
+	case "udp6":
+		ip = ip6
+	default:
+		ip = cmp.Or(ip4, ip6)
+	}
+	if !ip.IsValid() {
+		return netip.Addr{}, fmt.Errorf("node has no Tailscale IP for %s", network)
+	}
+	return ip, nil
+}
+
 // udpPacketConn wraps a net.PacketConn to unregister from s.listeners on Close.
 type udpPacketConn struct {
 	net.PacketConn
@@ -1380,7 +1556,7 @@ func (s *Server) ListenTLS(network, addr string) (net.Listener, error) {
 		return nil, err
 	}
 	return tls.NewListener(ln, &tls.Config{
-		GetCertificate: s.getCert,
+		GetCertificate: s.GetCertificate,
 	}), nil
 }
 
@@ -1402,16 +1578,43 @@ func (s *Server) RegisterFallbackTCPHandler(cb FallbackTCPHandler) func() {
 	}
 }
 
-// getCert is the GetCertificate function used by ListenTLS.
+// GetCertificate returns the TLS certificate for hi's ServerName, one of
+// the node's MagicDNS names, fetching it from Let's Encrypt if needed and
+// renewing it as it nears expiry. See [Server.OnCertRenew].
 //
-// It calls GetCertificate on the localClient, passing in the ClientHelloInfo.
-// For testing, if s.getCertForTesting is set, it will call that instead.
-func (s *Server) getCert(hi *tls.ClientHelloInfo) (*tls.Certificate, error) {
+// It's the right signature to use as the value of
+// [tls.Config.GetCertificate], and is what ListenTLS uses.
+// It will start the server if it has not been started yet.
+func (s *Server) GetCertificate(hi *tls.ClientHelloInfo) (*tls.Certificate, error) {
 	lc, err := s.LocalClient()
 	if err != nil {
 		return nil, err
 	}
-	return lc.GetCertificate(hi)
+	cert, err := lc.GetCertificate(hi)
+	if err != nil {
+		return nil, err
+	}
+	if s.OnCertRenew != nil && s.noteCert(hi.ServerName, cert) {
+		s.OnCertRenew(hi.ServerName, cert)
+	}
+	return cert, nil
+}
+
+// noteCert records cert as the one served for name and reports whether it
+// replaces a different one.
+func (s *Server) noteCert(name string, cert *tls.Certificate) (renewed bool) {
+	if len(cert.Certificate) == 0 {
+		return false
+	}
+	leaf := cert.Certificate[0]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.certLeaf[name]
+	if ok && bytes.Equal(old, leaf) {
+		return false
+	}
+	mak.Set(&s.certLeaf, name, leaf)
+	return ok
 }
 
 // FunnelOption is an option passed to ListenFunnel to configure the listener.
@@ -1495,7 +1698,7 @@ func (s *Server) ListenFunnel(network, addr string, opts ...FunnelOption) (net.L
 		}
 	}
 	if tlsConfig == nil {
-		tlsConfig = &tls.Config{GetCertificate: s.getCert}
+		tlsConfig = &tls.Config{GetCertificate: s.GetCertificate}
 	}
 
 	ctx := context.Background()
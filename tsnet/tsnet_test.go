@@ -877,6 +877,128 @@ func TestFunnel(t *testing.T) {
 	}
 }
 
+func TestSetServeConfig(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	controlURL, _ := startControl(t)
+	s1, s1ip, _ := startServer(t, ctx, controlURL, "s1")
+	s2, _, _ := startServer(t, ctx, controlURL, "s2")
+
+	hp := ipn.HostPort("s1.tail-scale.ts.net:80")
+	sc := &ipn.ServeConfig{
+		TCP: map[uint16]*ipn.TCPPortHandler{80: {HTTP: true}},
+		Web: map[ipn.HostPort]*ipn.WebServerConfig{
+			hp: {Handlers: map[string]*ipn.HTTPHandler{
+				"/": {Text: "hello"},
+			}},
+		},
+	}
+	if err := s1.SetServeConfig(ctx, sc); err != nil {
+		t.Fatal(err)
+	}
+
+	req := must.Get(http.NewRequestWithContext(ctx, "GET", "http://"+s1ip.String()+"/", nil))
+	req.Host = string(hp)
+	resp, err := s2.HTTPClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body := must.Get(io.ReadAll(resp.Body))
+	if resp.StatusCode != 200 || string(body) != "hello" {
+		t.Errorf("got %v %q; want 200 %q", resp.StatusCode, body, "hello")
+	}
+
+	if err := s1.SetServeConfig(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := s1.lb.ServeConfig(); got.Valid() && got.Web().Len() > 0 {
+		t.Errorf("serve config not cleared: %v", got)
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	controlURL, _ := startControl(t)
+	s1, s1ip, s1Key := startServer(t, ctx, controlURL, "s1")
+	s2, _, _ := startServer(t, ctx, controlURL, "s2")
+
+	route := netip.MustParsePrefix("10.1.2.0/24")
+	if err := s1.Reconfigure(ctx, Config{
+		Hostname:        "s1-renamed",
+		AdvertiseRoutes: []netip.Prefix{route},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	prefs := s1.lb.Prefs()
+	if got := prefs.Hostname(); got != "s1-renamed" {
+		t.Errorf("Hostname = %q; want s1-renamed", got)
+	}
+	if got := prefs.AdvertiseRoutes().AsSlice(); !slices.Equal(got, []netip.Prefix{route}) {
+		t.Errorf("AdvertiseRoutes = %v; want [%v]", got, route)
+	}
+	st1 := must.Get(must.Get(s1.LocalClient()).Status(ctx))
+	if got := st1.Self.PublicKey; got != s1Key {
+		t.Errorf("node key changed from %v to %v", s1Key, got)
+	}
+
+	// The peer should see the new hostname, at the same IP.
+	lc2 := must.Get(s2.LocalClient())
+	for {
+		st, err := lc2.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found bool
+		for _, ps := range st.Peer {
+			if ps.HostName == "s1-renamed" && slices.Contains(ps.TailscaleIPs, s1ip) {
+				found = true
+			}
+		}
+		if found {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("peer never saw the new hostname: %v", ctx.Err())
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	// An empty Hostname and nil AdvertiseRoutes keep the current ones.
+	if err := s1.Reconfigure(ctx, Config{}); err != nil {
+		t.Fatal(err)
+	}
+	prefs = s1.lb.Prefs()
+	if got := prefs.Hostname(); got != "s1-renamed" {
+		t.Errorf("Hostname after empty Reconfigure = %q; want s1-renamed", got)
+	}
+	if got := prefs.AdvertiseRoutes().AsSlice(); !slices.Equal(got, []netip.Prefix{route}) {
+		t.Errorf("AdvertiseRoutes after empty Reconfigure = %v; want [%v]", got, route)
+	}
+
+	// A non-nil empty AdvertiseRoutes stops advertising them.
+	if err := s1.Reconfigure(ctx, Config{AdvertiseRoutes: []netip.Prefix{}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := s1.lb.Prefs().AdvertiseRoutes().Len(); got != 0 {
+		t.Errorf("got %d AdvertiseRoutes after clearing them; want 0", got)
+	}
+
+	// Changing tags needs a way to get an auth key, which s1 lacks, and a
+	// failed Reconfigure leaves the hostname as it was.
+	err := s1.Reconfigure(ctx, Config{Hostname: "s1-tagged", AdvertiseTags: []string{"tag:foo"}})
+	if err == nil {
+		t.Fatal("Reconfigure with new tags and no auth key succeeded")
+	}
+	if got := s1.lb.Prefs().Hostname(); got != "s1-renamed" {
+		t.Errorf("Hostname after failed Reconfigure = %q; want s1-renamed", got)
+	}
+}
+
 // TestFunnelClose ensures that the listener returned by ListenFunnel cleans up
 // after itself when closed. Specifically, changes made to the serve config
 // should be cleared.
@@ -2902,6 +3024,51 @@ func TestListenPacket(t *testing.T) {
 		t.Run("IPv4", func(t *testing.T) { testListenPacket(t, lt, lt.s2ip4) })
 		t.Run("IPv6", func(t *testing.T) { testListenPacket(t, lt, lt.s2ip6) })
 	})
+
+	t.Run("NoIP", func(t *testing.T) {
+		lt := setupTwoClientTest(t, false)
+		for _, tt := range []struct {
+			network string
+			want    netip.Addr
+		}{
+			{"udp", lt.s2ip4},
+			{"udp4", lt.s2ip4},
+			{"udp6", lt.s2ip6},
+		} {
+			pc, err := lt.s2.ListenPacket(tt.network, ":0")
+			if err != nil {
+				t.Fatalf("ListenPacket(%q, \":0\"): %v", tt.network, err)
+			}
+			got := pc.LocalAddr().(*net.UDPAddr).AddrPort().Addr().Unmap()
+			pc.Close()
+			if got != tt.want {
+				t.Errorf("ListenPacket(%q, \":0\") bound to %v; want %v", tt.network, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("NoIPNotRunning", func(t *testing.T) {
+		// Nothing listens at the control URL, so s never gets to Running.
+		s := &Server{
+			Dir:        t.TempDir(),
+			ControlURL: "http://127.0.0.1:1",
+			Hostname:   "not-running",
+			Store:      new(mem.Store),
+			Ephemeral:  true,
+		}
+		if *verboseNodes {
+			s.Logf = t.Logf
+		}
+		defer s.Close()
+		pc, err := s.ListenPacket("udp", ":0")
+		if err == nil {
+			pc.Close()
+			t.Fatal("ListenPacket succeeded on a node that isn't running")
+		}
+		if !strings.Contains(err.Error(), "call Up first") {
+			t.Errorf("ListenPacket error = %v; want one saying to call Up first", err)
+		}
+	})
 }
 
 // TestListenTCP tests TCP listeners with concrete addresses in both netstack
@@ -3905,3 +4072,25 @@ func TestListenMultipleEphemeralPorts(t *testing.T) {
 		testMultipleEphemeral(t, lt)
 	})
 }
+
+func TestNoteCert(t *testing.T) {
+	s := new(Server)
+	cert := func(der string) *tls.Certificate {
+		return &tls.Certificate{Certificate: [][]byte{[]byte(der)}}
+	}
+	for i, tt := range []struct {
+		name, der string
+		want      bool
+	}{
+		{"a.ts.net", "one", false}, // first cert isn't a renewal
+		{"a.ts.net", "one", false},
+		{"b.ts.net", "two", false},
+		{"a.ts.net", "three", true},
+		{"a.ts.net", "three", false},
+		{"b.ts.net", "two", false},
+	} {
+		if got := s.noteCert(tt.name, cert(tt.der)); got != tt.want {
+			t.Errorf("%d: noteCert(%q, %q) = %v; want %v", i, tt.name, tt.der, got, tt.want)
+		}
+	}
+}
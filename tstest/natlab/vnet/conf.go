@@ -390,7 +390,9 @@ type Network struct {
 	svcs set.Set[NetworkService]
 
 	latency  time.Duration // latency applied to interface writes
+	jitter   time.Duration // random extra latency, up to this, per write
 	lossRate float64       // chance of packet loss (0.0 to 1.0)
+	mtu      int           // if non-zero, larger IP packets are dropped
 
 	// ...
 	err error // carried error
@@ -401,6 +403,20 @@ func (n *Network) SetLatency(d time.Duration) {
 	n.latency = d
 }
 
+// SetJitter sets the simulated jitter for this network: each packet is
+// delayed by a random extra duration from 0 up to d, on top of the latency
+// from SetLatency. As the delays are independent, packets get reordered.
+func (n *Network) SetJitter(d time.Duration) {
+	n.jitter = d
+}
+
+// SetMTU sets the largest IP packet, in bytes, that this network carries.
+// Larger packets are silently dropped, as by a path MTU blackhole. Zero
+// means no limit.
+func (n *Network) SetMTU(mtu int) {
+	n.mtu = mtu
+}
+
 // SetPacketLoss sets the packet loss rate for this network 0.0 (no loss) to 1.0 (total loss).
 func (n *Network) SetPacketLoss(rate float64) {
 	if rate < 0 {
@@ -509,7 +525,9 @@ func (s *Server) initFromConfig(c *Config) error {
 			lanIP4:     conf.lanIP4,
 			breakWAN4:  conf.breakWAN4,
 			latency:    conf.latency,
+			jitter:     conf.jitter,
 			lossRate:   conf.lossRate,
+			mtu:        conf.mtu,
 			nodesByIP4: map[netip.Addr]*node{},
 			nodesByMAC: map[MAC]*node{},
 			logf:       logger.WithPrefix(s.logf, fmt.Sprintf("[net-%v] ", conf.mac)),
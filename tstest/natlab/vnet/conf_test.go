@@ -27,6 +27,8 @@ func TestConfig(t *testing.T) {
 				n1 := c.AddNetwork("2.1.1.1", "192.168.1.1/24", EasyNAT, NATPMP)
 				n1.SetLatency(time.Second)
 				n1.SetPacketLoss(0.1)
+				n1.SetJitter(50 * time.Millisecond)
+				n1.SetMTU(1280)
 				c.AddNode(n1)
 				c.AddNode(c.AddNetwork("2.2.2.2", "10.2.0.1/16", HardNAT))
 			},
@@ -685,7 +685,9 @@ type network struct {
 	breakWAN4        bool                 // break WAN IPv4 connectivity
 	blackholeControl bool                 // blackhole control connectivity
 	latency          time.Duration        // latency applied to interface writes
+	jitter           time.Duration        // random extra latency, up to this, per write
 	lossRate         float64              // probability of dropping a packet (0.0 to 1.0)
+	mtu              int                  // if non-zero, larger IP packets are dropped
 	nodesByIP4       map[netip.Addr]*node // by LAN IPv4
 	nodesByMAC       map[MAC]*node
 	logf             func(format string, args ...any)
@@ -1547,13 +1549,21 @@ func (n *network) conditionedWrite(nw networkWriter, packet []byte) {
 		// packet lost
 		return
 	}
-	if n.latency > 0 {
+	if n.mtu > 0 && len(packet)-14 > n.mtu { // 14 byte Ethernet header
+		// too big; blackholed rather than answered with ICMP
+		return
+	}
+	delay := n.latency
+	if n.jitter > 0 {
+		delay += rand.N(n.jitter)
+	}
+	if delay > 0 {
 		// copy the packet as there's no guarantee packet is owned long enough.
 		// TODO(raggi): this could be optimized substantially if necessary,
 		// a pool of buffers and a cheaper delay mechanism are both obvious improvements.
 		var pkt = make([]byte, len(packet))
 		copy(pkt, packet)
-		time.AfterFunc(n.latency, func() { nw.write(pkt) })
+		time.AfterFunc(delay, func() { nw.write(pkt) })
 	} else {
 		nw.write(packet)
 	}
@@ -696,3 +696,16 @@ func awaitCond(t testing.TB, timeout time.Duration, cond func() error) {
 		time.Sleep(10 * time.Millisecond)
 	}
 }
+
+func TestConditionedWriteMTU(t *testing.T) {
+	var got []int
+	nw := networkWriter{writer: func(_ vmClient, ethFrame []byte, _ int) {
+		got = append(got, len(ethFrame))
+	}}
+	n := &network{mtu: 1280}
+	n.conditionedWrite(nw, make([]byte, 14+1280))
+	n.conditionedWrite(nw, make([]byte, 14+1281))
+	if len(got) != 1 || got[0] != 14+1280 {
+		t.Errorf("wrote frames of sizes %v; want just %v", got, 14+1280)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package osdiag
+
+import (
+	"net"
+	"os"
+	"slices"
+
+	"tailscale.com/hostinfo"
+	"tailscale.com/net/netmon"
+	"tailscale.com/types/lazy"
+	"tailscale.com/types/opt"
+)
+
+// EnvironmentFacts returns the facts about the host environment from
+// [hostinfo.GetEnvironmentFacts], plus those that hostinfo leaves out
+// because finding them out takes raw system calls or OS APIs that hostinfo
+// doesn't otherwise depend on.
+func EnvironmentFacts() hostinfo.EnvironmentFacts {
+	f := hostinfo.GetEnvironmentFacts()
+	f.HasIOUring = HasIOUring()
+	f.EBPFSockops = HasEBPFSockops()
+	f.Antivirus = AntivirusDetected()
+	f.DefaultInterfaceMTU, _ = DefaultInterfaceMTU()
+	if v, ok := CodeSignatureValid(); ok {
+		f.CodeSignatureValid.Set(v)
+	}
+	return f
+}
+
+// non-nil on some platforms
+var (
+	hasIOUring          func() bool
+	hasEBPFSockops      func() bool
+	verifyCodeSignature func(exe string) (valid, ok bool)
+	antivirusProducts   func() []string
+)
+
+var hasIOUringCache lazy.SyncValue[bool]
+
+// HasIOUring reports whether the kernel supports io_uring and the current
+// process is permitted to use it.
+//
+// On Linux, io_uring requires kernel 5.1 or later, but it can also be turned
+// off by the kernel.io_uring_disabled sysctl (Linux 6.6+) or blocked by a
+// seccomp filter (as Docker's default profile does). Rather than inspecting
+// the kernel version and each of those, HasIOUring probes for it directly by
+// creating, and immediately closing, a minimal io_uring instance. The result
+// is cached.
+//
+// It always reports false on other platforms.
+func HasIOUring() bool {
+	if hasIOUring == nil {
+		return false
+	}
+	return hasIOUringCache.Get(hasIOUring)
+}
+
+// DefaultInterfaceMTU returns the MTU of the network interface that carries
+// the host's default route, as found by [netmon.DefaultRouteInterface], and
+// whether it could be determined.
+func DefaultInterfaceMTU() (mtu int, ok bool) {
+	name, err := netmon.DefaultRouteInterface()
+	if err != nil || name == "" {
+		return 0, false
+	}
+	ifc, err := net.InterfaceByName(name)
+	if err != nil {
+		return 0, false
+	}
+	return ifc.MTU, true
+}
+
+var codeSignatureCache lazy.SyncValue[opt.Bool]
+
+// CodeSignatureValid reports whether the running executable carries a valid
+// code signature, and whether that could be checked. A binary that's
+// normally signed but fails this check may have been tampered with. It uses:
+//
+//   - on macOS, "codesign --verify --strict", which checks the signature
+//     of the executable (and of its enclosing app bundle, if any) with the
+//     Security framework's code signing APIs
+//   - on Windows, WinVerifyTrust, via [authenticode.Verify], which checks
+//     the executable's embedded or catalog Authenticode signature and its
+//     certificate chain
+//
+// It verifies only that a signature is present and valid, not who produced
+// it: a binary validly signed by anyone passes. Unsigned binaries, such as
+// local builds, report (false, true). Platforms without code signing report
+// ok=false. The result is cached.
+//
+// [authenticode.Verify]: https://pkg.go.dev/tailscale.com/util/winutil/authenticode#Verify
+func CodeSignatureValid() (valid, ok bool) {
+	if verifyCodeSignature == nil {
+		return false, false
+	}
+	v := codeSignatureCache.Get(func() opt.Bool {
+		var b opt.Bool
+		exe, err := os.Executable()
+		if err != nil {
+			return b
+		}
+		if valid, ok := verifyCodeSignature(exe); ok {
+			b.Set(valid)
+		}
+		return b
+	})
+	return v.Get()
+}
+
+var hasEBPFSockopsCache lazy.SyncValue[bool]
+
+// HasEBPFSockops reports whether the process can load eBPF programs of the
+// socket operations (BPF_PROG_TYPE_SOCK_OPS) type, which optional datapath
+// performance features attach to cgroups to act on TCP connection events.
+//
+// On Linux, that requires kernel 4.13 or later and the CAP_BPF or
+// CAP_SYS_ADMIN capability; unprivileged BPF doesn't allow the type. After
+// checking the kernel version, it probes by loading, and immediately
+// closing, a minimal program of the type, which also detects BPF being
+// compiled out or blocked by seccomp. The result is cached.
+//
+// It always reports false on other platforms.
+func HasEBPFSockops() bool {
+	if hasEBPFSockops == nil {
+		return false
+	}
+	return hasEBPFSockopsCache.Get(hasEBPFSockops)
+}
+
+// AntivirusDetected returns the names of the enabled antivirus products on
+// the host, sorted, or an empty slice if there are none. On Windows,
+// antivirus and endpoint security products commonly filter network traffic
+// in ways that break Tailscale's, by inspecting or blocking its UDP packets
+// or its TUN adapter.
+//
+// On Windows, it lists the antivirus products registered with the Windows
+// Security Center and reports those whose state is on. Microsoft Defender
+// Antivirus is among them when it's on. Windows Server has no Security
+// Center, so nothing is found there, and other platforms always report an
+// empty slice.
+func AntivirusDetected() []string {
+	if antivirusProducts == nil {
+		return []string{}
+	}
+	names := append([]string{}, antivirusProducts()...)
+	slices.Sort(names)
+	return slices.Compact(names)
+}
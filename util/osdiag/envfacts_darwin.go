@@ -0,0 +1,29 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package osdiag
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"time"
+)
+
+func init() {
+	verifyCodeSignature = func(exe string) (valid, ok bool) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err := exec.CommandContext(ctx, "/usr/bin/codesign", "--verify", "--strict", exe).Run()
+		var ee *exec.ExitError
+		switch {
+		case err == nil:
+			return true, true
+		case errors.As(err, &ee):
+			// codesign exits non-zero for unsigned and invalidly
+			// signed code alike.
+			return false, true
+		}
+		return false, false
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package osdiag
+
+import (
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func init() {
+	hasIOUring = hasIOUringLinux
+	hasEBPFSockops = hasEBPFSockopsLinux
+}
+
+func hasIOUringLinux() bool {
+	// params is a zeroed struct io_uring_params (120 bytes), which asks for
+	// a ring with default flags. The kernel fills in the rest.
+	var params [120]byte
+	fd, _, errno := unix.Syscall(unix.SYS_IO_URING_SETUP, 1, uintptr(unsafe.Pointer(&params[0])), 0)
+	if errno != 0 {
+		// ENOSYS on kernels before 5.1, EPERM when disabled by
+		// sysctl or seccomp.
+		return false
+	}
+	unix.Close(int(fd))
+	return true
+}
+
+func hasEBPFSockopsLinux() bool {
+	var un unix.Utsname
+	if unix.Uname(&un) != nil || !kernelAtLeast(unix.ByteSliceToString(un.Release[:]), 4, 13) {
+		return false
+	}
+	// The program is "r0 = 1; exit", as struct bpf_insn values: an 8-bit
+	// opcode, the destination and source registers, a 16-bit offset and a
+	// 32-bit immediate.
+	insns := [2]uint64{
+		0xb7 | 1<<32, // BPF_ALU64 | BPF_MOV | BPF_K, imm 1
+		0x95,         // BPF_JMP | BPF_EXIT
+	}
+	license := []byte("GPL\x00")
+	// attr is the prefix of union bpf_attr used by BPF_PROG_LOAD: the
+	// program type, instruction count and pointers to the instructions
+	// and license. The kernel treats the omitted fields as zero.
+	var attr struct {
+		progType uint32
+		insnCnt  uint32
+		insns    uint64
+		license  uint64
+	}
+	attr.progType = unix.BPF_PROG_TYPE_SOCK_OPS
+	attr.insnCnt = uint32(len(insns))
+	attr.insns = uint64(uintptr(unsafe.Pointer(&insns[0])))
+	attr.license = uint64(uintptr(unsafe.Pointer(&license[0])))
+	fd, _, errno := unix.Syscall(unix.SYS_BPF, unix.BPF_PROG_LOAD, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr))
+	if errno != 0 {
+		// EPERM without the needed capability or when blocked by
+		// seccomp, ENOSYS without BPF and EINVAL if the program type is
+		// unknown.
+		return false
+	}
+	unix.Close(int(fd))
+	return true
+}
+
+// kernelAtLeast reports whether the Linux kernel release string release
+// (such as "6.8.0-45-generic") is at least version major.minor.
+func kernelAtLeast(release string, major, minor int) bool {
+	maj, rest, _ := strings.Cut(release, ".")
+	min, _, _ := strings.Cut(rest, ".")
+	if i := strings.IndexFunc(min, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		min = min[:i]
+	}
+	gotMaj, err1 := strconv.Atoi(maj)
+	gotMin, err2 := strconv.Atoi(min)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return gotMaj > major || gotMaj == major && gotMin >= minor
+}
@@ -0,0 +1,28 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package osdiag
+
+import "testing"
+
+func TestKernelAtLeast(t *testing.T) {
+	tests := []struct {
+		release      string
+		major, minor int
+		want         bool
+	}{
+		{"6.8.0-45-generic", 4, 13, true},
+		{"4.13.0", 4, 13, true},
+		{"4.9.337", 4, 13, false},
+		{"3.18.140-android", 4, 13, false},
+		{"5.4", 4, 13, true},
+		{"4.14-rc1", 4, 14, true},
+		{"", 4, 13, false},
+		{"bogus", 4, 13, false},
+	}
+	for _, tt := range tests {
+		if got := kernelAtLeast(tt.release, tt.major, tt.minor); got != tt.want {
+			t.Errorf("kernelAtLeast(%q, %d, %d) = %v; want %v", tt.release, tt.major, tt.minor, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package osdiag
+
+import (
+	"errors"
+
+	"github.com/dblohm7/wingoes/com"
+	"tailscale.com/util/osdiag/internal/wsc"
+	"tailscale.com/util/winutil/authenticode"
+)
+
+func init() {
+	antivirusProducts = antivirusProductsWindows
+	verifyCodeSignature = func(exe string) (valid, ok bool) {
+		// An empty expected subject makes Verify report any valid
+		// signature as ErrUnexpectedCertSubject, which is fine here:
+		// only validity is of interest.
+		err := authenticode.Verify(exe, "")
+		return err == nil || errors.Is(err, authenticode.ErrUnexpectedCertSubject), true
+	}
+}
+
+func antivirusProductsWindows() []string {
+	productList, err := com.CreateInstance[wsc.WSCProductList](wsc.CLSID_WSCProductList)
+	if err != nil {
+		return nil
+	}
+	if err := productList.Initialize(wsc.WSC_SECURITY_PROVIDER_ANTIVIRUS); err != nil {
+		return nil
+	}
+	n, err := productList.GetCount()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for i := range min(n, maxProvCount) {
+		product, err := productList.GetItem(uint32(i))
+		if err != nil {
+			continue
+		}
+		state, err := product.GetProductState()
+		if err != nil || state != wsc.WSC_SECURITY_PRODUCT_STATE_ON {
+			continue
+		}
+		if name, err := product.GetProductName(); err == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
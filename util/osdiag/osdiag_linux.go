@@ -0,0 +1,18 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package osdiag
+
+import "tailscale.com/hostinfo"
+
+const (
+	supportInfoKeyInterfaces = "interfaces"
+	supportInfoKeySysctls    = "sysctls"
+)
+
+func supportInfo(LogSupportInfoReason) map[string]any {
+	return map[string]any{
+		supportInfoKeyInterfaces: hostinfo.InterfaceSummary(),
+		supportInfoKeySysctls:    hostinfo.NetworkSysctls(),
+	}
+}
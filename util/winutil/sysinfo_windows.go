@@ -0,0 +1,97 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package winutil
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// MemoryStatus is the Win32 MEMORYSTATUSEX struct, as returned by
+// [GlobalMemoryStatus].
+type MemoryStatus struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+// GlobalMemoryStatus returns the system's current usage of physical and
+// virtual memory.
+func GlobalMemoryStatus() (*MemoryStatus, error) {
+	ms := &MemoryStatus{Length: uint32(unsafe.Sizeof(MemoryStatus{}))}
+	if err := globalMemoryStatusEx(ms); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// SystemPowerStatus is the Win32 SYSTEM_POWER_STATUS struct, as returned by
+// [GetSystemPowerStatus].
+type SystemPowerStatus struct {
+	ACLineStatus        byte // 0 offline, 1 online, 255 unknown
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// GetSystemPowerStatus returns whether the system is running on AC or
+// battery power, and the battery's charge.
+func GetSystemPowerStatus() (*SystemPowerStatus, error) {
+	st := new(SystemPowerStatus)
+	if err := getSystemPowerStatus(st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// GetAdaptersAddresses returns the system's network adapters, with their
+// addresses of the given family (such as windows.AF_INET or
+// windows.AF_UNSPEC), as a list linked by their Next fields. flags are the
+// windows.GAA_FLAG_* flags for the GetAdaptersAddresses Win32 function. It
+// returns nil if there are none.
+func GetAdaptersAddresses(family, flags uint32) (*windows.IpAdapterAddresses, error) {
+	size := uint32(15 << 10) // Microsoft's recommended initial size
+	for {
+		buf := make([]byte, size)
+		aa := (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0]))
+		err := windows.GetAdaptersAddresses(family, flags, 0, aa, &size)
+		switch {
+		case err == nil:
+			return aa, nil
+		case err == windows.ERROR_NO_DATA:
+			return nil, nil
+		case err != windows.ERROR_BUFFER_OVERFLOW || size <= uint32(len(buf)):
+			return nil, err
+		}
+	}
+}
+
+// ProcessNames returns the executable file names, such as "explorer.exe", of
+// the processes running on the system.
+func ProcessNames() ([]string, error) {
+	snap, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(snap)
+
+	var names []string
+	pe := windows.ProcessEntry32{Size: uint32(unsafe.Sizeof(windows.ProcessEntry32{}))}
+	for err = windows.Process32First(snap, &pe); err == nil; err = windows.Process32Next(snap, &pe) {
+		names = append(names, windows.UTF16ToString(pe.ExeFile[:]))
+	}
+	if err != windows.ERROR_NO_MORE_FILES {
+		return nil, err
+	}
+	return names, nil
+}
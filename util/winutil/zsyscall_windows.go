@@ -48,6 +48,8 @@ func errnoErr(e syscall.Errno) error {
 
 	procQueryServiceConfig2W             = modadvapi32.NewProc("QueryServiceConfig2W")
 	procGetApplicationRestartSettings    = modkernel32.NewProc("GetApplicationRestartSettings")
+	procGetSystemPowerStatus             = modkernel32.NewProc("GetSystemPowerStatus")
+	procGlobalMemoryStatusEx             = modkernel32.NewProc("GlobalMemoryStatusEx")
 	procRegisterApplicationRestart       = modkernel32.NewProc("RegisterApplicationRestart")
 	procDsGetDcNameW                     = modnetapi32.NewProc("DsGetDcNameW")
 	procNetValidateName                  = modnetapi32.NewProc("NetValidateName")
@@ -75,6 +77,22 @@ func getApplicationRestartSettings(process windows.Handle, commandLine *uint16,
 	return
 }
 
+func getSystemPowerStatus(status *SystemPowerStatus) (err error) {
+	r1, _, e1 := syscall.SyscallN(procGetSystemPowerStatus.Addr(), uintptr(unsafe.Pointer(status)))
+	if int32(r1) == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
+func globalMemoryStatusEx(memStatus *MemoryStatus) (err error) {
+	r1, _, e1 := syscall.SyscallN(procGlobalMemoryStatusEx.Addr(), uintptr(unsafe.Pointer(memStatus)))
+	if int32(r1) == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func registerApplicationRestart(cmdLineExclExeName *uint16, flags uint32) (ret wingoes.HRESULT) {
 	r0, _, _ := syscall.SyscallN(procRegisterApplicationRestart.Addr(), uintptr(unsafe.Pointer(cmdLineExclExeName)), uintptr(flags))
 	ret = wingoes.HRESULT(r0)
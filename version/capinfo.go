@@ -0,0 +1,71 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package version
+
+import (
+	"slices"
+
+	"tailscale.com/tailcfg"
+)
+
+// capRelease maps capability versions to the release that introduced them,
+// where the history on [tailcfg.CurrentCapabilityVersion] records it.
+var capRelease = map[int]string{
+	26: "1.20.0",
+}
+
+// capGates maps the names of protocol features to the capability version
+// that introduced them: peers and control servers only use a feature with a
+// node at that capability version or later. The versions are from the
+// history on [tailcfg.CurrentCapabilityVersion]; add a feature here when
+// adding one there that tooling should be able to name.
+var capGates = map[string]int{
+	"ssh-policy":         27,
+	"noise":              28,
+	"tailnet-lock":       64,
+	"peer-cap-map":       67,
+	"node-cap-map":       74,
+	"seamless-key-renew": 84,
+	"stateful-filtering": 93,
+	"filter-src-caps":    109,
+	"home-derp":          111,
+	"display-messages":   117,
+	"peer-relay":         120,
+	"cache-netmap":       135,
+	"c2n-localapi-proxy": 142,
+}
+
+// CapInfo describes m's capability version (m.Cap): the version itself,
+// the release that introduced it, and the sorted names of the features
+// gated at or below it, which a node at that capability version supports.
+//
+// introducedIn and features come from tables in this package that record
+// the releases and features named in the history on
+// [tailcfg.CurrentCapabilityVersion], which remains authoritative.
+// introducedIn is empty if the history doesn't say which release introduced
+// the capability version, and features only includes the features in the
+// table, not every change the history lists.
+func (m Meta) CapInfo() (version int, introducedIn string, features []string) {
+	for name, capVer := range capGates {
+		if capVer <= m.Cap {
+			features = append(features, name)
+		}
+	}
+	slices.Sort(features)
+	return m.Cap, capRelease[m.Cap], features
+}
+
+// CapCompatible reports whether two nodes, at capability versions local and
+// remote (such as [Meta.Cap] and a peer's reported version), can both speak
+// a protocol feature that requires capability version required: that is,
+// whether both are at least required.
+func CapCompatible(local, remote int, required tailcfg.CapabilityVersion) bool {
+	return local >= int(required) && remote >= int(required)
+}
+
+// CapAtLeast reports whether the current build's capability version,
+// [tailcfg.CurrentCapabilityVersion], is at least required.
+func CapAtLeast(required tailcfg.CapabilityVersion) bool {
+	return tailcfg.CurrentCapabilityVersion >= required
+}
@@ -4,6 +4,7 @@
 package version
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -59,6 +60,272 @@ func AtLeast(version string, minimum string) bool {
 	}
 }
 
+// CurrentAtLeast reports whether the running build, as identified by
+// [Short], is at least version minimum (a "major.minor.patch" version or a
+// prefix of one, as accepted by [AtLeast]). It's meant for gating code on
+// the release that introduced a feature, the numeric comparison being that
+// of [Compare].
+//
+// Dev builds count as the release they precede: a build whose Short is
+// "1.62.0-dev20240101" satisfies CurrentAtLeast("1.62.0"), as features
+// for 1.62.0 land on the development branch before the release. If either
+// version can't be parsed, it reports true for dev builds, so that
+// features aren't accidentally disabled during local development, and
+// false otherwise.
+//
+// (It isn't named AtLeast, as that name compares two arbitrary versions.)
+func CurrentAtLeast(minimum string) bool {
+	return shortAtLeast(Short(), minimum)
+}
+
+// shortAtLeast is CurrentAtLeast for the short version short.
+func shortAtLeast(short, minimum string) bool {
+	release, _, isDev := strings.Cut(short, "-dev")
+	c, ok := Compare(release, minimum)
+	if !ok {
+		return isDev
+	}
+	return c >= 0
+}
+
+// Constraint is a minimum version requirement, as taken by a command-line
+// flag such as --min-version, checked against the running build with
+// [Constraint.Satisfied]. It implements [flag.Value]:
+//
+//	var minVersion version.Constraint
+//	flag.Var(&minVersion, "min-version", "minimum Tailscale version, as major.minor.patch")
+//
+// The zero Constraint has no minimum and is always satisfied.
+type Constraint struct {
+	minimum string // "major.minor.patch", or empty
+}
+
+// Set sets the minimum version to s, which must be of the form
+// "major.minor.patch", such as "1.62.0".
+func (c *Constraint) Set(s string) error {
+	if !isMajorMinorPatch(s) {
+		return fmt.Errorf("invalid version %q, want major.minor.patch such as 1.62.0", s)
+	}
+	c.minimum = s
+	return nil
+}
+
+// String returns the minimum version, or "" if there's none.
+func (c *Constraint) String() string {
+	if c == nil {
+		return ""
+	}
+	return c.minimum
+}
+
+// Satisfied reports whether the running build is at least the minimum
+// version, as reported by [CurrentAtLeast]; in particular, dev builds
+// satisfy the minimum of the release they precede.
+func (c Constraint) Satisfied() bool {
+	return c.satisfiedBy(Short())
+}
+
+// satisfiedBy is Satisfied for the short version short.
+func (c Constraint) satisfiedBy(short string) bool {
+	if c.minimum == "" {
+		return true
+	}
+	return shortAtLeast(short, c.minimum)
+}
+
+// Compare compares the Tailscale version strings a and b (such as "1.60.0",
+// "1.61.0-dev20240101" or a long version like "1.60.0-t1234abcde-g5678"),
+// returning -1 if a is older than b, 0 if they're the same version and +1 if
+// a is newer.
+//
+// The major, minor and patch components are compared numerically, so
+// "1.60.0" is newer than "1.9.0"; missing components are zero. Any suffix,
+// such as a -dev datestamp or commit hashes, is ignored, except that a
+// release is newer than the -dev build of the same version number. OSS
+// build datestamps (date.YYYYMMDD) are compared with each other.
+//
+// ok reports whether a and b were both valid and comparable. If not, cmp
+// orders invalid versions before valid ones, and otherwise falls back to
+// comparing the strings lexically, so that sorting with it is still
+// deterministic.
+func Compare(a, b string) (cmp int, ok bool) {
+	pa, aok := parse(a)
+	pb, bok := parse(b)
+	switch {
+	case !aok && !bok:
+		return strings.Compare(a, b), false
+	case !aok:
+		return -1, false
+	case !bok:
+		return +1, false
+	case (pa.Datestamp != 0) != (pb.Datestamp != 0):
+		// OSS version vs. Tailscale version.
+		return strings.Compare(a, b), false
+	}
+	if c := compareInts(pa.Datestamp, pb.Datestamp); c != 0 {
+		return c, true
+	}
+	if c := compareInts(pa.Major, pb.Major); c != 0 {
+		return c, true
+	}
+	if c := compareInts(pa.Minor, pb.Minor); c != 0 {
+		return c, true
+	}
+	if c := compareInts(pa.Patch, pb.Patch); c != 0 {
+		return c, true
+	}
+	switch aDev, bDev := strings.Contains(a, "-dev"), strings.Contains(b, "-dev"); {
+	case aDev && !bDev:
+		return -1, true
+	case !aDev && bDev:
+		return +1, true
+	}
+	return 0, true
+}
+
+// Skew returns how many minor versions the client (e.g. the tailscale CLI),
+// whose short version is clientShort, is ahead of the daemon (tailscaled),
+// whose short version is daemonShort; it's negative if the client is older.
+// warn reports whether they're more than one minor version apart, which is
+// outside the range of client/daemon combinations that's supported, or
+// have different major versions.
+//
+// Only the numeric major and minor versions are compared, so a -dev build
+// counts as the release it precedes. If either version can't be parsed, or
+// both are OSS datestamps, Skew returns (0, false).
+func Skew(clientShort, daemonShort string) (skew int, warn bool) {
+	c, ok := parse(clientShort)
+	if !ok || c.Datestamp != 0 {
+		return 0, false
+	}
+	d, ok := parse(daemonShort)
+	if !ok || d.Datestamp != 0 {
+		return 0, false
+	}
+	skew = c.Minor - d.Minor
+	return skew, c.Major != d.Major || skew > 1 || skew < -1
+}
+
+// UpdateDirection reports whether updating from version from to version to
+// (such as a Short version and that of an update) is an upgrade (dir is +1),
+// a downgrade (-1) or neither (0), and whether it moves between the stable
+// and unstable tracks, which have even and odd minor versions respectively.
+//
+// Only the numeric major, minor and patch versions are compared, so any
+// -dev suffix is ignored. If either version can't be parsed with
+// [ParseShort], it returns (0, false).
+func UpdateDirection(from, to string) (dir int, crossesTrack bool) {
+	f, err := ParseShort(from)
+	if err != nil {
+		return 0, false
+	}
+	t, err := ParseShort(to)
+	if err != nil {
+		return 0, false
+	}
+	return t.Compare(f), f.IsUnstable() != t.IsUnstable()
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return +1
+	}
+	return 0
+}
+
+// Parsed is a Tailscale version broken into its parts, such as that of a
+// peer's version or of an update, as parsed by [ParseShort].
+type Parsed struct {
+	Major, Minor, Patch int
+
+	// ExtraCommits is the number of commits since the release was tagged,
+	// for untagged release branch builds, such as the 12 in
+	// "1.62.3-12-t1234abcde".
+	ExtraCommits int
+
+	// Dev is the development build suffix, without its leading "-", such as
+	// "dev20240101" or a bare "dev", or empty for releases.
+	Dev string
+
+	// Commit is the abbreviated tailscale.com git commit the build was made
+	// at, and ExtraCommit that of the supplemental repository it was built
+	// from, if any. They're only present in long versions, like
+	// "1.62.3-t1234abcde-g5678fedcb", whose hashes are prefixed by "t" and
+	// "g" respectively. See [Long].
+	Commit, ExtraCommit string
+
+	// Dirty is whether the git checkout the build was made from had
+	// uncommitted changes, as marked by a "-dirty" suffix.
+	Dirty bool
+}
+
+// ParseShort parses the Tailscale version s, short or long (see [Short] and
+// [Long]), such as "1.62.3", "1.63.0-dev20240101" or
+// "1.62.3-t1234abcde-g5678fedcb". A prefix of a full version, such as
+// "1.62", is also accepted, with the missing numbers being zero. Parts of
+// the suffix it doesn't recognize are ignored. OSS build datestamps
+// (date.YYYYMMDD) aren't accepted.
+//
+// To check a version string against a minimum, use [AtLeast].
+func ParseShort(s string) (Parsed, error) {
+	p, ok := parse(s)
+	if !ok || p.Datestamp != 0 {
+		return Parsed{}, fmt.Errorf("invalid Tailscale version %q", s)
+	}
+	v := Parsed{
+		Major:        p.Major,
+		Minor:        p.Minor,
+		Patch:        p.Patch,
+		ExtraCommits: p.ExtraCommits,
+	}
+	_, suffix, _ := strings.Cut(s, "-")
+	for f := range strings.SplitSeq(suffix, "-") {
+		switch {
+		case f == "dev" || strings.HasPrefix(f, "dev") && allDigits(f[len("dev"):]):
+			v.Dev = f
+		case len(f) > 1 && f[0] == 't' && isLowerHex(f[1:]):
+			v.Commit = f[1:]
+		case len(f) > 1 && f[0] == 'g' && isLowerHex(f[1:]):
+			v.ExtraCommit = f[1:]
+		case f == "dirty":
+			v.Dirty = true
+		}
+	}
+	return v, nil
+}
+
+// Short returns p as a "major.minor.patch" version.
+func (p Parsed) Short() string {
+	return fmt.Sprintf("%d.%d.%d", p.Major, p.Minor, p.Patch)
+}
+
+// IsUnstable reports whether p is an unstable version, one with an odd
+// minor version number. See [IsUnstableBuild].
+func (p Parsed) IsUnstable() bool {
+	return p.Minor%2 == 1
+}
+
+// IsDev reports whether p is a development build's version.
+func (p Parsed) IsDev() bool {
+	return p.Dev != ""
+}
+
+// Compare returns -1 if p is older than other, 0 if they're the same
+// version and +1 if p is newer. Only the major, minor and patch numbers are
+// compared.
+func (p Parsed) Compare(other Parsed) int {
+	if c := compareInts(p.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareInts(p.Minor, other.Minor); c != 0 {
+		return c
+	}
+	return compareInts(p.Patch, other.Patch)
+}
+
 type parsed struct {
 	Major, Minor, Patch, ExtraCommits int // for Tailscale version e.g. e.g. "0.99.1-20"
 	Datestamp                         int // for OSS version e.g. "date.20200612"
@@ -4,6 +4,8 @@
 package version_test
 
 import (
+	"flag"
+	"io"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -83,3 +85,230 @@ func TestAtLeast(t *testing.T) {
 		}
 	}
 }
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"1.60.0", "1.60.0", 0, true},
+		{"1.60.0", "1.9.0", +1, true},
+		{"1.9.0", "1.60.0", -1, true},
+		{"1.60.1", "1.60.0", +1, true},
+		{"1.60", "1.60.0", 0, true},
+		{"2", "1.98.7", +1, true},
+		{"1.60.0", "1.60.0-dev20240101", +1, true},
+		{"1.60.0-dev20240101", "1.60.0", -1, true},
+		{"1.61.0-dev20240101", "1.60.0", +1, true},
+		{"1.60.0-dev20240101", "1.60.0-dev20240202", 0, true},
+		{"1.60.0-t1234abcde-g5678fedcb", "1.60.0", 0, true},
+		{"date.20200612", "date.20200701", -1, true},
+		{"bogus", "1.60.0", -1, false},
+		{"1.60.0", "bogus", +1, false},
+		{"abc", "abd", -1, false},
+		{"date.20200612", "1.60.0", +1, false},
+	}
+	for _, tt := range tests {
+		got, ok := version.Compare(tt.a, tt.b)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Compare(%q, %q) = (%v, %v), want (%v, %v)", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSkew(t *testing.T) {
+	tests := []struct {
+		client, daemon string
+		want           int
+		wantWarn       bool
+	}{
+		{"1.62.0", "1.62.0", 0, false},
+		{"1.62.3", "1.62.0", 0, false},
+		{"1.63.0", "1.62.0", 1, false},
+		{"1.61.0", "1.62.0", -1, false},
+		{"1.64.0", "1.62.0", 2, true},
+		{"1.58.2", "1.62.0", -4, true},
+		{"1.63.0-dev20240101", "1.62.1", 1, false},
+		{"1.62.0", "1.64.0-dev20240101", -2, true},
+		{"2.0.0", "1.0.0", 0, true},
+		{"bogus", "1.62.0", 0, false},
+		{"1.62.0", "", 0, false},
+		{"date.20200612", "date.20200701", 0, false},
+	}
+	for _, tt := range tests {
+		got, warn := version.Skew(tt.client, tt.daemon)
+		if got != tt.want || warn != tt.wantWarn {
+			t.Errorf("Skew(%q, %q) = (%v, %v), want (%v, %v)", tt.client, tt.daemon, got, warn, tt.want, tt.wantWarn)
+		}
+	}
+}
+
+func TestShortAtLeast(t *testing.T) {
+	tests := []struct {
+		short, minimum string
+		want           bool
+	}{
+		{"1.62.0", "1.62.0", true},
+		{"1.62.1", "1.62.0", true},
+		{"1.61.9", "1.62.0", false},
+		{"1.62.0-dev", "1.62.0", true},
+		{"1.62.0-dev20240101", "1.62.0", true},
+		{"1.62.0-dev20240101", "1.62", true},
+		{"1.62.0-dev20240101", "1.63.0", false},
+		{"1.100.0", "1.62.0", true},
+		{"bogus-dev", "1.62.0", true},
+		{"bogus", "1.62.0", false},
+		{"1.62.0-dev", "bogus", true},
+		{"1.62.0", "bogus", false},
+	}
+	for _, tt := range tests {
+		if got := version.ExportShortAtLeast(tt.short, tt.minimum); got != tt.want {
+			t.Errorf("shortAtLeast(%q, %q) = %v, want %v", tt.short, tt.minimum, got, tt.want)
+		}
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var c version.Constraint
+	fs.Var(&c, "min-version", "minimum version")
+	if err := fs.Parse([]string{"--min-version=1.62.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.String(); got != "1.62.0" {
+		t.Errorf("String = %q; want 1.62.0", got)
+	}
+
+	for _, bad := range []string{"", "1.62", "1.62.0-dev", "v1.62.0", "1.62.0.1", "1.x.0", "1..0"} {
+		var c version.Constraint
+		if err := c.Set(bad); err == nil {
+			t.Errorf("Set(%q) succeeded; want error", bad)
+		}
+	}
+	if err := fs.Parse([]string{"--min-version=1.62"}); err == nil {
+		t.Error("parsing --min-version=1.62 succeeded; want error")
+	}
+
+	tests := []struct {
+		minimum, short string
+		want           bool
+	}{
+		{"1.62.0", "1.62.0", true},
+		{"1.62.0", "1.61.9", false},
+		{"1.62.0", "1.62.1", true},
+		{"1.62.0", "1.100.0", true},
+		{"1.62.0", "1.62.0-dev20240101", true},
+		{"1.62.1", "1.62.0-dev20240101", false},
+		{"0.0.0", "1.0.0", true},
+		{"", "1.0.0", true},
+	}
+	for _, tt := range tests {
+		var c version.Constraint
+		if tt.minimum != "" {
+			if err := c.Set(tt.minimum); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := version.ExportConstraintSatisfiedBy(c, tt.short); got != tt.want {
+			t.Errorf("Constraint(%q) satisfied by %q = %v; want %v", tt.minimum, tt.short, got, tt.want)
+		}
+	}
+}
+
+func TestParseShort(t *testing.T) {
+	tests := []struct {
+		in       string
+		want     version.Parsed
+		wantErr  bool
+		unstable bool
+	}{
+		{in: "1.62.3", want: version.Parsed{Major: 1, Minor: 62, Patch: 3}},
+		{in: "1.62", want: version.Parsed{Major: 1, Minor: 62}},
+		{
+			in:       "1.63.0-dev20240101",
+			want:     version.Parsed{Major: 1, Minor: 63, Dev: "dev20240101"},
+			unstable: true,
+		},
+		{in: "1.2.3-dev", want: version.Parsed{Major: 1, Minor: 2, Patch: 3, Dev: "dev"}},
+		{in: "1.2.3-devel", want: version.Parsed{Major: 1, Minor: 2, Patch: 3}},
+		{
+			in:   "1.62.3-t1234abcde-g5678fedcb",
+			want: version.Parsed{Major: 1, Minor: 62, Patch: 3, Commit: "1234abcde", ExtraCommit: "5678fedcb"},
+		},
+		{
+			in:   "1.62.3-12-t1234abcde",
+			want: version.Parsed{Major: 1, Minor: 62, Patch: 3, ExtraCommits: 12, Commit: "1234abcde"},
+		},
+		{
+			in:       "1.63.0-dev20240101-t1234abcde-dirty",
+			want:     version.Parsed{Major: 1, Minor: 63, Dev: "dev20240101", Commit: "1234abcde", Dirty: true},
+			unstable: true,
+		},
+		{in: "1.62.3-ERR-BuildInfo", want: version.Parsed{Major: 1, Minor: 62, Patch: 3}},
+		{in: "date.20200612", wantErr: true},
+		{in: "bogus", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := version.ParseShort(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseShort(%q) = (%+v, %v); want (%+v, error %v)", tt.in, got, err, tt.want, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.IsUnstable() != tt.unstable {
+			t.Errorf("ParseShort(%q).IsUnstable() = %v; want %v", tt.in, got.IsUnstable(), tt.unstable)
+		}
+		if got.IsDev() != (tt.want.Dev != "") {
+			t.Errorf("ParseShort(%q).IsDev() = %v", tt.in, got.IsDev())
+		}
+	}
+
+	if got := (version.Parsed{Major: 1, Minor: 62, Patch: 3}).Short(); got != "1.62.3" {
+		t.Errorf("Short() = %q; want 1.62.3", got)
+	}
+
+	cmpTests := []struct {
+		a, b version.Parsed
+		want int
+	}{
+		{version.Parsed{Major: 1, Minor: 62, Patch: 3}, version.Parsed{Major: 1, Minor: 62, Patch: 3}, 0},
+		{version.Parsed{Major: 1, Minor: 62, Patch: 3}, version.Parsed{Major: 1, Minor: 62, Patch: 4}, -1},
+		{version.Parsed{Major: 1, Minor: 100, Patch: 0}, version.Parsed{Major: 1, Minor: 62, Patch: 9}, +1},
+		{version.Parsed{Major: 2, Minor: 0, Patch: 0}, version.Parsed{Major: 1, Minor: 99, Patch: 99}, +1},
+	}
+	for _, tt := range cmpTests {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("%v.Compare(%v) = %d; want %d", tt.a.Short(), tt.b.Short(), got, tt.want)
+		}
+	}
+}
+
+func TestUpdateDirection(t *testing.T) {
+	tests := []struct {
+		from, to     string
+		wantDir      int
+		wantCrossing bool
+	}{
+		{"1.62.0", "1.62.1", +1, false},
+		{"1.62.1", "1.62.0", -1, false},
+		{"1.62.0", "1.62.0", 0, false},
+		{"1.62.0", "1.64.0", +1, false},
+		{"1.62.0", "1.63.0", +1, true},
+		{"1.63.5", "1.62.0", -1, true},
+		{"1.63.0-dev20240101", "1.63.0", 0, false},
+		{"1.62.0", "1.63.0-dev20240101", +1, true},
+		{"1.62.0", "bogus", 0, false},
+		{"", "1.62.0", 0, false},
+	}
+	for _, tt := range tests {
+		dir, crossing := version.UpdateDirection(tt.from, tt.to)
+		if dir != tt.wantDir || crossing != tt.wantCrossing {
+			t.Errorf("UpdateDirection(%q, %q) = (%d, %v); want (%d, %v)", tt.from, tt.to, dir, crossing, tt.wantDir, tt.wantCrossing)
+		}
+	}
+}
@@ -36,6 +36,26 @@
 	JetKVM    = Distro("jetkvm")
 )
 
+// PackageFormat returns the format of the package that Tailscale is installed
+// from on d when the distro determines it, such as "spk" for Synology's
+// Package Center. It returns the empty string for distros on which Tailscale
+// can be installed in more than one way.
+func (d Distro) PackageFormat() string {
+	switch d {
+	case Synology:
+		return "spk"
+	case QNAP:
+		return "qpkg"
+	case Unraid:
+		return "plg"
+	case NixOS:
+		return "nix"
+	case Gokrazy:
+		return "gokrazy"
+	}
+	return ""
+}
+
 var distro lazy.SyncValue[Distro]
 var isWSL lazy.SyncValue[bool]
 
@@ -23,3 +23,25 @@ func checkPreppedExeNameForGUI(preppedExeName string) bool {
 func isGUIExeName(exe, arch string) bool {
 	return checkPreppedExeNameForGUI(prepExeNameForCmp(exe, arch))
 }
+
+// windowsFlavorOfExe returns the WindowsFlavor of the executable exe
+// built for arch.
+func windowsFlavorOfExe(exe, arch string) WindowsFlavor {
+	switch name := prepExeNameForCmp(exe, arch); {
+	case checkPreppedExeNameForGUI(name):
+		return WindowsGUI
+	case name == "tailscaled":
+		return WindowsService
+	case name == "tailscale":
+		return WindowsCLI
+	}
+	return WindowsUnknown
+}
+
+// updaterHelperExePrefix is the prefix of the temporary executable that the
+// Windows self-updater copies itself to before re-executing.
+const updaterHelperExePrefix = "tailscale-updater-"
+
+func isUpdaterHelperExeName(exe string) bool {
+	return strings.HasPrefix(strings.ToLower(filepath.Base(exe)), updaterHelperExePrefix)
+}
@@ -7,6 +7,9 @@
 	ExportParse          = parse
 	ExportFindModuleInfo = findModuleInfo
 	ExportCmdName        = cmdName
+	ExportShortAtLeast   = shortAtLeast
+
+	ExportConstraintSatisfiedBy = Constraint.satisfiedBy
 )
 
 type (
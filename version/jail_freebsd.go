@@ -0,0 +1,12 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package version
+
+import "syscall"
+
+// inJail reports whether the current process is running in a FreeBSD jail.
+func inJail() bool {
+	v, err := syscall.SysctlUint32("security.jail.jailed")
+	return err == nil && v == 1
+}
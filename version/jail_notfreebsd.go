@@ -0,0 +1,9 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !freebsd
+
+package version
+
+// inJail reports whether the current process is running in a FreeBSD jail.
+func inJail() bool { return false }
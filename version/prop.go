@@ -4,15 +4,19 @@
 package version
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
-	"sync"
 
 	"tailscale.com/tailcfg"
 	"tailscale.com/types/lazy"
+	"tailscale.com/util/testenv"
+	"tailscale.com/version/distro"
 )
 
 // AppIdentifierFn, if non-nil, is a callback function that returns the
@@ -45,16 +49,33 @@ func OS() string {
 	// differentiate them. Then a later Go release added GOOS=ios as a separate
 	// platform, but by then the "iOS" and "macOS" values we'd picked, with that
 	// exact capitalization, were already baked into databases.
-	if IsAppleTV() {
+	goos := runtime.GOOS
+	if osForTest != "" {
+		goos = osForTest
+	} else if IsAppleTV() {
 		return "tvOS"
 	}
-	if runtime.GOOS == "ios" {
+	if goos == "ios" {
 		return "iOS"
 	}
-	if runtime.GOOS == "darwin" {
+	if goos == "darwin" {
 		return "macOS"
 	}
-	return runtime.GOOS
+	return goos
+}
+
+// osForTest, if non-empty, is the GOOS that OS reports on instead of
+// runtime.GOOS. See SetOSForTest.
+var osForTest string
+
+// SetOSForTest makes [OS] act as if runtime.GOOS were goos for the duration
+// of tb and its subtests. The Apple TV check is skipped. It panics outside
+// of tests.
+func SetOSForTest(tb testenv.TB, goos string) {
+	testenv.AssertInTest()
+	old := osForTest
+	osForTest = goos
+	tb.Cleanup(func() { osForTest = old })
 }
 
 // IsMacGUIVariant reports whether runtime.GOOS=="darwin" and this one of the
@@ -209,28 +230,385 @@ func IsWindowsGUI() bool {
 	})
 }
 
-var isUnstableBuild lazy.SyncValue[bool]
+var linuxPackaging lazy.SyncValue[string]
+
+// LinuxPackaging returns how this binary was installed on Linux: "deb" or
+// "rpm" if it's in /usr/bin or /usr/sbin and the Debian package database
+// lists the tailscale package or an RPM database exists, "tgz" if it's
+// somewhere else (as a binary extracted from the static tarball typically
+// is), or "unknown", including for binaries in the Nix store or a snap
+// and when not running on Linux.
+func LinuxPackaging() string {
+	if runtime.GOOS != "linux" {
+		return "unknown"
+	}
+	return linuxPackaging.Get(func() string {
+		exe, err := os.Executable()
+		if err != nil {
+			return "unknown"
+		}
+		return linuxPackagingOf(exe, func(name string) bool {
+			_, err := os.Stat(name)
+			return err == nil
+		})
+	})
+}
 
-// IsUnstableBuild reports whether this is an unstable build.
-// That is, whether its minor version number is odd.
-func IsUnstableBuild() bool {
-	return isUnstableBuild.Get(func() bool {
-		_, rest, ok := strings.Cut(Short(), ".")
-		if !ok {
-			return false
+// linuxPackagingOf is LinuxPackaging for the executable path exe, where
+// exists reports whether a file exists.
+func linuxPackagingOf(exe string, exists func(string) bool) string {
+	switch path.Dir(exe) {
+	case "/usr/bin", "/usr/sbin":
+		switch {
+		case exists("/var/lib/dpkg/info/tailscale.list"):
+			return "deb"
+		case exists("/var/lib/rpm"), exists("/usr/lib/sysimage/rpm"):
+			return "rpm"
 		}
-		minorStr, _, ok := strings.Cut(rest, ".")
-		if !ok {
-			return false
+		return "unknown"
+	}
+	if strings.HasPrefix(exe, "/nix/store/") || strings.HasPrefix(exe, "/snap/") {
+		return "unknown"
+	}
+	return "tgz"
+}
+
+var isContainer lazy.SyncValue[bool]
+
+// IsContainer reports whether the current process is running in a Linux
+// container. It's best-effort: it looks for the /.dockerenv file that Docker
+// creates, Podman and CRI-O's /run/.containerenv, and a cgroup of process 1
+// belonging to Docker, containerd, Kubernetes or LXC. The cgroup check only
+// works when the container has no cgroup namespace of its own, as is usual
+// with cgroup v1.
+func IsContainer() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	return isContainer.Get(func() bool {
+		for _, f := range []string{"/.dockerenv", "/run/.containerenv"} {
+			if _, err := os.Stat(f); err == nil {
+				return true
+			}
+		}
+		b, _ := os.ReadFile("/proc/1/cgroup")
+		return cgroupIndicatesContainer(string(b))
+	})
+}
+
+// cgroupIndicatesContainer reports whether the contents of a
+// /proc/<pid>/cgroup file place the process in a container's cgroup.
+func cgroupIndicatesContainer(cgroup string) bool {
+	for line := range strings.Lines(cgroup) {
+		// Lines are "hierarchy-ID:controllers:path".
+		f := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(f) != 3 {
+			continue
+		}
+		path := f[2]
+		for _, marker := range []string{"/docker/", "/docker-", "containerd", "/kubepods", "/lxc/", "/lxc.payload"} {
+			if strings.Contains(path, marker) {
+				return true
+			}
 		}
-		minor, err := strconv.Atoi(minorStr)
+	}
+	return false
+}
+
+var installSource lazy.SyncValue[string]
+
+// InstallSource returns how this binary was installed:
+//
+//   - "appstore" for the iOS, tvOS and Android apps and the Mac App Store app
+//   - "standalone" for the macOS app downloaded from Tailscale
+//   - "msi" for Tailscale's own Windows programs (see [CurrentWindowsFlavor])
+//   - "docker" for Linux container images
+//   - "apk" for the Alpine package, or the format from
+//     [distro.Distro.PackageFormat] on distros that have their own, such as
+//     "spk" on Synology
+//   - otherwise on Linux, what [LinuxPackaging] reports: "deb", "rpm", "tgz"
+//     or "unknown"
+//   - "unknown" elsewhere
+func InstallSource() string {
+	return installSource.Get(func() string {
+		switch {
+		case IsMobile() || IsAppleTV() || IsMacAppStore():
+			return "appstore"
+		case IsMacSys():
+			return "standalone"
+		}
+		switch runtime.GOOS {
+		case "windows":
+			return windowsInstallSourceOf(CurrentWindowsFlavor())
+		case "linux":
+			return linuxInstallSourceOf(distro.Get(), IsContainer(), LinuxPackaging())
+		}
+		return "unknown"
+	})
+}
+
+// windowsInstallSourceOf is InstallSource on Windows for a process of
+// flavor f. Other programs built from this tree, such as tsnet apps, aren't
+// installed by the MSI.
+func windowsInstallSourceOf(f WindowsFlavor) string {
+	if f == WindowsUnknown {
+		return "unknown"
+	}
+	return "msi"
+}
+
+// linuxInstallSourceOf is InstallSource on Linux distro d, given whether the
+// process is in a container and what LinuxPackaging reports.
+func linuxInstallSourceOf(d distro.Distro, container bool, packaging string) string {
+	if f := d.PackageFormat(); f != "" {
+		return f
+	}
+	if container {
+		return "docker"
+	}
+	if d == distro.Alpine && packaging == "unknown" {
+		// The Alpine package installs to /usr/sbin but, lacking a dpkg or
+		// RPM database, LinuxPackaging can't tell.
+		return "apk"
+	}
+	return packaging
+}
+
+// WindowsFlavor is which of Tailscale's Windows programs a process is.
+type WindowsFlavor int
+
+const (
+	// WindowsUnknown is for processes that aren't one of the others,
+	// and for all processes on other platforms.
+	WindowsUnknown WindowsFlavor = iota
+	// WindowsGUI is the GUI, tailscale-ipn.exe; see [IsWindowsGUI].
+	WindowsGUI
+	// WindowsService is the tailscaled.exe service.
+	WindowsService
+	// WindowsCLI is the command-line client, tailscale.exe.
+	WindowsCLI
+)
+
+// String returns "gui", "service", "cli" or "unknown".
+func (f WindowsFlavor) String() string {
+	switch f {
+	case WindowsUnknown:
+		return "unknown"
+	case WindowsGUI:
+		return "gui"
+	case WindowsService:
+		return "service"
+	case WindowsCLI:
+		return "cli"
+	}
+	return "WindowsFlavor(" + strconv.Itoa(int(f)) + ")"
+}
+
+var windowsFlavor lazy.SyncValue[WindowsFlavor]
+
+// CurrentWindowsFlavor returns which of Tailscale's Windows programs the
+// current process is, classified by its executable's name like
+// [IsWindowsGUI]. It returns [WindowsUnknown] on other platforms.
+func CurrentWindowsFlavor() WindowsFlavor {
+	if runtime.GOOS != "windows" {
+		return WindowsUnknown
+	}
+	return windowsFlavor.Get(func() WindowsFlavor {
+		exe, err := os.Executable()
+		if err != nil {
+			return WindowsUnknown
+		}
+		return windowsFlavorOfExe(exe, runtime.GOARCH)
+	})
+}
+
+// UpdaterHelperEnv is the environment variable that the self-updater (package
+// clientupdate) sets to "1" in the environment of the helper process it spawns
+// to swap out the installed binaries.
+const UpdaterHelperEnv = "TS_UPDATER_HELPER"
+
+var isUpdaterHelper lazy.SyncValue[bool]
+
+// IsUpdaterHelper reports whether the current process is a helper spawned by
+// the self-updater to replace the installed binaries, in which case it should
+// skip its normal startup and only perform the swap.
+//
+// A process is a helper if $TS_UPDATER_HELPER is "1" or, as the older updater
+// didn't set that variable, if its executable is named like the temporary
+// "tailscale-updater-*.exe" copy that the updater makes of itself on Windows.
+func IsUpdaterHelper() bool {
+	return isUpdaterHelper.Get(func() bool {
+		if os.Getenv(UpdaterHelperEnv) == "1" {
+			return true
+		}
+		exe, err := os.Executable()
 		if err != nil {
 			return false
 		}
-		return minor%2 == 1
+		return isUpdaterHelperExeName(exe)
 	})
 }
 
+var isUnstableBuild lazy.SyncValue[bool]
+
+// IsUnstableBuild reports whether this is an unstable build.
+// That is, whether its minor version number is odd.
+func IsUnstableBuild() bool {
+	return isUnstableBuild.Get(func() bool {
+		return hasOddMinor(Short())
+	})
+}
+
+// hasOddMinor reports whether the version string short has an odd minor
+// version number.
+func hasOddMinor(short string) bool {
+	_, rest, ok := strings.Cut(short, ".")
+	if !ok {
+		return false
+	}
+	minorStr, _, ok := strings.Cut(rest, ".")
+	if !ok {
+		return false
+	}
+	minor, err := strconv.Atoi(minorStr)
+	if err != nil {
+		return false
+	}
+	return minor%2 == 1
+}
+
+// Track is the release track a build belongs to.
+type Track int
+
+const (
+	// TrackStable is for builds of stable releases, which have even minor
+	// version numbers.
+	TrackStable Track = iota
+	// TrackUnstable is for builds of unstable releases, which have odd
+	// minor version numbers.
+	TrackUnstable
+	// TrackDev is for development builds, whose short version has a
+	// "-dev" or "-devYYYYMMDD" suffix.
+	TrackDev
+)
+
+// String returns "stable", "unstable" or "dev".
+func (t Track) String() string {
+	switch t {
+	case TrackStable:
+		return "stable"
+	case TrackUnstable:
+		return "unstable"
+	case TrackDev:
+		return "dev"
+	}
+	return "Track(" + strconv.Itoa(int(t)) + ")"
+}
+
+// CurrentTrack returns the release track of the current build, classified
+// from [Short]: [TrackDev] for dev builds, otherwise [TrackUnstable] if the
+// minor version is odd (see [IsUnstableBuild]) and [TrackStable] if not.
+//
+// Unlike [ReleaseChannel], it doesn't consider the channel stamped by the
+// release pipeline.
+func CurrentTrack() Track {
+	return trackOf(Short())
+}
+
+// trackOf returns the Track of the short version short.
+func trackOf(short string) Track {
+	switch {
+	case strings.Contains(short, "-dev"):
+		return TrackDev
+	case hasOddMinor(short):
+		return TrackUnstable
+	}
+	return TrackStable
+}
+
+// ReleaseChannel returns the release channel the build was made for:
+//
+//   - "stable" or "unstable" for the usual release tracks
+//   - "nightly" for automated builds from the main branch
+//   - "internal" for builds not meant for distribution outside Tailscale
+//
+// The release pipeline stamps the channel at link time. For builds without a
+// stamp, it's inferred from the version number: "unstable" if the minor
+// version is odd (see [IsUnstableBuild]), and "stable" otherwise.
+func ReleaseChannel() string {
+	switch releaseChannelStamp {
+	case "stable", "unstable", "nightly", "internal":
+		return releaseChannelStamp
+	}
+	if IsUnstableBuild() {
+		return "unstable"
+	}
+	return "stable"
+}
+
+// SupportsGracefulUpgrade reports whether this binary can be upgraded in
+// place without dropping connections, with the new tailscaled taking over the
+// running state of the old one. The updater uses it to choose between a
+// seamless and a disruptive upgrade, and to tell the user which to expect.
+//
+// It reports true only if all of:
+//
+//   - the release pipeline stamped the build as capable of the handover at
+//     link time, as no build currently is
+//   - the OS is one on which tailscaled replaces its own binaries (Linux,
+//     Windows or FreeBSD)
+//   - the flavor isn't one upgraded from the outside, as mobile, tvOS and
+//     Mac App Store apps are by their stores and container images are by
+//     being replaced
+func SupportsGracefulUpgrade() bool {
+	managed := IsMobile() || IsAppleTV() || IsMacAppStore() || IsContainer()
+	return gracefulUpgradeOf(gracefulUpgradeStamp != "", runtime.GOOS, managed)
+}
+
+// gracefulUpgradeOf reports whether a build for goos supports graceful
+// upgrade, given whether it's stamped as capable and whether its flavor is
+// upgraded from the outside.
+func gracefulUpgradeOf(stamped bool, goos string, managed bool) bool {
+	if !stamped || managed {
+		return false
+	}
+	switch goos {
+	case "linux", "windows", "freebsd":
+		return true
+	}
+	return false
+}
+
+var unixVariant lazy.SyncValue[string]
+
+// UnixVariant returns a normalized label for the Unix platform other than
+// Linux and Apple's that this binary is running on: "freebsd", "openbsd",
+// "netbsd", "dragonfly", or "illumos" for both the illumos and Solaris
+// kernels. On FreeBSD, a "-jail" suffix is added when running in a jail.
+// It returns the empty string on other platforms.
+func UnixVariant() string {
+	return unixVariant.Get(func() string {
+		return unixVariantOf(runtime.GOOS, inJail())
+	})
+}
+
+// unixVariantOf returns the UnixVariant label for goos, where jailed is
+// whether the process is running in a FreeBSD jail.
+func unixVariantOf(goos string, jailed bool) string {
+	switch goos {
+	case "freebsd":
+		if jailed {
+			return "freebsd-jail"
+		}
+		return "freebsd"
+	case "openbsd", "netbsd", "dragonfly":
+		return goos
+	case "illumos", "solaris":
+		return "illumos"
+	}
+	return ""
+}
+
 // osVariant returns the OS variant string for systems where we support
 // multiple ways of running tailscale(d), if any.
 //
@@ -248,9 +626,21 @@ func osVariant() string {
 	return ""
 }
 
-var isDev = sync.OnceValue(func() bool {
-	return strings.Contains(Short(), "-dev")
-})
+var isDev lazy.SyncValue[bool]
+
+// IsDev reports whether this is a development build, one whose [Short]
+// version has a "-dev" or "-devYYYYMMDD" suffix.
+func IsDev() bool {
+	return isDev.Get(func() bool {
+		return isDevVersion(Short())
+	})
+}
+
+// isDevVersion reports whether the version string short is that of a
+// development build.
+func isDevVersion(short string) bool {
+	return strings.Contains(short, "-dev")
+}
 
 // Meta is a JSON-serializable type that contains all the version
 // information.
@@ -321,6 +711,111 @@ type Meta struct {
 	// incrementing integer that's incremented whenever a new capability is
 	// added.
 	Cap int `json:"cap"`
+
+	// PGO is whether the binary is known to have been built with
+	// profile-guided optimization. See [IsPGOBuild].
+	PGO bool `json:"pgo,omitempty"`
+
+	// DefaultLogLevel is the build's default log level: "info", "debug" or
+	// "verbose". See [DefaultLogLevel].
+	DefaultLogLevel string `json:"defaultLogLevel,omitempty"`
+
+	// ReleaseChannel is the release channel the build was made for, such
+	// as "stable" or "unstable". See [ReleaseChannel].
+	ReleaseChannel string `json:"releaseChannel,omitempty"`
+
+	// MinTLSVersion is the minimum TLS version the build negotiates,
+	// "1.2" or "1.3". See [MinTLSVersion].
+	MinTLSVersion string `json:"minTLSVersion,omitempty"`
+
+	// EmbedsTZData is whether the binary embeds the time zone database.
+	// See [EmbedsTZData].
+	EmbedsTZData bool `json:"embedsTZData,omitempty"`
+
+	// GracefulUpgrade is whether the binary can be upgraded in place
+	// without dropping connections. See [SupportsGracefulUpgrade].
+	GracefulUpgrade bool `json:"gracefulUpgrade,omitempty"`
+
+	// InstallSource is how the binary was installed, such as "deb", "msi",
+	// "docker" or "appstore". See [InstallSource].
+	InstallSource string `json:"installSource,omitempty"`
+
+	// SelfUpdate is whether "tailscale update" can update the binary in
+	// place. Only tailscaled knows whether it was built with the updater,
+	// so GetMeta leaves it false; it's set in the metadata that tailscaled
+	// serves over the LocalAPI.
+	SelfUpdate bool `json:"selfUpdate,omitempty"`
+}
+
+// Equal reports whether m and other are identical in all fields.
+func (m Meta) Equal(other Meta) bool {
+	// Meta only has comparable fields. Adding one that isn't breaks
+	// compilation here, so that Equal can be updated.
+	return m == other
+}
+
+// String returns a compact human-readable summary of m, such as
+// "1.62.3 (cap 106, commit 1234abcde, dirty)". Empty optional fields are
+// omitted.
+func (m Meta) String() string {
+	var sb strings.Builder
+	sb.WriteString(m.Short)
+	sb.WriteString(" (cap ")
+	sb.WriteString(strconv.Itoa(m.Cap))
+	if m.GitCommit != "" {
+		sb.WriteString(", commit ")
+		sb.WriteString(abbrevCommit(m.GitCommit))
+	}
+	if m.ExtraGitCommit != "" {
+		sb.WriteString(", extra commit ")
+		sb.WriteString(abbrevCommit(m.ExtraGitCommit))
+	}
+	if m.GitDirty {
+		sb.WriteString(", dirty")
+	}
+	if m.DaemonLong != "" {
+		sb.WriteString(", daemon ")
+		sb.WriteString(m.DaemonLong)
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// abbrevCommit returns the abbreviated form of the git commit hash commit,
+// as used in [Long].
+func abbrevCommit(commit string) string {
+	if len(commit) > 9 {
+		return commit[:9]
+	}
+	return commit
+}
+
+// ParseMeta decodes the JSON encoding of a Meta, as received from another
+// node, and checks that it's consistent: MajorMinorPatch must be of the form
+// "N.N.N", Short must begin with that version (optionally followed by a
+// "-" suffix), and Cap mustn't be negative. If data doesn't decode or isn't
+// consistent, it returns an error and a zero Meta.
+func ParseMeta(data []byte) (Meta, error) {
+	var m Meta
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Meta{}, fmt.Errorf("decoding version metadata: %w", err)
+	}
+	if !isMajorMinorPatch(m.MajorMinorPatch) {
+		return Meta{}, fmt.Errorf("invalid majorMinorPatch %q", m.MajorMinorPatch)
+	}
+	if rest, ok := strings.CutPrefix(m.Short, m.MajorMinorPatch); !ok || (rest != "" && rest[0] != '-') {
+		return Meta{}, fmt.Errorf("short version %q doesn't match majorMinorPatch %q", m.Short, m.MajorMinorPatch)
+	}
+	if m.Cap < 0 {
+		return Meta{}, fmt.Errorf("invalid negative capability version %d", m.Cap)
+	}
+	return m, nil
+}
+
+// isMajorMinorPatch reports whether s is of the form "N.N.N".
+func isMajorMinorPatch(s string) bool {
+	parts := strings.Split(s, ".")
+	return len(parts) == 3 && allDigits(parts[0]) && allDigits(parts[1]) && allDigits(parts[2])
 }
 
 var getMeta lazy.SyncValue[Meta]
@@ -337,10 +832,17 @@ func GetMeta() Meta {
 			GitDirty:           gitDirty(),
 			OSVariant:          osVariant(),
 			ExtraGitCommit:     extraGitCommitStamp,
-			IsDev:              isDev(),
+			IsDev:              IsDev(),
 			UnstableBranch:     IsUnstableBuild(),
 			TailscaleGoGitHash: tailscaleToolchainRev(),
 			Cap:                int(tailcfg.CurrentCapabilityVersion),
+			PGO:                IsPGOBuild(),
+			DefaultLogLevel:    DefaultLogLevel(),
+			ReleaseChannel:     ReleaseChannel(),
+			MinTLSVersion:      MinTLSVersion(),
+			EmbedsTZData:       EmbedsTZData(),
+			GracefulUpgrade:    SupportsGracefulUpgrade(),
+			InstallSource:      InstallSource(),
 		}
 	})
 }
@@ -0,0 +1,11 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !timetzdata
+
+package version
+
+// EmbedsTZData reports whether the current binary was built with the
+// timetzdata build tag, which embeds Go's copy of the time zone database so
+// that time zones can be loaded without the system's.
+func EmbedsTZData() bool { return false }
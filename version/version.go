@@ -10,6 +10,7 @@
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	tailscaleroot "tailscale.com"
 	"tailscale.com/types/lazy"
@@ -55,6 +56,29 @@
 	// repository). Together, gitCommit and extraGitCommit exactly describe what
 	// repositories and commits were used in a build.
 	extraGitCommitStamp string
+
+	// pgoStamp, if non-empty, records that the binary was built with
+	// profile-guided optimization by Tailscale's release pipeline.
+	// Conventionally, it's the name of the profile used.
+	pgoStamp string
+
+	// logLevelStamp is the default log level of the build, one of "info",
+	// "debug" or "verbose". Other values are ignored. See DefaultLogLevel.
+	logLevelStamp string
+
+	// releaseChannelStamp is the release channel the build was made for,
+	// one of "stable", "unstable", "nightly" or "internal". Other values
+	// are ignored. See ReleaseChannel.
+	releaseChannelStamp string
+
+	// minTLSVersionStamp is the minimum TLS version the build negotiates,
+	// "1.2" or "1.3". Other values are ignored. See MinTLSVersion.
+	minTLSVersionStamp string
+
+	// gracefulUpgradeStamp, if non-empty, records that the build's daemon
+	// can hand its running state to its upgraded successor without dropping
+	// connections. See SupportsGracefulUpgrade.
+	gracefulUpgradeStamp string
 )
 
 var long lazy.SyncValue[string]
@@ -123,8 +147,14 @@ func (i embeddedInfo) commitAbbrev() string {
 	if !ok {
 		return embeddedInfo{}
 	}
+	return embeddedInfoFromSettings(bi.Settings)
+})
+
+// embeddedInfoFromSettings returns the VCS information in the build info
+// settings, or an invalid embeddedInfo if they lack the commit or its time.
+func embeddedInfoFromSettings(settings []debug.BuildSetting) embeddedInfo {
 	ret := embeddedInfo{valid: true}
-	for _, s := range bi.Settings {
+	for _, s := range settings {
 		switch s.Key {
 		case "vcs.revision":
 			ret.commit = s.Value
@@ -144,7 +174,7 @@ func (i embeddedInfo) commitAbbrev() string {
 		return embeddedInfo{}
 	}
 	return ret
-})
+}
 
 // tailscaleToolchainRev returns the git hash of the Tailscale Go toolchain
 // used to build this binary, if any. It is read separately from getEmbeddedInfo
@@ -163,6 +193,66 @@ func (i embeddedInfo) commitAbbrev() string {
 	return ""
 })
 
+// buildInfoPGO returns the -pgo setting recorded by the Go tool, if any.
+var buildInfoPGO = sync.OnceValue(func() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, s := range bi.Settings {
+		if s.Key == "-pgo" {
+			return s.Value
+		}
+	}
+	return ""
+})
+
+// IsPGOBuild reports whether the binary is known to have been built with
+// profile-guided optimization, which changes its performance
+// characteristics. That's the case if the release pipeline stamped it as
+// such or if the Go tool recorded a -pgo profile in the build info.
+//
+// A false result doesn't prove the binary wasn't built with PGO, only that
+// it wasn't recorded (for instance, if the build info was stripped).
+func IsPGOBuild() bool {
+	return pgoStamp != "" || buildInfoPGO() != ""
+}
+
+// DefaultLogLevel returns the compiled-in default log level of the build,
+// which explains differences in log volume between otherwise identical
+// builds. It's one of:
+//
+//   - "info", the default, for normal logging
+//   - "debug", for builds that additionally log debugging information
+//   - "verbose", for builds that log as if run with maximum verbosity
+//
+// Builds choose a level other than "info" by stamping it at link time.
+func DefaultLogLevel() string {
+	switch logLevelStamp {
+	case "debug", "verbose":
+		return logLevelStamp
+	}
+	return "info"
+}
+
+// MinTLSVersion returns the minimum TLS version the build negotiates by
+// default, as asked about by compliance audits. It's one of:
+//
+//   - "1.2", the default, matching the Go standard library's default
+//   - "1.3", for builds whose policy requires TLS 1.3
+//
+// Builds require TLS 1.3 by stamping it at link time. The policy is enforced
+// by [tailscale.com/net/tlsdial.Config], which configures the TLS connections
+// to the control plane, DERP servers and the log server. Connections that
+// configure TLS themselves, such as to DNS-over-TLS servers, set their own
+// minimum and aren't covered by it.
+func MinTLSVersion() string {
+	if minTLSVersionStamp == "1.3" {
+		return "1.3"
+	}
+	return "1.2"
+}
+
 func gitCommit() string {
 	if gitCommitStamp != "" {
 		return gitCommitStamp
@@ -184,6 +274,28 @@ func dirtyString() string {
 	return ""
 }
 
+// DevDate returns the date in the "-devYYYYMMDD" suffix of [Short], the
+// date of the commit a development build was made from, as midnight UTC. It
+// reports ok=false for builds that aren't development builds, and for those
+// whose suffix is a plain "-dev" without a date.
+func DevDate() (date time.Time, ok bool) {
+	return devDateOf(Short())
+}
+
+// devDateOf is DevDate for the short version short.
+func devDateOf(short string) (time.Time, bool) {
+	_, suffix, ok := strings.Cut(short, "-dev")
+	if !ok || len(suffix) < 8 {
+		return time.Time{}, false
+	}
+	// Parse validates the calendar date, such as rejecting February 30.
+	d, err := time.Parse("20060102", suffix[:8])
+	if err != nil || (len(suffix) > 8 && suffix[8] != '-') {
+		return time.Time{}, false
+	}
+	return d, true
+}
+
 func majorMinorPatch() string {
 	ret, _, _ := strings.Cut(Short(), "-")
 	return ret
@@ -193,6 +305,67 @@ func majorMinorPatch() string {
 // Tailscale's custom Go toolchain.
 func IsTailscaleGo() bool { return isTailscaleGo }
 
+// ParseLong splits a long version string, as returned by [Long] and reported
+// in [Meta.Long], back into its parts: the "major.minor.patch" version mmp,
+// the abbreviated commit hashes (that of GitCommit, followed by that of
+// ExtraGitCommit if present), and whether the build was from a dirty
+// checkout.
+//
+// It accepts the forms documented on [Long] that include commit hashes,
+// where the Tailscale commit is prefixed with "t" and the extra commit, if
+// any, with "g", such as "1.80.2-t1234abcde-g5678fedcb", the
+// "1.80.2-12-t1234abcde" form with a change count, and the
+// "1.81.0-dev20250101-t1234abcde-dirty" form of dev builds. For anything
+// else, it returns ok=false rather than guessing.
+func ParseLong(long string) (mmp string, commits []string, dirty bool, ok bool) {
+	parts := strings.Split(long, "-")
+	mmp, parts = parts[0], parts[1:]
+	if nums := strings.Split(mmp, "."); len(nums) != 3 || !allDigits(nums[0]) || !allDigits(nums[1]) || !allDigits(nums[2]) {
+		return "", nil, false, false
+	}
+	// Optional change count or dev datestamp.
+	if len(parts) > 0 && (allDigits(parts[0]) || strings.HasPrefix(parts[0], "dev") && allDigits(parts[0][len("dev"):])) {
+		parts = parts[1:]
+	}
+	if len(parts) > 0 && parts[len(parts)-1] == "dirty" {
+		dirty = true
+		parts = parts[:len(parts)-1]
+	}
+	for i, prefix := range []string{"t", "g"} {
+		if i >= len(parts) {
+			break
+		}
+		hash, found := strings.CutPrefix(parts[i], prefix)
+		if !found || len(hash) < 6 || !isLowerHex(hash) {
+			return "", nil, false, false
+		}
+		commits = append(commits, hash)
+	}
+	if len(commits) == 0 || len(commits) != len(parts) {
+		return "", nil, false, false
+	}
+	return mmp, commits, dirty, true
+}
+
+func allDigits(s string) bool {
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isLowerHex(s string) bool {
+	for i := range len(s) {
+		b := s[i]
+		if (b < '0' || b > '9') && (b < 'a' || b > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 func isValidLongWithTwoRepos(v string) bool {
 	s := strings.Split(v, "-")
 	if len(s) != 3 {
@@ -5,10 +5,16 @@
 
 import (
 	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"tailscale.com/tailcfg"
 	"tailscale.com/util/cibuild"
+	"tailscale.com/version/distro"
 )
 
 func TestIsValidLongWithTwoRepos(t *testing.T) {
@@ -86,3 +92,427 @@ func TestPrepExeNameForCmp(t *testing.T) {
 		}
 	}
 }
+
+func TestWindowsFlavorOfExe(t *testing.T) {
+	tests := []struct {
+		exe  string
+		want WindowsFlavor
+	}{
+		{"tailscale-ipn.exe", WindowsGUI},
+		{"tailscale-gui-amd64.exe", WindowsGUI},
+		{"tailscaled.exe", WindowsService},
+		{"TailscaleD.EXE", WindowsService},
+		{"tailscale.exe", WindowsCLI},
+		{"tailscale-amd64.exe", WindowsCLI},
+		{"tailscale-updater-1234.exe", WindowsUnknown},
+		{"notepad.exe", WindowsUnknown},
+	}
+	for _, tt := range tests {
+		if got := windowsFlavorOfExe(tt.exe, "amd64"); got != tt.want {
+			t.Errorf("windowsFlavorOfExe(%q) = %v; want %v", tt.exe, got, tt.want)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		if got := CurrentWindowsFlavor(); got != WindowsUnknown {
+			t.Errorf("CurrentWindowsFlavor = %v on %s; want unknown", got, runtime.GOOS)
+		}
+	}
+}
+
+func TestIsUpdaterHelperExeName(t *testing.T) {
+	tests := []struct {
+		exe  string
+		want bool
+	}{
+		{"/tmp/tailscale-updater-1234.exe", true},
+		{"tailscale-updater-1234.exe", true},
+		{"TAILSCALE-UPDATER-1234.EXE", true},
+		{"tailscale.exe", false},
+		{"tailscaled", false},
+		{"tailscale-updater", false},
+	}
+	for _, tt := range tests {
+		if got := isUpdaterHelperExeName(tt.exe); got != tt.want {
+			t.Errorf("isUpdaterHelperExeName(%q) = %v; want %v", tt.exe, got, tt.want)
+		}
+	}
+}
+
+func TestIsDevVersion(t *testing.T) {
+	tests := []struct {
+		short string
+		want  bool
+	}{
+		{"1.2.3", false},
+		{"1.2.3-dev", true},
+		{"1.2.3-dev20240101", true},
+	}
+	for _, tt := range tests {
+		if got := isDevVersion(tt.short); got != tt.want {
+			t.Errorf("isDevVersion(%q) = %v; want %v", tt.short, got, tt.want)
+		}
+	}
+	if got, want := IsDev(), GetMeta().IsDev; got != want {
+		t.Errorf("IsDev() = %v; GetMeta().IsDev = %v", got, want)
+	}
+}
+
+func TestUnixVariantOf(t *testing.T) {
+	tests := []struct {
+		goos   string
+		jailed bool
+		want   string
+	}{
+		{"freebsd", false, "freebsd"},
+		{"freebsd", true, "freebsd-jail"},
+		{"openbsd", false, "openbsd"},
+		{"netbsd", false, "netbsd"},
+		{"dragonfly", false, "dragonfly"},
+		{"illumos", false, "illumos"},
+		{"solaris", false, "illumos"},
+		{"linux", false, ""},
+		{"darwin", false, ""},
+		{"windows", false, ""},
+	}
+	for _, tt := range tests {
+		if got := unixVariantOf(tt.goos, tt.jailed); got != tt.want {
+			t.Errorf("unixVariantOf(%q, %v) = %q; want %q", tt.goos, tt.jailed, got, tt.want)
+		}
+	}
+}
+
+func TestIsPGOBuildStamp(t *testing.T) {
+	if buildInfoPGO() != "" {
+		t.Skip("test binary was built with -pgo")
+	}
+	old := pgoStamp
+	t.Cleanup(func() { pgoStamp = old })
+
+	pgoStamp = ""
+	if IsPGOBuild() {
+		t.Error("IsPGOBuild = true without stamp")
+	}
+	pgoStamp = "default.pgo"
+	if !IsPGOBuild() {
+		t.Error("IsPGOBuild = false with stamp")
+	}
+}
+
+func TestDefaultLogLevel(t *testing.T) {
+	old := logLevelStamp
+	t.Cleanup(func() { logLevelStamp = old })
+
+	tests := []struct {
+		stamp, want string
+	}{
+		{"", "info"},
+		{"info", "info"},
+		{"debug", "debug"},
+		{"verbose", "verbose"},
+		{"bogus", "info"},
+	}
+	for _, tt := range tests {
+		logLevelStamp = tt.stamp
+		if got := DefaultLogLevel(); got != tt.want {
+			t.Errorf("stamp %q: DefaultLogLevel = %q; want %q", tt.stamp, got, tt.want)
+		}
+	}
+}
+
+func TestGracefulUpgradeOf(t *testing.T) {
+	tests := []struct {
+		stamped bool
+		goos    string
+		managed bool
+		want    bool
+	}{
+		{false, "linux", false, false},
+		{true, "linux", false, true},
+		{true, "windows", false, true},
+		{true, "freebsd", false, true},
+		{true, "linux", true, false},
+		{true, "darwin", false, false},
+		{true, "ios", true, false},
+		{true, "openbsd", false, false},
+	}
+	for _, tt := range tests {
+		if got := gracefulUpgradeOf(tt.stamped, tt.goos, tt.managed); got != tt.want {
+			t.Errorf("gracefulUpgradeOf(%v, %q, %v) = %v; want %v", tt.stamped, tt.goos, tt.managed, got, tt.want)
+		}
+	}
+	if SupportsGracefulUpgrade() {
+		t.Error("SupportsGracefulUpgrade = true without stamp")
+	}
+}
+
+func TestMinTLSVersion(t *testing.T) {
+	old := minTLSVersionStamp
+	t.Cleanup(func() { minTLSVersionStamp = old })
+
+	tests := []struct {
+		stamp, want string
+	}{
+		{"", "1.2"},
+		{"1.2", "1.2"},
+		{"1.3", "1.3"},
+		{"1.1", "1.2"},
+		{"bogus", "1.2"},
+	}
+	for _, tt := range tests {
+		minTLSVersionStamp = tt.stamp
+		if got := MinTLSVersion(); got != tt.want {
+			t.Errorf("stamp %q: MinTLSVersion = %q; want %q", tt.stamp, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseChannel(t *testing.T) {
+	old := releaseChannelStamp
+	t.Cleanup(func() { releaseChannelStamp = old })
+
+	releaseChannelStamp = ""
+	want := "stable"
+	if IsUnstableBuild() {
+		want = "unstable"
+	}
+	if got := ReleaseChannel(); got != want {
+		t.Errorf("inferred ReleaseChannel = %q; want %q", got, want)
+	}
+
+	for _, stamp := range []string{"stable", "unstable", "nightly", "internal"} {
+		releaseChannelStamp = stamp
+		if got := ReleaseChannel(); got != stamp {
+			t.Errorf("stamp %q: ReleaseChannel = %q", stamp, got)
+		}
+	}
+
+	releaseChannelStamp = "bogus"
+	if got := ReleaseChannel(); got != want {
+		t.Errorf("invalid stamp: ReleaseChannel = %q; want inferred %q", got, want)
+	}
+}
+
+func TestCapInfo(t *testing.T) {
+	oldRelease, oldGates := capRelease, capGates
+	t.Cleanup(func() { capRelease, capGates = oldRelease, oldGates })
+	capRelease = map[int]string{100: "1.70.0", 110: "1.74.0"}
+	capGates = map[string]int{"peer-relay": 95, "cache-netmap": 100, "future-thing": 110}
+
+	v, introducedIn, features := Meta{Cap: 100}.CapInfo()
+	if v != 100 || introducedIn != "1.70.0" || !slices.Equal(features, []string{"cache-netmap", "peer-relay"}) {
+		t.Errorf("CapInfo = (%v, %q, %q); want (100, \"1.70.0\", [cache-netmap peer-relay])", v, introducedIn, features)
+	}
+	v, introducedIn, features = Meta{Cap: 90}.CapInfo()
+	if v != 90 || introducedIn != "" || features != nil {
+		t.Errorf("CapInfo = (%v, %q, %q); want (90, \"\", nil)", v, introducedIn, features)
+	}
+}
+
+func TestCapTables(t *testing.T) {
+	for name, capVer := range capGates {
+		if capVer > int(tailcfg.CurrentCapabilityVersion) {
+			t.Errorf("feature %q gated at %d, after current capability version %d", name, capVer, tailcfg.CurrentCapabilityVersion)
+		}
+	}
+	for capVer := range capRelease {
+		if capVer > int(tailcfg.CurrentCapabilityVersion) {
+			t.Errorf("release for capability version %d, after current %d", capVer, tailcfg.CurrentCapabilityVersion)
+		}
+	}
+
+	_, _, features := Meta{Cap: int(tailcfg.CurrentCapabilityVersion)}.CapInfo()
+	if len(features) != len(capGates) {
+		t.Errorf("at current capability version, CapInfo features = %q; want all %d", features, len(capGates))
+	}
+	if _, introducedIn, _ := (Meta{Cap: 26}).CapInfo(); introducedIn != "1.20.0" {
+		t.Errorf("CapInfo(26) introducedIn = %q; want 1.20.0", introducedIn)
+	}
+}
+
+// TestIsWindowsGUICached checks that IsWindowsGUI caches its first result.
+// os.Executable can't be stubbed, so it checks the cache against whatever
+// this test binary is.
+func TestIsWindowsGUICached(t *testing.T) {
+	first := IsWindowsGUI()
+	if runtime.GOOS == "windows" {
+		if cached, ok := isWindowsGUI.Peek(); !ok || cached != first {
+			t.Errorf("cached value = (%v, %v); want (%v, true)", cached, ok, first)
+		}
+	}
+	if got := IsWindowsGUI(); got != first {
+		t.Errorf("second IsWindowsGUI = %v; first was %v", got, first)
+	}
+}
+
+func TestTrackOf(t *testing.T) {
+	tests := []struct {
+		short string
+		want  Track
+	}{
+		{"1.80.2", TrackStable},
+		{"1.81.0", TrackUnstable},
+		{"1.81.0-dev", TrackDev},
+		{"1.80.0-dev20250101", TrackDev},
+		{"1.81.0-ERR-BuildInfo", TrackUnstable},
+		{"bogus", TrackStable},
+	}
+	for _, tt := range tests {
+		if got := trackOf(tt.short); got != tt.want {
+			t.Errorf("trackOf(%q) = %v; want %v", tt.short, got, tt.want)
+		}
+	}
+	for tr, want := range map[Track]string{TrackStable: "stable", TrackUnstable: "unstable", TrackDev: "dev", 7: "Track(7)"} {
+		if got := tr.String(); got != want {
+			t.Errorf("Track(%d).String() = %q; want %q", int(tr), got, want)
+		}
+	}
+}
+
+func TestDevDateOf(t *testing.T) {
+	tests := []struct {
+		short string
+		want  string // in time.DateOnly format, or empty for !ok
+	}{
+		{"1.81.0-dev20240312", "2024-03-12"},
+		{"1.81.0-dev20240312-t1234abcde", "2024-03-12"},
+		{"1.81.0-dev", ""},
+		{"1.80.2", ""},
+		{"1.81.0-dev20240230", ""},
+		{"1.81.0-dev2024031", ""},
+		{"1.81.0-dev202403121", ""},
+		{"1.81.0-devabcdefgh", ""},
+	}
+	for _, tt := range tests {
+		got, ok := devDateOf(tt.short)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("devDateOf(%q) = %v; want !ok", tt.short, got)
+			}
+			continue
+		}
+		if !ok || got.Format(time.DateOnly) != tt.want || got.Location() != time.UTC {
+			t.Errorf("devDateOf(%q) = (%v, %v); want %s UTC", tt.short, got, ok, tt.want)
+		}
+	}
+}
+
+func TestLinuxPackagingOf(t *testing.T) {
+	existing := func(names ...string) func(string) bool {
+		return func(name string) bool { return slices.Contains(names, name) }
+	}
+	tests := []struct {
+		exe    string
+		exists func(string) bool
+		want   string
+	}{
+		{"/usr/sbin/tailscaled", existing("/var/lib/dpkg/info/tailscale.list", "/var/lib/rpm"), "deb"},
+		{"/usr/bin/tailscale", existing("/var/lib/rpm"), "rpm"},
+		{"/usr/sbin/tailscaled", existing("/usr/lib/sysimage/rpm"), "rpm"},
+		{"/usr/sbin/tailscaled", existing(), "unknown"},
+		{"/usr/local/bin/tailscaled", existing("/var/lib/dpkg/info/tailscale.list"), "tgz"},
+		{"/opt/tailscale_1.62.0_amd64/tailscaled", existing(), "tgz"},
+		{"/nix/store/abc-tailscale-1.62.0/bin/tailscaled", existing(), "unknown"},
+		{"/snap/tailscale/123/bin/tailscaled", existing(), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := linuxPackagingOf(tt.exe, tt.exists); got != tt.want {
+			t.Errorf("linuxPackagingOf(%q) = %q; want %q", tt.exe, got, tt.want)
+		}
+	}
+}
+
+func TestLinuxInstallSourceOf(t *testing.T) {
+	tests := []struct {
+		distro    distro.Distro
+		container bool
+		packaging string
+		want      string
+	}{
+		{distro.Debian, false, "deb", "deb"},
+		{"", false, "rpm", "rpm"},
+		{distro.Arch, false, "tgz", "tgz"},
+		{distro.Synology, false, "tgz", "spk"},
+		{distro.QNAP, false, "tgz", "qpkg"},
+		{distro.NixOS, false, "unknown", "nix"},
+		{distro.Alpine, false, "unknown", "apk"},
+		{distro.Alpine, false, "tgz", "tgz"},
+		{distro.Alpine, true, "unknown", "docker"},
+		{distro.Debian, true, "deb", "docker"},
+	}
+	for _, tt := range tests {
+		if got := linuxInstallSourceOf(tt.distro, tt.container, tt.packaging); got != tt.want {
+			t.Errorf("linuxInstallSourceOf(%q, %v, %q) = %q; want %q", tt.distro, tt.container, tt.packaging, got, tt.want)
+		}
+	}
+}
+
+func TestWindowsInstallSourceOf(t *testing.T) {
+	for f, want := range map[WindowsFlavor]string{
+		WindowsUnknown: "unknown",
+		WindowsGUI:     "msi",
+		WindowsService: "msi",
+		WindowsCLI:     "msi",
+	} {
+		if got := windowsInstallSourceOf(f); got != want {
+			t.Errorf("windowsInstallSourceOf(%v) = %q; want %q", f, got, want)
+		}
+	}
+}
+
+func TestCgroupIndicatesContainer(t *testing.T) {
+	tests := []struct {
+		cgroup string
+		want   bool
+	}{
+		{"12:memory:/docker/0123456789abcdef\n11:cpu:/docker/0123456789abcdef\n", true},
+		{"0::/system.slice/docker-0123456789abcdef.scope\n", true},
+		{"1:name=systemd:/kubepods/besteffort/pod1234/0123456789abcdef\n", true},
+		{"0::/system.slice/containerd.service/kubepods-burstable-pod1234.slice:cri-containerd:0123\n", true},
+		{"0::/lxc.payload.mycontainer\n", true},
+		{"0::/init.scope\n", false},
+		{"0::/\n", false},
+		{"12:memory:/user.slice\n0::/user.slice/user-1000.slice/session-2.scope\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := cgroupIndicatesContainer(tt.cgroup); got != tt.want {
+			t.Errorf("cgroupIndicatesContainer(%q) = %v; want %v", tt.cgroup, got, tt.want)
+		}
+	}
+}
+
+func TestGitInfoFallsBackToBuildInfo(t *testing.T) {
+	oldCommit, oldDirty, oldInfo := gitCommitStamp, gitDirtyStamp, getEmbeddedInfo
+	t.Cleanup(func() {
+		gitCommitStamp, gitDirtyStamp, getEmbeddedInfo = oldCommit, oldDirty, oldInfo
+	})
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+	getEmbeddedInfo = func() embeddedInfo {
+		return embeddedInfoFromSettings([]debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: commit},
+			{Key: "vcs.time", Value: "2024-03-05T12:34:56Z"},
+			{Key: "vcs.modified", Value: "true"},
+		})
+	}
+
+	gitCommitStamp, gitDirtyStamp = "", false
+	if got := gitCommit(); got != commit {
+		t.Errorf("unstamped gitCommit = %q; want %q from build info", got, commit)
+	}
+	if !gitDirty() {
+		t.Error("unstamped gitDirty = false; want true from build info")
+	}
+	if info := getEmbeddedInfo(); info.commitDate != "20240305" || info.commitTime != "2024-03-05T12:34:56Z" {
+		t.Errorf("commit date, time = %q, %q; want 20240305, 2024-03-05T12:34:56Z", info.commitDate, info.commitTime)
+	}
+
+	gitCommitStamp = "fedcba9876543210fedcba9876543210fedcba98"
+	if got := gitCommit(); got != gitCommitStamp {
+		t.Errorf("stamped gitCommit = %q; want stamp %q", got, gitCommitStamp)
+	}
+
+	if info := embeddedInfoFromSettings([]debug.BuildSetting{{Key: "vcs.modified", Value: "true"}}); info.valid {
+		t.Errorf("build info without a revision = %+v; want invalid", info)
+	}
+}
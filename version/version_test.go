@@ -5,12 +5,16 @@
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path"
 	"runtime/debug"
+	"slices"
+	"strings"
 	"testing"
 
 	ts "tailscale.com"
+	"tailscale.com/tailcfg"
 	"tailscale.com/version"
 )
 
@@ -69,3 +73,177 @@ func BenchmarkReadBuildInfo(b *testing.B) {
 		_ = path.Base(info.Path)
 	}
 }
+
+func TestParseLong(t *testing.T) {
+	tests := []struct {
+		long      string
+		mmp       string
+		commits   []string
+		dirty, ok bool
+	}{
+		{"1.80.2-t1234abcde-g5678fedcb", "1.80.2", []string{"1234abcde", "5678fedcb"}, false, true},
+		{"1.80.2-t1234abcde", "1.80.2", []string{"1234abcde"}, false, true},
+		{"1.80.2-12-t1234abcde-g5678fedcb", "1.80.2", []string{"1234abcde", "5678fedcb"}, false, true},
+		{"1.81.0-dev20250101-t1234abcde", "1.81.0", []string{"1234abcde"}, false, true},
+		{"1.81.0-dev20250101-t1234abcde-dirty", "1.81.0", []string{"1234abcde"}, true, true},
+		{"1.81.0-ERR-BuildInfo", "", nil, false, false},
+		{"1.80.2", "", nil, false, false},
+		{"1.80-t1234abcde", "", nil, false, false},
+		{"1.80.2-g5678fedcb", "", nil, false, false},
+		{"1.80.2-t1234abcde-g5678fedcb-t1234abcde", "", nil, false, false},
+		{"1.80.2-tNOTHEX", "", nil, false, false},
+		{"", "", nil, false, false},
+	}
+	for _, tt := range tests {
+		mmp, commits, dirty, ok := version.ParseLong(tt.long)
+		if mmp != tt.mmp || !slices.Equal(commits, tt.commits) || dirty != tt.dirty || ok != tt.ok {
+			t.Errorf("ParseLong(%q) = (%q, %q, %v, %v); want (%q, %q, %v, %v)", tt.long, mmp, commits, dirty, ok, tt.mmp, tt.commits, tt.dirty, tt.ok)
+		}
+	}
+}
+
+func TestMetaEqual(t *testing.T) {
+	m := version.GetMeta()
+	if !m.Equal(version.GetMeta()) {
+		t.Error("GetMeta not equal to itself")
+	}
+	other := m
+	other.DaemonLong = "1.2.3-tabcdef123"
+	if m.Equal(other) {
+		t.Error("Metas with different DaemonLong are equal")
+	}
+}
+
+func TestMetaString(t *testing.T) {
+	tests := []struct {
+		m    version.Meta
+		want string
+	}{
+		{
+			version.Meta{Short: "1.62.3", Cap: 106},
+			"1.62.3 (cap 106)",
+		},
+		{
+			version.Meta{Short: "1.62.3", Cap: 106, GitCommit: "abc1234def5678abc1234def5678", GitDirty: true},
+			"1.62.3 (cap 106, commit abc1234de, dirty)",
+		},
+		{
+			version.Meta{Short: "1.62.3", Cap: 106, GitCommit: "abc1234", ExtraGitCommit: "fedcba987654", DaemonLong: "1.62.2-tabc-gdef"},
+			"1.62.3 (cap 106, commit abc1234, extra commit fedcba987, daemon 1.62.2-tabc-gdef)",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String() = %q; want %q", got, tt.want)
+		}
+	}
+}
+
+func TestEmbedsTZData(t *testing.T) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		t.Skip("no build info")
+	}
+	var tagged bool
+	for _, s := range bi.Settings {
+		if s.Key == "-tags" {
+			tagged = slices.Contains(strings.Split(s.Value, ","), "timetzdata")
+		}
+	}
+	if got := version.EmbedsTZData(); got != tagged {
+		t.Errorf("EmbedsTZData = %v; want %v", got, tagged)
+	}
+	if got := version.GetMeta().EmbedsTZData; got != tagged {
+		t.Errorf("GetMeta().EmbedsTZData = %v; want %v", got, tagged)
+	}
+}
+
+func TestParseMeta(t *testing.T) {
+	m := version.GetMeta()
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := version.ParseMeta(data)
+	if err != nil {
+		t.Fatalf("ParseMeta(GetMeta()) error: %v", err)
+	}
+	if !got.Equal(m) {
+		t.Errorf("ParseMeta round trip = %+v; want %+v", got, m)
+	}
+
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{"ok", `{"majorMinorPatch":"1.62.3","short":"1.62.3","cap":106}`, false},
+		{"ok-dev", `{"majorMinorPatch":"1.63.0","short":"1.63.0-dev20240101","cap":106}`, false},
+		{"bad-json", `{"majorMinorPatch":`, true},
+		{"wrong-type", `{"majorMinorPatch":"1.62.3","short":"1.62.3","cap":"106"}`, true},
+		{"empty", `{}`, true},
+		{"short-mmp", `{"majorMinorPatch":"1.62","short":"1.62","cap":106}`, true},
+		{"non-numeric", `{"majorMinorPatch":"1.x.3","short":"1.x.3","cap":106}`, true},
+		{"short-mismatch", `{"majorMinorPatch":"1.62.3","short":"1.64.0","cap":106}`, true},
+		{"short-prefix", `{"majorMinorPatch":"1.62.3","short":"1.62.30","cap":106}`, true},
+		{"negative-cap", `{"majorMinorPatch":"1.62.3","short":"1.62.3","cap":-1}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := version.ParseMeta([]byte(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMeta error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !got.Equal(version.Meta{}) {
+				t.Errorf("ParseMeta returned %+v with error; want zero Meta", got)
+			}
+		})
+	}
+}
+
+func TestSetOSForTest(t *testing.T) {
+	real := version.OS()
+	for goos, want := range map[string]string{
+		"ios":     "iOS",
+		"darwin":  "macOS",
+		"linux":   "linux",
+		"windows": "windows",
+	} {
+		t.Run(goos, func(t *testing.T) {
+			version.SetOSForTest(t, goos)
+			if got := version.OS(); got != want {
+				t.Errorf("OS() = %q; want %q", got, want)
+			}
+		})
+	}
+	if got := version.OS(); got != real {
+		t.Errorf("OS() after subtests = %q; want %q", got, real)
+	}
+}
+
+func TestCapCompatible(t *testing.T) {
+	tests := []struct {
+		local, remote int
+		required      tailcfg.CapabilityVersion
+		want          bool
+	}{
+		{100, 100, 100, true},
+		{110, 105, 100, true},
+		{99, 110, 100, false},
+		{110, 99, 100, false},
+		{0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		if got := version.CapCompatible(tt.local, tt.remote, tt.required); got != tt.want {
+			t.Errorf("CapCompatible(%d, %d, %d) = %v; want %v", tt.local, tt.remote, tt.required, got, tt.want)
+		}
+	}
+
+	cur := tailcfg.CurrentCapabilityVersion
+	if !version.CapAtLeast(cur) {
+		t.Errorf("CapAtLeast(current %d) = false", cur)
+	}
+	if version.CapAtLeast(cur + 1) {
+		t.Errorf("CapAtLeast(%d) = true; current is %d", cur+1, cur)
+	}
+}
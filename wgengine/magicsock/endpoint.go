@@ -118,7 +118,7 @@ func (de *endpoint) udpRelayEndpointReady(maybeBest addrQuality) {
 
 	if !curBestAddrTrusted ||
 		sameRelayServer ||
-		betterAddr(maybeBest, de.bestAddr) {
+		de.betterAddrLocked(maybeBest, de.bestAddr) {
 		// We must set maybeBest as de.bestAddr if:
 		//   1. de.bestAddr is untrusted. betterAddr does not consider
 		//      time-based trust.
@@ -1791,7 +1791,7 @@ func (de *endpoint) handlePongConnLocked(m *disco.Pong, di *discoInfo, src epAdd
 			wireMTU: pingSizeToPktLen(sp.size, sp.to),
 		}
 		bestUntrusted := now.After(de.trustBestAddrUntil)
-		if betterAddr(thisPong, de.bestAddr) || bestUntrusted {
+		if de.betterAddrLocked(thisPong, de.bestAddr) || bestUntrusted {
 			de.c.logf("magicsock: disco: node %v %v now using %v mtu=%v tx=%x", de.publicKey.ShortString(), de.discoShort(), sp.to, thisPong.wireMTU, m.TxID[:6])
 			de.debugUpdates.Add(EndpointChange{
 				When: time.Now(),
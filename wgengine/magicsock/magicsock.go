@@ -166,6 +166,7 @@ type Conn struct {
 	idleFunc               func() time.Duration // nil means unknown
 	testOnlyPacketListener nettype.PacketListener
 	onDERPRecv             func(int, key.NodePublic, []byte) bool // or nil, see Options.OnDERPRecv
+	pathPolicy             PathPolicy                             // or nil, see Options.PathPolicy
 	netMon                 *netmon.Monitor                        // must be non-nil
 	health                 *health.Tracker                        // or nil
 	extraRootCAs           *x509.CertPool                         // additional trusted root CAs; or nil
@@ -523,6 +524,10 @@ type Options struct {
 	// WireGuard. The pkt slice is borrowed and must be copied if
 	// the callee needs to retain it.
 	OnDERPRecv func(regionID int, src key.NodePublic, pkt []byte) bool
+
+	// PathPolicy, if non-nil, overrides the Conn's choice of UDP path to
+	// peers. See [PathPolicy].
+	PathPolicy PathPolicy
 }
 
 func (o *Options) logf() logger.Logf {
@@ -671,6 +676,7 @@ func NewConn(opts Options) (*Conn, error) {
 	c.idleFunc = opts.IdleFunc
 	c.testOnlyPacketListener = opts.TestOnlyPacketListener
 	c.onDERPRecv = opts.OnDERPRecv
+	c.pathPolicy = opts.PathPolicy
 
 	// Set up publishers and subscribers. Subscribe calls must return before
 	// NewConn otherwise published events can be missed.
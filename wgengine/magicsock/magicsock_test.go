@@ -2164,6 +2164,46 @@ func TestBetterAddr(t *testing.T) {
 	}
 }
 
+func TestBetterAddrPathPolicy(t *testing.T) {
+	al := func(ipps string, d time.Duration) addrQuality {
+		return addrQuality{epAddr: epAddr{ap: netip.MustParseAddrPort(ipps)}, latency: d}
+	}
+	v4 := al("1.2.3.4:555", 5*time.Millisecond)
+	v6 := al("[2001::5]:123", 50*time.Millisecond)
+
+	peer := key.NewNode().Public()
+	var gotPeer key.NodePublic
+	preferIPv6 := func(p key.NodePublic, a, b PathCandidate) (aBetter, ok bool) {
+		gotPeer = p
+		if a.Addr.Addr().Is6() == b.Addr.Addr().Is6() {
+			return false, false
+		}
+		return a.Addr.Addr().Is6(), true
+	}
+	de := &endpoint{c: &Conn{pathPolicy: preferIPv6}, publicKey: peer}
+
+	if !de.betterAddrLocked(v6, v4) {
+		t.Error("policy: IPv6 not better than faster IPv4")
+	}
+	if de.betterAddrLocked(v4, v6) {
+		t.Error("policy: faster IPv4 better than IPv6")
+	}
+	if gotPeer != peer {
+		t.Errorf("policy called with peer %v; want %v", gotPeer, peer)
+	}
+	if !de.betterAddrLocked(v4, addrQuality{}) {
+		t.Error("policy: IPv4 not better than no addr")
+	}
+	if !de.betterAddrLocked(al("5.6.7.8:999", time.Millisecond), v4) {
+		t.Error("policy declined: faster IPv4 not better")
+	}
+
+	de.c.pathPolicy = nil
+	if de.betterAddrLocked(v6, v4) {
+		t.Error("no policy: slower IPv6 better than IPv4")
+	}
+}
+
 func epFromTyped(eps []tailcfg.Endpoint) (ret []netip.AddrPort) {
 	for _, ep := range eps {
 		ret = append(ret, ep.Addr)
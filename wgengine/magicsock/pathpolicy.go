@@ -0,0 +1,57 @@
+// Copyright (c) Tailscale Inc & contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package magicsock
+
+import (
+	"net/netip"
+	"time"
+
+	"tailscale.com/types/key"
+)
+
+// PathCandidate describes a UDP path to a peer, for a [PathPolicy] to
+// compare with another.
+type PathCandidate struct {
+	// Addr is the peer's address, or that of the relay server if Relayed.
+	Addr netip.AddrPort
+
+	// Relayed is whether the path is via a peer relay server rather than
+	// direct to the peer.
+	Relayed bool
+
+	// Latency is the most recent round-trip time on the path, or zero if
+	// unknown.
+	Latency time.Duration
+}
+
+// PathPolicy is a hook to override which UDP path a [Conn] uses to a peer.
+//
+// It's called with the peer's node key when a newly working path a might
+// replace the current best path b. If ok, aBetter reports whether to
+// switch to a. If not ok, the Conn's own heuristics make the choice.
+//
+// It's called with the endpoint's lock held and must be quick and not call
+// back into the Conn.
+type PathPolicy func(peer key.NodePublic, a, b PathCandidate) (aBetter, ok bool)
+
+func (a addrQuality) pathCandidate() PathCandidate {
+	return PathCandidate{
+		Addr:    a.ap,
+		Relayed: a.vni.IsSet(),
+		Latency: a.latency,
+	}
+}
+
+// betterAddrLocked reports whether a is a better addr to use than b for
+// de, asking de.c's PathPolicy first, if any.
+//
+// de.mu must be held.
+func (de *endpoint) betterAddrLocked(a, b addrQuality) bool {
+	if p := de.c.pathPolicy; p != nil && b.ap.IsValid() && a.epAddr != b.epAddr {
+		if aBetter, ok := p(de.publicKey, a.pathCandidate(), b.pathCandidate()); ok {
+			return aBetter
+		}
+	}
+	return betterAddr(a, b)
+}
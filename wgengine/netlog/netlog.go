@@ -15,8 +15,10 @@
 	"log"
 	"net/http"
 	"net/netip"
+	"os"
 	"time"
 
+	"tailscale.com/envknob"
 	"tailscale.com/health"
 	"tailscale.com/logpolicy"
 	"tailscale.com/logtail"
@@ -100,6 +102,18 @@ func (nl *Logger) Running() bool {
 
 var testClient *http.Client
 
+// localFile is the path of a file that each network flow log message is
+// also appended to, as a line of JSON, or empty for none. It lets
+// administrators keep a local audit trail of the same records uploaded to
+// the logging service.
+//
+// The file is never rotated or truncated, and grows by one line per flushed
+// record, each up to maxLogSize bytes, for as long as network flow logging
+// is enabled. Rotating it is left to external tools such as logrotate; as
+// it's opened in append mode, their copytruncate mode works, and so does
+// moving it aside, which takes effect when the logger next restarts.
+var localFile = envknob.RegisterString("TS_NETLOG_FILE")
+
 // Startup starts an asynchronous network logger that monitors
 // statistics for the provided tun and/or sock device.
 //
@@ -173,6 +187,16 @@ func (nl *Logger) Startup(logf logger.Logf, source NodeSource, nodeLogID, domain
 	sock = cmp.Or[Device](sock, noopDevice{})
 	sock.SetConnectionCounter(nl.updatePhysConn)
 
+	var file *os.File
+	if path := localFile(); path != "" {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			logf("netlog: not logging locally: %v", err)
+		} else {
+			file = f
+		}
+	}
+
 	// Startup a goroutine to record log messages.
 	// This is done asynchronously so that the cost of serializing
 	// the network flow log message never stalls processing of packets.
@@ -190,6 +214,11 @@ func (nl *Logger) Startup(logf logger.Logf, source NodeSource, nodeLogID, domain
 				}
 			} else {
 				logger.Logf("%s", b)
+				if file != nil {
+					if _, err := file.Write(append(b, '\n')); err != nil && nl.logf != nil {
+						nl.logf("netlog: writing %v: %v", file.Name(), err)
+					}
+				}
 			}
 		}
 	}(nl.recordsChan)
@@ -205,6 +234,9 @@ func (nl *Logger) Startup(logf logger.Logf, source NodeSource, nodeLogID, domain
 		nl.recordsChan = nil
 		<-recorderDone
 		recorderDone = nil
+		if file != nil {
+			file.Close()
+		}
 
 		// Try to upload all pending records.
 		err := logger.Shutdown(ctx)
@@ -6,9 +6,16 @@
 package netlog
 
 import (
+	"context"
 	"encoding/binary"
+	"errors"
 	"math/rand/v2"
+	"net"
+	"net/http"
 	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"testing/synctest"
@@ -17,10 +24,13 @@
 	jsonv2 "github.com/go-json-experiment/json"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"tailscale.com/envknob"
 	"tailscale.com/tailcfg"
 	"tailscale.com/types/bools"
 	"tailscale.com/types/ipproto"
+	"tailscale.com/types/logid"
 	"tailscale.com/types/netlogtype"
+	"tailscale.com/util/eventbus/eventbustest"
 	"tailscale.com/wgengine/router"
 )
 
@@ -236,6 +246,58 @@ func TestUpdateRace(t *testing.T) {
 	logger.mu.Unlock()
 }
 
+func TestLocalFile(t *testing.T) {
+	// Keep the uploads off the network; they're abandoned on shutdown.
+	testClient = &http.Client{Transport: &http.Transport{
+		DialContext: func(context.Context, string, string) (net.Conn, error) {
+			return nil, errors.New("no network in tests")
+		},
+	}}
+	defer func() { testClient = nil }()
+
+	path := filepath.Join(t.TempDir(), "netlog.json")
+	envknob.Setenv("TS_NETLOG_FILE", path)
+	defer envknob.Setenv("TS_NETLOG_FILE", "")
+
+	source := newFakeNodeSource(
+		(&tailcfg.Node{ID: 1, Addresses: []netip.Prefix{prefix("100.1.2.3")}}).View(),
+		[]tailcfg.NodeView{(&tailcfg.Node{ID: 2, Addresses: []netip.Prefix{prefix("100.1.2.4")}}).View()},
+		nil)
+	var logger Logger
+	if err := logger.Startup(t.Logf, source, logid.PrivateID{}, logid.PrivateID{}, nil, nil, nil, nil, eventbustest.NewBus(t), false); err != nil {
+		t.Fatal(err)
+	}
+	src, dst := netip.MustParseAddrPort("100.1.2.3:1234"), netip.MustParseAddrPort("100.1.2.4:80")
+	logger.updateVirtConn(ipproto.TCP, src, dst, 1, 100, false)
+	logger.mu.Lock()
+	logger.flushRecordLocked()
+	logger.mu.Unlock()
+	logger.updateVirtConn(ipproto.TCP, src, dst, 2, 200, true)
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := logger.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines; want 2:\n%s", len(lines), b)
+	}
+	for i, line := range lines {
+		var msg netlogtype.Message
+		if err := jsonv2.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if len(msg.VirtualTraffic) != 1 || msg.VirtualTraffic[0].Src != src || msg.VirtualTraffic[0].Dst != dst {
+			t.Errorf("line %d: VirtualTraffic = %+v; want one %v -> %v connection", i, msg.VirtualTraffic, src, dst)
+		}
+	}
+}
+
 func randAddrPort() netip.AddrPort {
 	var b [4]uint8
 	binary.LittleEndian.PutUint32(b[:], rand.Uint32())
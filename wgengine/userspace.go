@@ -261,6 +261,10 @@ type Config struct {
 	// WireGuard. The pkt slice is borrowed and must be copied if
 	// the callee needs to retain it.
 	OnDERPRecv func(regionID int, src key.NodePublic, pkt []byte) (handled bool)
+
+	// PathPolicy, if non-nil, overrides magicsock's choice of UDP path
+	// to peers. See [magicsock.PathPolicy].
+	PathPolicy magicsock.PathPolicy
 }
 
 // NewFakeUserspaceEngine returns a new userspace engine for testing.
@@ -436,6 +440,7 @@ func NewUserspaceEngine(logf logger.Logf, conf Config) (_ Engine, reterr error)
 		PeerByKeyFunc:  e.PeerByKey,
 		ForceDiscoKey:  conf.ForceDiscoKey,
 		OnDERPRecv:     conf.OnDERPRecv,
+		PathPolicy:     conf.PathPolicy,
 	}
 	var err error
 	e.magicConn, err = magicsock.NewConn(magicsockOpts)